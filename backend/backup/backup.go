@@ -0,0 +1,259 @@
+package backup
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"bk_kms/lib"
+	"bk_kms/migrate"
+)
+
+const (
+	// Format 备份文件格式标识
+	Format = "bk_kms-backup"
+	// SchemaVersion 备份文件的格式版本（清单与文件布局），格式发生不兼容变化时递增，与数据库迁移版本无关
+	SchemaVersion = 1
+
+	manifestFile  = "manifest.json"
+	tableDir      = "tables/"
+	snapshotDir   = "snapshots/"
+	timeLayout    = "2006-01-02 15:04:05.999999"
+	snapshotTable = "bookmark"
+	snapshotField = "html"
+)
+
+// Manifest 备份清单，记录格式版本、数据库迁移版本与各表的行数
+type Manifest struct {
+	Format           string         `json:"format"`
+	SchemaVersion    int            `json:"schema_version"`
+	MigrationVersion int            `json:"migration_version"` // 备份时数据库的迁移版本，旧版本的备份没有该字段（为 0）
+	CreatedAt        time.Time      `json:"created_at"`
+	Tables           map[string]int `json:"tables"`    // 表名 -> 行数
+	Snapshots        int            `json:"snapshots"` // 书签归档快照文件数
+}
+
+// table 参与备份的表，Refs 为引用其他表ID的字段，Key 为合并恢复时判断记录是否已存在的字段
+type table struct {
+	Name string
+	Refs map[string]string
+	Key  []string
+	// OwnedBy 只在该字段引用的记录为本次新建时才恢复（如恢复码只属于新建的用户）
+	OwnedBy string
+	// ReplaceOnly 只在替换模式下恢复
+	ReplaceOnly bool
+}
+
+// tables 按依赖顺序排列：被引用的表在前。验证码为临时数据，不参与备份
+var tables = []table{
+	{Name: "user", Key: []string{"username"}},
+	{Name: "user_identity", Refs: map[string]string{"user_id": "user"}, Key: []string{"issuer", "subject"}},
+	{Name: "user_recovery_code", Refs: map[string]string{"user_id": "user"}, OwnedBy: "user_id"},
+	{Name: "personal_token", Refs: map[string]string{"user_id": "user"}, Key: []string{"token_hash"}},
+	{Name: "tag", Key: []string{"name"}},
+	{Name: "bookmark", Key: []string{"url"}},
+	{Name: "bookmark_tag", Refs: map[string]string{"bookmark_id": "bookmark", "tag_id": "tag"}},
+	{Name: "collection", Refs: map[string]string{"parent_id": "collection"}, Key: []string{"parent_id", "name"}},
+	{Name: "collection_bookmark", Refs: map[string]string{"collection_id": "collection", "bookmark_id": "bookmark"}},
+	{Name: "tag_rule", Key: []string{"name", "field", "match_type", "pattern"}},
+	{Name: "tag_rule_tag", Refs: map[string]string{"rule_id": "tag_rule", "tag_id": "tag"}},
+	{Name: "saved_search", Refs: map[string]string{"user_id": "user"}, Key: []string{"feed_token"}},
+	{Name: "share", Refs: map[string]string{"user_id": "user"}, Key: []string{"token"}},
+	{Name: "subscription", Key: []string{"url"}},
+	{Name: "subscription_item", Refs: map[string]string{"subscription_id": "subscription"}, Key: []string{"subscription_id", "item_key"}},
+	{Name: "bookmark_state", Refs: map[string]string{"user_id": "user", "bookmark_id": "bookmark"}},
+	{Name: "highlight", Refs: map[string]string{"user_id": "user", "bookmark_id": "bookmark"}, Key: []string{"user_id", "bookmark_id", "quote", "created_at"}},
+	{Name: "bookmark_note", Refs: map[string]string{"user_id": "user", "bookmark_id": "bookmark"}, Key: []string{"user_id", "bookmark_id", "created_at"}},
+	{Name: "audit_log", ReplaceOnly: true},
+}
+
+// session 备份与恢复使用的数据库会话，关闭逐条 SQL 日志，避免大量数据写入日志
+func session() *gorm.DB {
+	return lib.DB.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Warn)})
+}
+
+// Write 将全部数据写入 zip 格式的备份：每个表一个 JSON Lines 文件，书签的归档 HTML 单独保存为快照文件
+func Write(w io.Writer) (*Manifest, error) {
+	migrationVersion, err := migrate.Current()
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{
+		Format:           Format,
+		SchemaVersion:    SchemaVersion,
+		MigrationVersion: migrationVersion,
+		CreatedAt:        time.Now(),
+		Tables:           make(map[string]int, len(tables)),
+	}
+
+	zw := zip.NewWriter(w)
+	err = readSnapshot(func(tx *gorm.DB) error {
+		for _, t := range tables {
+			count, err := writeTable(zw, tx, t.Name)
+			if err != nil {
+				return err
+			}
+			manifest.Tables[t.Name] = count
+		}
+		snapshots, err := writeSnapshots(zw, tx)
+		if err != nil {
+			return err
+		}
+		manifest.Snapshots = snapshots
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 清单最后写入，此时才能确定各表行数
+	mw, err := zw.Create(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// readSnapshot 在只读的一致性快照事务中执行 fn：全部表读取的是同一时刻的数据，
+// 备份期间的写入（如新建书签与标签关联）不会导致备份中的关联数据不一致
+func readSnapshot(fn func(tx *gorm.DB) error) error {
+	return session().Connection(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY").Error; err != nil {
+			return err
+		}
+		err := fn(tx)
+		// 只读事务没有需要提交的修改，提交与回滚等价
+		if commitErr := tx.Exec("COMMIT").Error; err == nil {
+			err = commitErr
+		}
+		return err
+	})
+}
+
+// writeTable 逐行写入一个表，返回行数。书签表不包含归档 HTML，由 writeSnapshots 单独写入
+func writeTable(zw *zip.Writer, tx *gorm.DB, name string) (int, error) {
+	columns, err := tableColumns(tx, name)
+	if err != nil {
+		return 0, err
+	}
+	selected := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if name != snapshotTable || column != snapshotField {
+			selected = append(selected, quote(column))
+		}
+	}
+	rows, err := tx.Table(name).Select(selected).Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	values := make([]interface{}, len(columnTypes))
+	pointers := make([]interface{}, len(columnTypes))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	fw, err := zw.Create(tableDir + name + ".jsonl")
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetEscapeHTML(false)
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return 0, err
+		}
+		row := make(map[string]interface{}, len(columnTypes))
+		for i, ct := range columnTypes {
+			row[ct.Name()] = exportValue(values[i], ct)
+		}
+		if err := enc.Encode(row); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// writeSnapshots 将书签的归档 HTML 写入 snapshots/<书签ID>.html，返回文件数
+func writeSnapshots(zw *zip.Writer, tx *gorm.DB) (int, error) {
+	rows, err := tx.Table(snapshotTable).Select("id", snapshotField).Where(snapshotField + " <> ''").Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var id int
+		var html string
+		if err := rows.Scan(&id, &html); err != nil {
+			return 0, err
+		}
+		sw, err := zw.Create(snapshotDir + strconv.Itoa(id) + ".html")
+		if err != nil {
+			return 0, err
+		}
+		if _, err := io.WriteString(sw, html); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// tableColumns 表当前可写入的字段名，不含生成列（如书签的 live_url），生成列由数据库计算
+func tableColumns(tx *gorm.DB, name string) ([]string, error) {
+	var columns []string
+	err := tx.Raw("SELECT column_name FROM information_schema.columns "+
+		"WHERE table_schema = DATABASE() AND table_name = ? AND generation_expression = '' "+
+		"ORDER BY ordinal_position", name).Scan(&columns).Error
+	return columns, err
+}
+
+// exportValue 将数据库的原始值转换为 JSON 值：整数字段输出为数字，时间使用本地时区的固定格式
+func exportValue(v interface{}, ct *sql.ColumnType) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		return v.Format(timeLayout)
+	case []byte:
+		s := string(v)
+		if strings.Contains(strings.ToUpper(ct.DatabaseTypeName()), "INT") {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+			if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+				return n
+			}
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,461 @@
+package backup
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"bk_kms/migrate"
+	"bk_kms/model/db"
+)
+
+// 恢复模式
+const (
+	ModeMerge   = "merge"   // 合并：保留现有数据，按唯一字段跳过已存在的记录，新记录重新分配ID
+	ModeReplace = "replace" // 替换：清空现有数据后按备份中的ID原样写入
+)
+
+// ErrInvalidBackup 备份文件无效或版本不受支持
+var ErrInvalidBackup = errors.New("备份文件无效")
+
+// TableResult 单个表的恢复结果
+type TableResult struct {
+	Restored int `json:"restored"` // 写入的行数
+	Skipped  int `json:"skipped"`  // 已存在或引用的记录缺失而跳过的行数
+	// Dropped 备份中存在、当前表中已不存在而丢弃的字段
+	Dropped []string `json:"dropped,omitempty"`
+}
+
+// RestoreResult 恢复结果
+type RestoreResult struct {
+	Mode     string                  `json:"mode"`
+	Manifest *Manifest               `json:"manifest"`
+	Tables   map[string]*TableResult `json:"tables"`
+}
+
+// shareTargets 分享对象类型对应的表
+var shareTargets = map[string]string{
+	db.ShareTargetBookmark:    "bookmark",
+	db.ShareTargetTag:         "tag",
+	db.ShareTargetSavedSearch: "saved_search",
+	db.ShareTargetCollection:  "collection",
+}
+
+// ReadManifest 读取并校验备份清单
+func ReadManifest(zr *zip.Reader) (*Manifest, error) {
+	files := zipFiles(zr)
+	file, ok := files[manifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%w: 缺少 %s", ErrInvalidBackup, manifestFile)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer rc.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: 清单格式错误: %v", ErrInvalidBackup, err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("%w: 不是 bk_kms 的备份文件", ErrInvalidBackup)
+	}
+	if manifest.SchemaVersion < 1 || manifest.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("%w: 不支持的备份版本 %d，当前支持的最高版本为 %d", ErrInvalidBackup, manifest.SchemaVersion, SchemaVersion)
+	}
+	for name, count := range manifest.Tables {
+		if _, ok := files[tableDir+name+".jsonl"]; !ok && count > 0 {
+			return nil, fmt.Errorf("%w: 缺少表文件 %s", ErrInvalidBackup, name)
+		}
+	}
+	return &manifest, nil
+}
+
+// Restore 从备份恢复数据，全部写入在一个事务中完成，任何错误都会回滚
+func Restore(zr *zip.Reader, mode string) (*RestoreResult, error) {
+	if mode != ModeMerge && mode != ModeReplace {
+		return nil, fmt.Errorf("%w: 不支持的恢复模式 %s", ErrInvalidBackup, mode)
+	}
+	manifest, err := ReadManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+	// 新版本数据库的备份可能包含当前表结构无法保存的数据，需要先升级程序并执行迁移
+	current, err := migrate.Current()
+	if err != nil {
+		return nil, err
+	}
+	if manifest.MigrationVersion > current {
+		return nil, fmt.Errorf("%w: 备份的数据库版本 %d 高于当前数据库版本 %d，请先升级程序并执行迁移",
+			ErrInvalidBackup, manifest.MigrationVersion, current)
+	}
+
+	result := &RestoreResult{
+		Mode:     mode,
+		Manifest: manifest,
+		Tables:   make(map[string]*TableResult, len(tables)),
+	}
+	err = session().Transaction(func(tx *gorm.DB) error {
+		r := &restorer{
+			tx:      tx,
+			files:   zipFiles(zr),
+			ids:     make(map[string]map[int64]int64),
+			created: make(map[string]map[int64]bool),
+			result:  result,
+		}
+		if mode == ModeReplace {
+			return r.replace()
+		}
+		for _, t := range tables {
+			if t.ReplaceOnly {
+				continue
+			}
+			if err := r.merge(t); err != nil {
+				return fmt.Errorf("恢复 %s 失败: %w", t.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type restorer struct {
+	tx    *gorm.DB
+	files map[string]*zip.File
+	// ids 备份中的ID -> 恢复后的ID，按表区分
+	ids map[string]map[int64]int64
+	// created 本次新建的记录（备份中的ID），按表区分
+	created map[string]map[int64]bool
+	result  *RestoreResult
+}
+
+// replace 清空全部表后按原ID写入。清空与写入期间关闭外键检查，收藏夹等自引用的表无需排序
+func (r *restorer) replace() error {
+	if err := r.tx.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+		return err
+	}
+	defer r.tx.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	for i := len(tables) - 1; i >= 0; i-- {
+		if err := r.tx.Exec("DELETE FROM " + quote(tables[i].Name)).Error; err != nil {
+			return fmt.Errorf("清空 %s 失败: %w", tables[i].Name, err)
+		}
+	}
+	for _, t := range tables {
+		tr := r.tableResult(t.Name)
+		err := r.eachRow(t.Name, func(row map[string]interface{}) error {
+			if _, _, err := insertRow(r.tx, t.Name, row, false); err != nil {
+				return err
+			}
+			tr.Restored++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("恢复 %s 失败: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// merge 合并一个表：引用字段换算为恢复后的ID，按 Key 匹配已存在的记录，自引用的行等上级写入后再处理
+func (r *restorer) merge(t table) error {
+	tr := r.tableResult(t.Name)
+	var pending []map[string]interface{}
+	err := r.eachRow(t.Name, func(row map[string]interface{}) error {
+		done, err := r.mergeRow(t, row)
+		if err != nil {
+			return err
+		}
+		if !done {
+			pending = append(pending, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 反复处理等待上级的行，直到没有进展；上级不存在的行跳过
+	for len(pending) > 0 {
+		var rest []map[string]interface{}
+		for _, row := range pending {
+			done, err := r.mergeRow(t, row)
+			if err != nil {
+				return err
+			}
+			if !done {
+				rest = append(rest, row)
+			}
+		}
+		if len(rest) == len(pending) {
+			tr.Skipped += len(rest)
+			break
+		}
+		pending = rest
+	}
+	return nil
+}
+
+// mergeRow 合并一行，返回 false 表示该行引用的同表记录尚未写入，需要稍后重试
+func (r *restorer) mergeRow(t table, row map[string]interface{}) (bool, error) {
+	tr := r.tableResult(t.Name)
+	if t.OwnedBy != "" && !r.created[t.Refs[t.OwnedBy]][toInt64(row[t.OwnedBy])] {
+		tr.Skipped++
+		return true, nil
+	}
+
+	refs := t.Refs
+	if t.Name == "share" {
+		refs = map[string]string{"user_id": "user"}
+		target, ok := shareTargets[fmt.Sprint(row["target_type"])]
+		if !ok {
+			tr.Skipped++
+			return true, nil
+		}
+		refs["target_id"] = target
+	}
+
+	mapped := make(map[string]interface{}, len(row))
+	for column, value := range row {
+		mapped[column] = value
+	}
+	for column, refTable := range refs {
+		oldID := toInt64(row[column])
+		if oldID == 0 {
+			continue
+		}
+		newID, ok := r.ids[refTable][oldID]
+		if !ok {
+			if refTable == t.Name {
+				return false, nil
+			}
+			tr.Skipped++
+			return true, nil
+		}
+		mapped[column] = newID
+	}
+
+	_, hasID := row["id"]
+	if !hasID {
+		// 关联表：复合主键已存在时忽略
+		_, affected, err := insertRow(r.tx, t.Name, mapped, true)
+		if err != nil {
+			return true, err
+		}
+		if affected > 0 {
+			tr.Restored++
+		} else {
+			tr.Skipped++
+		}
+		return true, nil
+	}
+
+	oldID := toInt64(row["id"])
+	delete(mapped, "id")
+	if len(t.Key) > 0 {
+		existing, err := r.findExisting(t, mapped)
+		if err != nil {
+			return true, err
+		}
+		if existing > 0 {
+			r.mapID(t.Name, oldID, existing)
+			tr.Skipped++
+			return true, nil
+		}
+	}
+	newID, _, err := insertRow(r.tx, t.Name, mapped, false)
+	if err != nil {
+		return true, err
+	}
+	r.mapID(t.Name, oldID, newID)
+	if r.created[t.Name] == nil {
+		r.created[t.Name] = make(map[int64]bool)
+	}
+	r.created[t.Name][oldID] = true
+	tr.Restored++
+	return true, nil
+}
+
+// findExisting 按 Key 查找已存在的记录ID，不存在时返回 0
+func (r *restorer) findExisting(t table, row map[string]interface{}) (int64, error) {
+	query := r.tx.Table(t.Name).Select("id")
+	for _, column := range t.Key {
+		query = query.Where(quote(column)+" = ?", row[column])
+	}
+	var ids []int64
+	if err := query.Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+func (r *restorer) mapID(name string, oldID, newID int64) {
+	if r.ids[name] == nil {
+		r.ids[name] = make(map[int64]int64)
+	}
+	r.ids[name][oldID] = newID
+}
+
+func (r *restorer) tableResult(name string) *TableResult {
+	tr, ok := r.result.Tables[name]
+	if !ok {
+		tr = &TableResult{}
+		r.result.Tables[name] = tr
+	}
+	return tr
+}
+
+// eachRow 逐行读取表文件，只保留当前表中存在的字段（丢弃的字段记录在恢复结果中）；书签行补回快照中的归档 HTML
+func (r *restorer) eachRow(name string, fn func(row map[string]interface{}) error) error {
+	file, ok := r.files[tableDir+name+".jsonl"]
+	if !ok {
+		return nil
+	}
+	columns, err := tableColumns(r.tx, name)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		known[column] = true
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	dec := json.NewDecoder(rc)
+	dec.UseNumber()
+	for {
+		var raw map[string]interface{}
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: %s 格式错误: %v", ErrInvalidBackup, name, err)
+		}
+		row := make(map[string]interface{}, len(raw))
+		for column, value := range raw {
+			if known[column] {
+				row[column] = value
+			} else {
+				r.drop(name, column)
+			}
+		}
+		if name == snapshotTable && known[snapshotField] {
+			html, err := r.snapshot(toInt64(raw["id"]))
+			if err != nil {
+				return err
+			}
+			row[snapshotField] = html
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// drop 记录恢复时丢弃的字段
+func (r *restorer) drop(name, column string) {
+	tr := r.tableResult(name)
+	if !slices.Contains(tr.Dropped, column) {
+		tr.Dropped = append(tr.Dropped, column)
+		sort.Strings(tr.Dropped)
+	}
+}
+
+// snapshot 读取书签的归档快照，没有快照时返回空字符串
+func (r *restorer) snapshot(id int64) (string, error) {
+	file, ok := r.files[snapshotDir+strconv.FormatInt(id, 10)+".html"]
+	if !ok {
+		return "", nil
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// insertRow 写入一行，返回自增ID与影响行数
+func insertRow(tx *gorm.DB, name string, row map[string]interface{}, ignore bool) (int64, int64, error) {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	quoted := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		quoted[i] = quote(column)
+		args[i] = row[column]
+	}
+	verb := "INSERT INTO "
+	if ignore {
+		verb = "INSERT IGNORE INTO "
+	}
+	sql := verb + quote(name) + " (" + strings.Join(quoted, ", ") + ") VALUES (" +
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	res, err := tx.Statement.ConnPool.ExecContext(tx.Statement.Context, sql, args...)
+	if err != nil {
+		return 0, 0, err
+	}
+	id, _ := res.LastInsertId()
+	affected, _ := res.RowsAffected()
+	return id, affected, nil
+}
+
+func quote(name string) string {
+	return "`" + name + "`"
+}
+
+// toInt64 将 JSON 中的ID转换为整数，无法转换时返回 0
+func toInt64(v interface{}) int64 {
+	switch v := v.(type) {
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
+
+// zipFiles 按文件名索引 zip 中的文件
+func zipFiles(zr *zip.Reader) map[string]*zip.File {
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, file := range zr.File {
+		files[file.Name] = file
+	}
+	return files
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// client REST 接口客户端
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+// envelope 接口统一响应
+type envelope struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+func newClient(server, token string) *client {
+	return &client{
+		server: strings.TrimRight(server, "/"),
+		token:  token,
+		http:   &http.Client{},
+	}
+}
+
+// newRequest 创建 /api/v1 下的请求
+func (c *client) newRequest(method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	if c.server == "" {
+		return nil, errors.New("未设置服务器地址，请先运行 bkms login")
+	}
+	u := c.server + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
+
+// call 发送 JSON 请求，响应的 data 解析到 data 中（data 为 nil 时忽略）
+func (c *client) call(method, path string, query url.Values, body, data interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := c.newRequest(method, path, query, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeEnvelope(resp, data)
+}
+
+// stream 发送请求并返回响应，接口返回错误信息（JSON）时转换为 error
+func (c *client) stream(req *http.Request) (*http.Response, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if isJSON(resp) {
+		defer resp.Body.Close()
+		return nil, decodeEnvelope(resp, nil)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("请求失败: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// download 下载文件写入 w
+func (c *client) download(path string, query url.Values, w io.Writer) error {
+	req, err := c.newRequest(http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.stream(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// upload 以 multipart/form-data 上传文件，返回原始响应，由调用方处理 JSON 或 SSE
+func (c *client) upload(path, field, file string, fields map[string]string) (*http.Response, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// 边读文件边上传，避免大文件整体读入内存
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		defer f.Close()
+		for key, value := range fields {
+			if err := mw.WriteField(key, value); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		part, err := mw.CreateFormFile(field, filepath.Base(file))
+		if err == nil {
+			_, err = io.Copy(part, f)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := c.newRequest(http.MethodPost, path, nil, pr)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return c.http.Do(req)
+}
+
+// decodeEnvelope 解析统一响应，code 不为 0 时返回 msg
+func decodeEnvelope(resp *http.Response, data interface{}) error {
+	if !isJSON(resp) {
+		return fmt.Errorf("请求失败: %s", resp.Status)
+	}
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if env.Code == http.StatusUnauthorized {
+		return errors.New(env.Msg + "，请运行 bkms login 重新登录")
+	}
+	if env.Code != 0 {
+		return errors.New(env.Msg)
+	}
+	if data != nil && len(env.Data) > 0 {
+		return json.Unmarshal(env.Data, data)
+	}
+	return nil
+}
+
+func isJSON(resp *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mediaType == "application/json"
+}
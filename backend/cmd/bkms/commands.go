@@ -0,0 +1,455 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"bk_kms/model/dto"
+)
+
+const listAllPageSize = 100 // -all 时每次请求的书签数
+
+// loginCommand 使用个人访问令牌登录：校验令牌后保存服务器地址与令牌
+// 用法: bkms login [-server URL] [-token TOKEN]，未提供令牌时从标准输入读取
+func loginCommand(a *app, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	server := fs.String("server", a.config.Server, "服务器地址，如 http://localhost:8081")
+	token := fs.String("token", "", "个人访问令牌（在网页的个人设置中创建）")
+	fs.Parse(args)
+
+	if *server == "" {
+		return errors.New("请通过 -server 指定服务器地址")
+	}
+	if *token == "" {
+		fmt.Fprint(os.Stderr, "个人访问令牌: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		*token = strings.TrimSpace(line)
+	}
+	if *token == "" {
+		return errors.New("令牌不能为空")
+	}
+
+	// 查询标签列表校验令牌是否有效（令牌管理接口只允许登录会话访问）
+	c := newClient(*server, *token)
+	var tags []dto.TagListItem
+	if err := c.call(http.MethodGet, "/tags", nil, nil, &tags); err != nil {
+		return err
+	}
+
+	a.config.Server = strings.TrimRight(*server, "/")
+	a.config.Token = *token
+	path, err := saveConfig(a.config)
+	if err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, "登录成功，配置已保存到 "+path)
+	return nil
+}
+
+// addCommand 添加书签
+// 用法: bkms add [-tags a,b] [-title 标题] [-archive] [-auto-tag] URL...
+func addCommand(a *app, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	tags := fs.String("tags", "", "标签，使用英文逗号分隔")
+	title := fs.String("title", "", "标题，为空时自动获取")
+	archive := fs.Bool("archive", false, "获取网页内容并创建归档")
+	autoTag := fs.Bool("auto-tag", false, "自动添加推荐标签")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errors.New("用法: bkms add [-tags a,b] [-title 标题] [-archive] [-auto-tag] URL...")
+	}
+
+	tagItems := make([]dto.TagItem, 0)
+	for _, name := range splitList(*tags) {
+		tagItems = append(tagItems, dto.TagItem{Name: name})
+	}
+
+	var created []dto.CreateBookmarkData
+	failed := 0
+	for _, u := range fs.Args() {
+		var data dto.CreateBookmarkData
+		err := a.client.call(http.MethodPost, "/bookmark", nil, dto.CreateBookmarkRequest{
+			URL:           u,
+			Title:         *title,
+			Tags:          tagItems,
+			CreateArchive: *archive,
+			AutoTag:       *autoTag,
+		}, &data)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "添加失败: %s - %v\n", u, err)
+			continue
+		}
+		created = append(created, data)
+
+		switch a.output {
+		case outputURL:
+			fmt.Println(u)
+		case outputTable:
+			fmt.Printf("%d\t%s\n", data.ID, u)
+			if len(data.AutoTags) > 0 {
+				fmt.Fprintf(os.Stderr, "  自动标签: %s\n", joinTags(data.AutoTags))
+			}
+			for _, similar := range data.Similar {
+				fmt.Fprintf(os.Stderr, "  内容相似: %s（%.0f%%）\n", similar.URL, similar.Similarity*100)
+			}
+		}
+	}
+	if a.output == outputJSON {
+		if err := printJSON(created); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个书签添加失败", failed)
+	}
+	return nil
+}
+
+// listCommand 列出书签
+// 用法: bkms list [-tags a,b] [-state unread,reading] [-favorite] [-page 1] [-size 20] [-all]
+func listCommand(a *app, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	filter := bindListFlags(fs)
+	fs.Parse(args)
+	return a.listBookmarks(filter.query(""), filter)
+}
+
+// searchCommand 按关键字搜索书签，参数与 list 相同
+// 用法: bkms search [-tags a,b] [-state ...] [-favorite] [-page 1] [-size 20] [-all] 关键字...
+func searchCommand(a *app, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	filter := bindListFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errors.New("用法: bkms search [参数] 关键字...")
+	}
+	return a.listBookmarks(filter.query(strings.Join(fs.Args(), " ")), filter)
+}
+
+// listFlags 书签列表的过滤与分页参数
+type listFlags struct {
+	tags     *string
+	state    *string
+	favorite *bool
+	page     *int
+	size     *int
+	all      *bool
+}
+
+func bindListFlags(fs *flag.FlagSet) *listFlags {
+	return &listFlags{
+		tags:     fs.String("tags", "", "标签，使用英文逗号分隔，需包含全部标签"),
+		state:    fs.String("state", "", "阅读状态: unread、reading、read、archived，使用英文逗号分隔"),
+		favorite: fs.Bool("favorite", false, "只显示收藏的书签"),
+		page:     fs.Int("page", 1, "页码"),
+		size:     fs.Int("size", 20, "每页数量"),
+		all:      fs.Bool("all", false, "查询全部页"),
+	}
+}
+
+// query 过滤条件对应的查询参数（不含分页）
+func (f *listFlags) query(keyword string) url.Values {
+	query := url.Values{}
+	if keyword != "" {
+		query.Set("keyword", keyword)
+	}
+	if *f.tags != "" {
+		query.Set("tags", *f.tags)
+	}
+	if *f.state != "" {
+		query.Set("state", *f.state)
+	}
+	if *f.favorite {
+		query.Set("favorite", "true")
+	}
+	return query
+}
+
+// bookmarkPage 书签列表分页数据
+type bookmarkPage struct {
+	Rows  []dto.BookmarkListItem `json:"rows"`
+	Total int                    `json:"total"`
+}
+
+func (a *app) listBookmarks(query url.Values, f *listFlags) error {
+	page, size := *f.page, *f.size
+	if *f.all {
+		page, size = 1, listAllPageSize
+	}
+
+	var items []dto.BookmarkListItem
+	total := 0
+	for {
+		query.Set("page", itoa(page))
+		query.Set("page_size", itoa(size))
+		var data bookmarkPage
+		if err := a.client.call(http.MethodGet, "/bookmarks", query, nil, &data); err != nil {
+			return err
+		}
+		items = append(items, data.Rows...)
+		total = data.Total
+		if !*f.all || len(data.Rows) == 0 || len(items) >= total {
+			break
+		}
+		page++
+	}
+	return a.printBookmarks(items, total)
+}
+
+// importCommand 导入书签文件
+// .html 为浏览器书签文件，实时显示服务端的导入进度；.jsonl/.csv 为原生格式，按 URL 导入
+// 用法: bkms import [-generate-tag] [-archive] [-auto-tag] [-map-folders] [-conflict skip|overwrite|merge_tags] 文件
+func importCommand(a *app, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	generateTag := fs.Bool("generate-tag", false, "使用书签文件夹名生成标签（.html）")
+	archive := fs.Bool("archive", false, "获取网页内容并创建归档（.html）")
+	autoTag := fs.Bool("auto-tag", false, "自动添加推荐标签（.html）")
+	mapFolders := fs.Bool("map-folders", false, "按书签文件夹创建收藏夹（.html）")
+	conflict := fs.String("conflict", "skip", "URL 已存在时的处理方式: skip、overwrite、merge_tags（.jsonl/.csv）")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("用法: bkms import [参数] 文件")
+	}
+	file := fs.Arg(0)
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".html", ".htm":
+		return a.importHTML(file, map[string]string{
+			"generate_tag":   strconv.FormatBool(*generateTag),
+			"create_archive": strconv.FormatBool(*archive),
+			"auto_tag":       strconv.FormatBool(*autoTag),
+			"map_folders":    strconv.FormatBool(*mapFolders),
+		})
+	case ".jsonl", ".ndjson", ".csv":
+		return a.importRecords(file, *conflict)
+	default:
+		return errors.New("仅支持 .html、.jsonl 与 .csv 文件")
+	}
+}
+
+// importHTML 上传浏览器书签文件，逐条读取 SSE 进度事件并输出
+func (a *app) importHTML(file string, fields map[string]string) error {
+	resp, err := a.client.upload("/bookmarks/import", "bookmark_file", file, fields)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if isJSON(resp) {
+		return decodeEnvelope(resp, nil)
+	}
+
+	var complete *dto.ImportProgressEvent
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var event dto.ImportProgressEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+			continue
+		}
+		if event.Type == "complete" {
+			complete = &event
+		}
+
+		switch a.output {
+		case outputJSON:
+			// 每个事件一行，便于逐行处理
+			data, _ := json.Marshal(event)
+			fmt.Println(string(data))
+		case outputURL:
+			if event.Type == "success" {
+				fmt.Println(event.URL)
+			}
+		default:
+			if event.Total > 0 && event.Type != "complete" {
+				fmt.Printf("[%d/%d] %s\n", event.Current, event.Total, event.Message)
+			} else {
+				fmt.Println(event.Message)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if complete == nil {
+		return errors.New("导入未完成，连接已中断")
+	}
+	return nil
+}
+
+// importRecords 上传 JSON Lines / CSV 文件按 URL 导入
+func (a *app) importRecords(file, conflict string) error {
+	resp, err := a.client.upload("/bookmarks/import/records", "file", file, map[string]string{"conflict": conflict})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result dto.BookmarkRecordImportResult
+	if err := decodeEnvelope(resp, &result); err != nil {
+		return err
+	}
+	if a.output == outputJSON {
+		return printJSON(result)
+	}
+	for _, recordErr := range result.Errors {
+		fmt.Fprintf(os.Stderr, "第 %d 行: %s %s\n", recordErr.Line, recordErr.Message, recordErr.URL)
+	}
+	fmt.Printf("导入完成！新建: %d, 更新: %d, 跳过: %d, 失败: %d\n", result.Created, result.Updated, result.Skipped, result.Failed)
+	return nil
+}
+
+// exportCommand 导出书签，默认输出到标准输出
+// 用法: bkms export [-format jsonl|csv|markdown] [-out 文件] [-keyword 关键字] [-tags a,b] [-state ...] [-favorite]
+func exportCommand(a *app, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "jsonl", "导出格式: jsonl、csv、markdown（zip）")
+	out := fs.String("out", "", "输出文件，默认输出到标准输出")
+	keyword := fs.String("keyword", "", "关键字")
+	filter := &listFlags{
+		tags:     fs.String("tags", "", "标签，使用英文逗号分隔，需包含全部标签"),
+		state:    fs.String("state", "", "阅读状态: unread、reading、read、archived，使用英文逗号分隔"),
+		favorite: fs.Bool("favorite", false, "只导出收藏的书签"),
+	}
+	fs.Parse(args)
+	if *format != "jsonl" && *format != "csv" && *format != "markdown" {
+		return fmt.Errorf("不支持的导出格式: %s", *format)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := a.client.download("/bookmarks/export/"+*format, filter.query(*keyword), w); err != nil {
+		if *out != "" {
+			os.Remove(*out)
+		}
+		return err
+	}
+	if *out != "" {
+		fmt.Fprintln(os.Stderr, "导出完成: "+*out)
+	}
+	return nil
+}
+
+// tagCommand 标签管理
+// 用法: bkms tag list [-name 名称] | bkms tag rename 原名称 新名称 | bkms tag merge 目标标签 被合并的标签...
+func tagCommand(a *app, args []string) error {
+	const tagUsage = "用法: bkms tag list [-name 名称] | rename 原名称 新名称 | merge 目标标签 被合并的标签..."
+	if len(args) == 0 {
+		return errors.New(tagUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("tag list", flag.ExitOnError)
+		name := fs.String("name", "", "按名称模糊查询")
+		fs.Parse(args[1:])
+		tags, err := a.listTags(*name)
+		if err != nil {
+			return err
+		}
+		return a.printTags(tags)
+
+	case "rename":
+		if len(args) != 3 {
+			return errors.New("用法: bkms tag rename 原名称 新名称")
+		}
+		tag, err := a.findTag(args[1])
+		if err != nil {
+			return err
+		}
+		if existing, err := a.findTag(args[2]); err == nil && existing.ID != tag.ID {
+			return fmt.Errorf("标签 %s 已存在，可以使用 bkms tag merge %s %s 合并", args[2], args[2], args[1])
+		}
+		if err := a.client.call(http.MethodPut, "/tag/"+itoa(tag.ID), nil, dto.UpdateTagRequest{Name: args[2]}, nil); err != nil {
+			return err
+		}
+		return a.printResult("已重命名: "+args[1]+" -> "+args[2], map[string]interface{}{"id": tag.ID, "name": args[2]})
+
+	case "merge":
+		if len(args) < 3 {
+			return errors.New("用法: bkms tag merge 目标标签 被合并的标签...")
+		}
+		target, err := a.findTag(args[1])
+		if err != nil {
+			return err
+		}
+		req := dto.MergeTagRequest{TargetID: target.ID}
+		for _, name := range args[2:] {
+			source, err := a.findTag(name)
+			if err != nil {
+				return err
+			}
+			req.SourceIDs = append(req.SourceIDs, source.ID)
+		}
+		var data struct {
+			Bookmarks int `json:"bookmarks"`
+		}
+		if err := a.client.call(http.MethodPost, "/tags/merge", nil, req, &data); err != nil {
+			return err
+		}
+		message := fmt.Sprintf("已合并到 %s，涉及 %d 个书签", target.Name, data.Bookmarks)
+		return a.printResult(message, map[string]interface{}{"target": target, "bookmarks": data.Bookmarks})
+
+	default:
+		return errors.New(tagUsage)
+	}
+}
+
+func (a *app) listTags(name string) ([]dto.TagListItem, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+	var tags []dto.TagListItem
+	err := a.client.call(http.MethodGet, "/tags", query, nil, &tags)
+	return tags, err
+}
+
+// findTag 按名称精确查找标签
+func (a *app) findTag(name string) (*dto.TagListItem, error) {
+	tags, err := a.listTags(name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tags {
+		if tags[i].Name == name {
+			return &tags[i], nil
+		}
+	}
+	return nil, fmt.Errorf("标签不存在: %s", name)
+}
+
+// splitList 按英文逗号分隔并去掉空项
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
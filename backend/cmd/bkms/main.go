@@ -0,0 +1,106 @@
+// bkms 是 bk_kms 的命令行客户端，通过 REST 接口管理书签，输出支持表格、JSON 与只输出 URL，便于在脚本中使用。
+//
+// 用法: bkms [全局参数] <命令> [参数]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `bkms - bk_kms 命令行客户端
+
+用法: bkms [全局参数] <命令> [参数]
+
+命令:
+  login    使用个人访问令牌登录，保存服务器地址与令牌
+  add      添加书签
+  list     列出书签
+  search   按关键字搜索书签
+  import   导入书签文件（.html 实时显示进度，.jsonl/.csv 按 URL 导入）
+  export   导出书签（jsonl、csv、markdown）
+  tag      标签管理（list、rename、merge）
+
+全局参数:
+  -server  服务器地址，默认读取配置文件或环境变量 BKMS_SERVER
+  -token   个人访问令牌，默认读取配置文件或环境变量 BKMS_TOKEN
+  -output  输出格式: table（默认）、json、url
+
+使用 "bkms <命令> -h" 查看命令的参数。
+`
+
+// command 子命令
+type command func(app *app, args []string) error
+
+var commands = map[string]command{
+	"login":  loginCommand,
+	"add":    addCommand,
+	"list":   listCommand,
+	"search": searchCommand,
+	"import": importCommand,
+	"export": exportCommand,
+	"tag":    tagCommand,
+}
+
+// app 命令执行环境
+type app struct {
+	config *config
+	client *client
+	output string
+}
+
+func main() {
+	fs := flag.NewFlagSet("bkms", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	server := fs.String("server", "", "服务器地址")
+	token := fs.String("token", "", "个人访问令牌")
+	output := fs.String("output", outputTable, "输出格式: table、json、url")
+	fs.Parse(os.Args[1:])
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	run, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "bkms: 未知命令 %s\n\n", fs.Arg(0))
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *output != outputTable && *output != outputJSON && *output != outputURL {
+		fatal(fmt.Errorf("不支持的输出格式: %s", *output))
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	// 优先级: 命令行参数 > 环境变量 > 配置文件
+	if v := os.Getenv("BKMS_SERVER"); v != "" {
+		cfg.Server = v
+	}
+	if v := os.Getenv("BKMS_TOKEN"); v != "" {
+		cfg.Token = v
+	}
+	if *server != "" {
+		cfg.Server = *server
+	}
+	if *token != "" {
+		cfg.Token = *token
+	}
+
+	a := &app{
+		config: cfg,
+		client: newClient(cfg.Server, cfg.Token),
+		output: *output,
+	}
+	if err := run(a, fs.Args()[1:]); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "bkms: "+err.Error())
+	os.Exit(1)
+}
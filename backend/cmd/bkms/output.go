@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bk_kms/model/dto"
+)
+
+// 输出格式
+const (
+	outputTable = "table" // 对齐的表格，便于阅读
+	outputJSON  = "json"  // JSON，便于 jq 等工具处理
+	outputURL   = "url"   // 每行一个 URL（标签命令为标签名），便于管道处理
+)
+
+const titleWidth = 50 // 表格中标题的最大显示宽度（字符数）
+
+// printJSON 以缩进的 JSON 输出
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printBookmarks 输出书签列表
+func (a *app) printBookmarks(items []dto.BookmarkListItem, total int) error {
+	switch a.output {
+	case outputJSON:
+		return printJSON(map[string]interface{}{"total": total, "rows": items})
+	case outputURL:
+		for _, item := range items {
+			fmt.Println(item.URL)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tTAGS\tCREATED\tURL")
+	for _, item := range items {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+			item.ID,
+			truncate(oneLine(item.Title), titleWidth),
+			joinTags(item.Tags),
+			time.Unix(item.CreatedAt, 0).Format("2006-01-02"),
+			item.URL,
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if total > len(items) {
+		fmt.Fprintf(os.Stderr, "共 %d 个书签，显示 %d 个\n", total, len(items))
+	}
+	return nil
+}
+
+// printTags 输出标签列表
+func (a *app) printTags(tags []dto.TagListItem) error {
+	switch a.output {
+	case outputJSON:
+		return printJSON(tags)
+	case outputURL:
+		for _, tag := range tags {
+			fmt.Println(tag.Name)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCOUNT")
+	for _, tag := range tags {
+		fmt.Fprintf(w, "%d\t%s\t%d\n", tag.ID, tag.Name, tag.Count)
+	}
+	return w.Flush()
+}
+
+// printResult 输出操作结果：json 格式输出 data，其他格式输出提示信息
+func (a *app) printResult(message string, data interface{}) error {
+	if a.output == outputJSON {
+		return printJSON(data)
+	}
+	fmt.Println(message)
+	return nil
+}
+
+func joinTags(tags []dto.TagItem) string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncate 按字符数截断，超出部分以省略号表示
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
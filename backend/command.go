@@ -0,0 +1,185 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"bk_kms/backup"
+	"bk_kms/lib"
+	"bk_kms/migrate"
+	"bk_kms/model/db"
+	"bk_kms/repo"
+)
+
+const usage = `bk_kms - 书签知识管理服务
+
+用法: bk_kms [-config 配置文件] [-set key=value]... [-print-config] [命令] [参数]
+
+命令:
+  serve        启动 HTTP 服务（默认）
+  migrate      执行数据库迁移，-status 查看迁移状态，-down N 回滚最近的 N 个迁移（删除数据时需要 -force）
+  user         用户管理（create、reset-password）
+  reindex      重新计算书签的规范化 URL 与正文指纹，并检查书签索引
+  rearchive    重新获取书签的网页内容并更新归档
+  check-links  检查书签链接是否可以访问
+  backup       生成全量备份文件
+  restore      从备份文件恢复
+
+配置的优先级从低到高：默认配置、配置文件、环境变量、-set 参数。
+配置文件默认为 config/config.yaml，也可以通过环境变量 BKKMS_CONFIG 指定；
+环境变量为 BKKMS_ 加上大写的配置项路径，如 BKKMS_MYSQL_PASSWORD、BKKMS_JWT_SECRET。
+使用 "bk_kms <命令> -h" 查看命令的参数。
+`
+
+// command 子命令，配置、日志与数据库已初始化
+type command func(config *lib.Config, args []string) error
+
+var commands = map[string]command{
+	"serve":       serveCommand,
+	"migrate":     migrateCommand,
+	"user":        userCommand,
+	"reindex":     reindexCommand,
+	"rearchive":   rearchiveCommand,
+	"check-links": checkLinksCommand,
+	"backup":      backupCommand,
+	"restore":     restoreCommand,
+}
+
+// migrateCommand 执行未执行的数据库迁移，或回滚最近执行的迁移
+// 用法: bk_kms migrate [-status] [-down N [-force]]
+func migrateCommand(config *lib.Config, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	status := fs.Bool("status", false, "只查看迁移状态，不执行迁移")
+	down := fs.Int("down", 0, "回滚最近执行的 N 个迁移")
+	force := fs.Bool("force", false, "回滚会删除数据（删除表或字段）时确认执行")
+	fs.Parse(args)
+
+	if *status {
+		statuses, err := migrate.List()
+		if err != nil {
+			return fmt.Errorf("查询迁移状态失败: %w", err)
+		}
+		for _, s := range statuses {
+			appliedAt := "未执行"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d  %-30s %s\n", s.Version, s.Name, appliedAt)
+		}
+		return nil
+	}
+
+	if *down > 0 {
+		rolledBack, err := migrate.Down(*down, *force)
+		for _, m := range rolledBack {
+			fmt.Printf("已回滚迁移: %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		current, err := migrate.Current()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("回滚完成，当前版本: %d\n", current)
+		return nil
+	}
+
+	ran, err := migrate.Up()
+	for _, m := range ran {
+		fmt.Printf("已执行迁移: %d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(ran) == 0 {
+		fmt.Printf("数据库已是最新版本: %d\n", migrate.Latest())
+		return nil
+	}
+	fmt.Printf("迁移完成，当前版本: %d\n", migrate.Latest())
+	return nil
+}
+
+// backupCommand 生成全量备份文件
+// 用法: bk_kms backup [-o 文件路径]
+func backupCommand(config *lib.Config, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := fs.String("o", "", "备份文件路径，默认为当前目录下的 bk_kms-backup-<时间>.zip")
+	fs.Parse(args)
+
+	path := *output
+	if path == "" {
+		path = "bk_kms-backup-" + time.Now().Format("20060102150405") + ".zip"
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建备份文件失败: %w", err)
+	}
+	manifest, err := backup.Write(file)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("生成备份失败: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入备份文件失败: %w", err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(manifest.Tables)) {
+		fmt.Printf("%-20s %d\n", name, manifest.Tables[name])
+	}
+	fmt.Printf("备份完成: %s（%d 个归档快照）\n", path, manifest.Snapshots)
+	(&repo.AuditRepo{}).Record("cli", db.AuditActionBackup, db.AuditEntityBackup, nil, map[string]interface{}{
+		"schema_version":    manifest.SchemaVersion,
+		"migration_version": manifest.MigrationVersion,
+		"tables":            manifest.Tables,
+		"snapshots":         manifest.Snapshots,
+	})
+	return nil
+}
+
+// restoreCommand 从备份文件恢复
+// 用法: bk_kms restore [-mode merge|replace] <备份文件>
+func restoreCommand(config *lib.Config, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	mode := fs.String("mode", backup.ModeMerge, "恢复模式: merge（合并）或 replace（清空后替换）")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("用法: bk_kms restore [-mode merge|replace] <备份文件>")
+	}
+
+	zr, err := zip.OpenReader(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("打开备份文件失败: %w", err)
+	}
+	defer zr.Close()
+
+	result, err := backup.Restore(&zr.Reader, *mode)
+	if err != nil {
+		return fmt.Errorf("恢复备份失败: %w", err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(result.Tables)) {
+		tr := result.Tables[name]
+		fmt.Printf("%-20s 写入 %d，跳过 %d\n", name, tr.Restored, tr.Skipped)
+		if len(tr.Dropped) > 0 {
+			fmt.Printf("%-20s 当前表中不存在，已丢弃字段: %s\n", "", strings.Join(tr.Dropped, ", "))
+		}
+	}
+	fmt.Printf("恢复完成（%s 模式），运行中的服务需要重启以重建书签索引\n", result.Mode)
+	(&repo.AuditRepo{}).Record("cli", db.AuditActionRestore, db.AuditEntityBackup, nil, map[string]interface{}{
+		"mode":              result.Mode,
+		"schema_version":    result.Manifest.SchemaVersion,
+		"migration_version": result.Manifest.MigrationVersion,
+		"created_at":        result.Manifest.CreatedAt,
+		"tables":            result.Tables,
+	})
+	return nil
+}
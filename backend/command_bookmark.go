@@ -0,0 +1,291 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bk_kms/index"
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+const filterUsage = "书签过滤条件，如 \"tag:go id:1,2 archived:false 关键字\"，为空表示全部书签"
+
+// reindexCommand 重新计算全部书签的规范化 URL 与正文指纹，并重新构建书签索引检查数据
+// 用法: bk_kms reindex
+func reindexCommand(config *lib.Config, args []string) error {
+	fs := flag.NewFlagSet("reindex", flag.ExitOnError)
+	fs.Parse(args)
+
+	bookmarkRepo := &repo.BookmarkRepo{}
+	canonical, err := bookmarkRepo.RefreshCanonicalURLs()
+	if err != nil {
+		return fmt.Errorf("重新计算规范化 URL 失败: %w", err)
+	}
+	fmt.Printf("规范化 URL: 更新 %d 个书签\n", canonical)
+
+	simHashes, empty, err := bookmarkRepo.RefreshSimHashes()
+	if err != nil {
+		return fmt.Errorf("重新计算正文指纹失败: %w", err)
+	}
+	fmt.Printf("正文指纹: 更新 %d 个书签，%d 个书签没有正文\n", simHashes, empty)
+
+	if err := index.Build(); err != nil {
+		return fmt.Errorf("构建书签索引失败: %w", err)
+	}
+	fmt.Printf("书签索引: %d 个书签\n", index.Default().Size())
+	fmt.Println("书签索引保存在服务进程内，运行中的服务需要重启以重建书签索引")
+
+	(&repo.AuditRepo{}).Record("cli", db.AuditActionUpdate, db.AuditEntityBookmark, nil, map[string]interface{}{
+		"reindex":       true,
+		"canonical_url": canonical,
+		"simhash":       simHashes,
+	})
+	return nil
+}
+
+// rearchiveCommand 重新获取书签的网页内容并更新归档，完成后重新定位高亮
+// 用法: bk_kms rearchive [-filter 过滤条件] [-concurrency 4] [-title] [-dry-run]
+func rearchiveCommand(config *lib.Config, args []string) error {
+	fs := flag.NewFlagSet("rearchive", flag.ExitOnError)
+	filterExpr := fs.String("filter", "", filterUsage)
+	concurrency := fs.Int("concurrency", 4, "同时获取的网页数")
+	withTitle := fs.Bool("title", false, "同时使用网页的标题与摘要替换书签的标题与摘要")
+	dryRun := fs.Bool("dry-run", false, "只列出匹配的书签，不获取网页内容")
+	fs.Parse(args)
+
+	filter, err := parseBookmarkFilter(*filterExpr)
+	if err != nil {
+		return err
+	}
+	bookmarkRepo := &repo.BookmarkRepo{}
+	ids, err := bookmarkRepo.ListIDs(filter)
+	if err != nil {
+		return fmt.Errorf("查询书签失败: %w", err)
+	}
+	if *dryRun {
+		return printBookmarkURLs(bookmarkRepo, ids)
+	}
+
+	highlightRepo := &repo.HighlightRepo{}
+	var mu sync.Mutex
+	var updated []int
+	failed := 0
+	forEachConcurrent(ids, *concurrency, func(id int) {
+		bookmark, err := bookmarkRepo.FindByID(id)
+		if err != nil {
+			mu.Lock()
+			failed++
+			fmt.Printf("失败 %d: 查询书签失败: %v\n", id, err)
+			mu.Unlock()
+			return
+		}
+		if err := rearchiveBookmark(bookmarkRepo, bookmark, *withTitle); err != nil {
+			mu.Lock()
+			failed++
+			fmt.Printf("失败 %d %s: %v\n", id, bookmark.URL, err)
+			mu.Unlock()
+			return
+		}
+		orphaned, err := highlightRepo.Reanchor(bookmark.ID, bookmark.Content)
+		if err != nil {
+			lib.Logger.Error("重新定位高亮失败: " + err.Error())
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		updated = append(updated, id)
+		fmt.Printf("成功 %d %s\n", id, bookmark.URL)
+		if orphaned > 0 {
+			fmt.Printf("  %d 个高亮在新的归档内容中找不到原文\n", orphaned)
+		}
+	})
+
+	fmt.Printf("重新归档完成！成功: %d, 失败: %d\n", len(updated), failed)
+	if len(updated) > 0 {
+		fmt.Println("运行中的服务需要重启以更新书签索引")
+		(&repo.AuditRepo{}).Record("cli", db.AuditActionUpdate, db.AuditEntityBookmark, updated, map[string]interface{}{
+			"rearchive": true,
+			"filter":    *filterExpr,
+			"title":     *withTitle,
+		})
+	}
+	return nil
+}
+
+// rearchiveBookmark 获取网页内容并更新书签的归档，不修改用户编辑过的标题、摘要与标签（withTitle 为 true 时更新标题与摘要）
+func rearchiveBookmark(bookmarkRepo *repo.BookmarkRepo, bookmark *db.Bookmark, withTitle bool) error {
+	content, err := utils.FetchBookmarkContent(bookmark.URL, false, false)
+	if err != nil {
+		return err
+	}
+	if withTitle {
+		bookmark.Title = content.Title
+		bookmark.Excerpt = content.Excerpt
+	}
+	bookmark.Author = content.Author
+	bookmark.Content = content.Content
+	bookmark.HTML = content.HTML
+	bookmark.IsArchive = true
+	return bookmarkRepo.UpdateArchive(bookmark, withTitle)
+}
+
+// checkLinksCommand 检查书签链接是否可以访问，输出无法访问的书签，可为其添加标签
+// 用法: bk_kms check-links [-filter 过滤条件] [-concurrency 8] [-timeout 15s] [-tag 标签]
+func checkLinksCommand(config *lib.Config, args []string) error {
+	fs := flag.NewFlagSet("check-links", flag.ExitOnError)
+	filterExpr := fs.String("filter", "", filterUsage)
+	concurrency := fs.Int("concurrency", 8, "同时检查的链接数")
+	timeout := fs.Duration("timeout", 15*time.Second, "每个链接的超时时间")
+	tag := fs.String("tag", "", "为无法访问的书签添加的标签，为空表示不添加")
+	fs.Parse(args)
+
+	filter, err := parseBookmarkFilter(*filterExpr)
+	if err != nil {
+		return err
+	}
+	bookmarkRepo := &repo.BookmarkRepo{}
+	ids, err := bookmarkRepo.ListIDs(filter)
+	if err != nil {
+		return fmt.Errorf("查询书签失败: %w", err)
+	}
+
+	var mu sync.Mutex
+	var broken []int
+	for start := 0; start < len(ids); start += 100 {
+		bookmarks, err := bookmarkRepo.FindByIDs(ids[start:min(start+100, len(ids))])
+		if err != nil {
+			return fmt.Errorf("查询书签失败: %w", err)
+		}
+		byID := make(map[int]*db.Bookmark, len(bookmarks))
+		batch := make([]int, 0, len(bookmarks))
+		for i := range bookmarks {
+			byID[bookmarks[i].ID] = &bookmarks[i]
+			batch = append(batch, bookmarks[i].ID)
+		}
+
+		forEachConcurrent(batch, *concurrency, func(id int) {
+			bookmark := byID[id]
+			status, err := utils.CheckLink(bookmark.URL, *timeout)
+			if err == nil && status < http.StatusBadRequest {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			broken = append(broken, id)
+			if err != nil {
+				fmt.Printf("%d\t%s\t%v\n", id, bookmark.URL, err)
+			} else {
+				fmt.Printf("%d\t%s\tHTTP %d\n", id, bookmark.URL, status)
+			}
+		})
+	}
+	fmt.Printf("检查完成！共 %d 个书签，%d 个无法访问\n", len(ids), len(broken))
+
+	if *tag == "" || len(broken) == 0 {
+		return nil
+	}
+	tags, err := bookmarkRepo.FindOrCreateTags([]string{*tag})
+	if err != nil {
+		return fmt.Errorf("创建标签失败: %w", err)
+	}
+	bookmarks, err := bookmarkRepo.FindByIDs(broken)
+	if err != nil {
+		return fmt.Errorf("查询书签失败: %w", err)
+	}
+	for i := range bookmarks {
+		if err := bookmarkRepo.AddTags(&bookmarks[i], tags); err != nil {
+			return fmt.Errorf("添加标签失败: %w", err)
+		}
+	}
+	fmt.Printf("已为 %d 个书签添加标签: %s\n", len(bookmarks), *tag)
+	(&repo.AuditRepo{}).Record("cli", db.AuditActionUpdate, db.AuditEntityBookmark, broken, map[string]interface{}{
+		"check_links": true,
+		"tags_added":  []string{*tag},
+	})
+	return nil
+}
+
+// parseBookmarkFilter 解析书签过滤条件，多个条件以空格分隔，需同时满足
+//   - tag:名称  包含标签，可出现多次
+//   - id:1,2,3  限定书签ID
+//   - archived:true|false  是否已存档
+//   - 其余内容作为全文搜索关键字
+func parseBookmarkFilter(expr string) (repo.BookmarkFilter, error) {
+	var filter repo.BookmarkFilter
+	var keywords []string
+	for _, term := range strings.Fields(expr) {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || value == "" {
+			keywords = append(keywords, term)
+			continue
+		}
+		switch key {
+		case "tag":
+			filter.Tags = append(filter.Tags, value)
+		case "id":
+			for _, s := range strings.Split(value, ",") {
+				id, err := strconv.Atoi(s)
+				if err != nil {
+					return filter, fmt.Errorf("书签ID格式错误: %s", s)
+				}
+				filter.IDs = append(filter.IDs, id)
+			}
+		case "archived":
+			archive, err := strconv.ParseBool(value)
+			if err != nil {
+				return filter, fmt.Errorf("archived 只能为 true 或 false: %s", value)
+			}
+			filter.Archive = &archive
+		default:
+			keywords = append(keywords, term)
+		}
+	}
+	filter.Keyword = strings.Join(keywords, " ")
+	return filter, nil
+}
+
+// printBookmarkURLs 输出书签ID与URL
+func printBookmarkURLs(bookmarkRepo *repo.BookmarkRepo, ids []int) error {
+	for start := 0; start < len(ids); start += 100 {
+		bookmarks, err := bookmarkRepo.FindByIDs(ids[start:min(start+100, len(ids))])
+		if err != nil {
+			return fmt.Errorf("查询书签失败: %w", err)
+		}
+		for _, bookmark := range bookmarks {
+			fmt.Printf("%d\t%s\n", bookmark.ID, bookmark.URL)
+		}
+	}
+	fmt.Printf("共 %d 个书签\n", len(ids))
+	return nil
+}
+
+// forEachConcurrent 使用 workers 个协程处理全部 ID，全部处理完成后返回
+func forEachConcurrent(ids []int, workers int, fn func(id int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	ch := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ch {
+				fn(id)
+			}
+		}()
+	}
+	for _, id := range ids {
+		ch <- id
+	}
+	close(ch)
+	wg.Wait()
+}
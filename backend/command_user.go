@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+const userUsage = "用法: bk_kms user create -username 用户名 [-password 密码] [-role admin|user] | reset-password -username 用户名 [-password 密码]"
+
+// userCommand 用户管理
+func userCommand(config *lib.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(userUsage)
+	}
+	switch args[0] {
+	case "create":
+		return createUserCommand(args[1:])
+	case "reset-password":
+		return resetPasswordCommand(args[1:])
+	default:
+		return errors.New(userUsage)
+	}
+}
+
+// createUserCommand 创建用户，不指定密码时随机生成并输出
+// 用法: bk_kms user create -username 用户名 [-password 密码] [-role admin|user]
+func createUserCommand(args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ExitOnError)
+	username := fs.String("username", "", "用户名")
+	password := fs.String("password", "", "密码，为空时随机生成")
+	role := fs.String("role", db.RoleUser, "角色: admin、user")
+	fs.Parse(args)
+
+	if *username == "" {
+		return errors.New("请通过 -username 指定用户名")
+	}
+	if *role != db.RoleAdmin && *role != db.RoleUser {
+		return fmt.Errorf("不支持的角色: %s", *role)
+	}
+
+	userRepo := &repo.UserRepo{}
+	if _, err := userRepo.FindByUsername(*username); err == nil {
+		return fmt.Errorf("用户已存在: %s", *username)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	plain, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+	salt, err := utils.GenerateSalt()
+	if err != nil {
+		return err
+	}
+	user := &db.User{
+		Username: *username,
+		Password: utils.HashPassword(plain, salt),
+		Salt:     salt,
+		Role:     *role,
+	}
+	if err := userRepo.Create(user); err != nil {
+		return fmt.Errorf("创建用户失败: %w", err)
+	}
+
+	fmt.Printf("用户创建成功: %s（%s）\n", user.Username, user.Role)
+	if generated {
+		fmt.Println("密码: " + plain)
+	}
+	(&repo.AuditRepo{}).Record("cli", db.AuditActionCreate, db.AuditEntityUser, []int{user.ID}, map[string]interface{}{
+		"username": user.Username,
+		"role":     user.Role,
+	})
+	return nil
+}
+
+// resetPasswordCommand 重置用户密码，不指定密码时随机生成并输出
+// 用法: bk_kms user reset-password -username 用户名 [-password 密码]
+func resetPasswordCommand(args []string) error {
+	fs := flag.NewFlagSet("user reset-password", flag.ExitOnError)
+	username := fs.String("username", "", "用户名")
+	password := fs.String("password", "", "新密码，为空时随机生成")
+	fs.Parse(args)
+
+	if *username == "" {
+		return errors.New("请通过 -username 指定用户名")
+	}
+
+	userRepo := &repo.UserRepo{}
+	user, err := userRepo.FindByUsername(*username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("用户不存在: %s", *username)
+	}
+	if err != nil {
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	plain, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+	salt, err := utils.GenerateSalt()
+	if err != nil {
+		return err
+	}
+	if err := userRepo.UpdatePassword(user.ID, utils.HashPassword(plain, salt), salt); err != nil {
+		return fmt.Errorf("重置密码失败: %w", err)
+	}
+
+	fmt.Println("密码已重置: " + user.Username)
+	if generated {
+		fmt.Println("新密码: " + plain)
+	}
+	(&repo.AuditRepo{}).Record("cli", db.AuditActionUpdate, db.AuditEntityUser, []int{user.ID}, map[string]interface{}{
+		"username": user.Username,
+		"password": "reset",
+	})
+	return nil
+}
+
+// passwordOrRandom 返回指定的密码，为空时随机生成
+func passwordOrRandom(password string) (string, bool, error) {
+	if password != "" {
+		return password, false, nil
+	}
+	random, err := utils.RandomString(12)
+	if err != nil {
+		return "", false, err
+	}
+	return random, true, nil
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+type AuditController struct {
+	auditRepo *repo.AuditRepo
+}
+
+func NewAuditController() *AuditController {
+	return &AuditController{
+		auditRepo: &repo.AuditRepo{},
+	}
+}
+
+// List 审计日志列表（仅管理员）
+func (ac *AuditController) List(c *gin.Context) {
+	var req dto.AuditListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 设置默认分页大小
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	filter := repo.AuditFilter{
+		UserID:     req.UserID,
+		Action:     req.Action,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+	}
+	if req.Start > 0 {
+		filter.Start = time.Unix(req.Start, 0)
+	}
+	if req.End > 0 {
+		filter.End = time.Unix(req.End, 0)
+	}
+
+	logs, total, err := ac.auditRepo.List(filter, req.Page, req.PageSize)
+	if err != nil {
+		lib.Logger.Error("查询审计日志失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	// 转换为 DTO
+	items := make([]dto.AuditListItem, 0, len(logs))
+	for _, log := range logs {
+		entityIDs := make([]int, 0)
+		for _, idStr := range strings.Split(log.EntityIDs, ",") {
+			if id, err := strconv.Atoi(idStr); err == nil {
+				entityIDs = append(entityIDs, id)
+			}
+		}
+
+		var diff interface{}
+		if log.Diff != "" {
+			diff = json.RawMessage(log.Diff)
+		}
+
+		items = append(items, dto.AuditListItem{
+			ID:         log.ID,
+			UserID:     log.UserID,
+			Username:   log.Username,
+			Action:     log.Action,
+			EntityType: log.EntityType,
+			EntityIDs:  entityIDs,
+			Diff:       diff,
+			IP:         log.IP,
+			UserAgent:  log.UserAgent,
+			CreatedAt:  log.CreatedAt.Unix(),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.PageData{
+			Rows:  items,
+			Total: int(total),
+		},
+	})
+}
+
+// writeAudit 记录审计日志，before/after 为变更前后的快照（新建时 before 为 nil，删除时 after 为 nil）
+// 审计日志写入失败只记录错误日志，不影响业务请求
+func writeAudit(c *gin.Context, action, entityType string, entityIDs []int, before, after interface{}) {
+	writeAuditAs(c, c.GetInt("user_id"), c.GetString("username"), action, entityType, entityIDs, before, after)
+}
+
+// writeAuditAs 以指定用户身份记录审计日志（用于登录等尚未通过认证的请求）
+func writeAuditAs(c *gin.Context, userID int, username, action, entityType string, entityIDs []int, before, after interface{}) {
+	diff, err := utils.DiffJSON(before, after)
+	if err != nil {
+		lib.Logger.Error("生成审计变更内容失败: " + err.Error())
+	}
+
+	ids := make([]string, 0, len(entityIDs))
+	for _, id := range entityIDs {
+		ids = append(ids, strconv.Itoa(id))
+	}
+
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+
+	log := &db.AuditLog{
+		UserID:     userID,
+		Username:   username,
+		Action:     action,
+		EntityType: entityType,
+		EntityIDs:  strings.Join(ids, ","),
+		Diff:       diff,
+		IP:         c.ClientIP(),
+		UserAgent:  userAgent,
+	}
+	if err := (&repo.AuditRepo{}).Create(log); err != nil {
+		lib.Logger.Error("写入审计日志失败: " + err.Error())
+	}
+}
+
+// bookmarkAuditSnapshot 书签审计快照（不包含正文与 HTML，仅记录归档内容长度）
+type bookmarkAuditSnapshot struct {
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	Excerpt       string   `json:"excerpt"`
+	Author        string   `json:"author"`
+	IsArchive     bool     `json:"is_archive"`
+	ContentLength int      `json:"content_length"`
+	Tags          []string `json:"tags"`
+}
+
+// newBookmarkAuditSnapshot 生成书签审计快照
+func newBookmarkAuditSnapshot(bookmark *db.Bookmark) *bookmarkAuditSnapshot {
+	tags := make([]string, 0, len(bookmark.Tags))
+	for _, tag := range bookmark.Tags {
+		tags = append(tags, tag.Name)
+	}
+	sort.Strings(tags)
+
+	return &bookmarkAuditSnapshot{
+		URL:           bookmark.URL,
+		Title:         bookmark.Title,
+		Excerpt:       bookmark.Excerpt,
+		Author:        bookmark.Author,
+		IsArchive:     bookmark.IsArchive,
+		ContentLength: len(bookmark.Content),
+		Tags:          tags,
+	}
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"fmt"
+	"math"
 	"net/http"
 	"time"
 
@@ -8,11 +10,17 @@ import (
 	"gorm.io/gorm"
 
 	"bk_kms/lib"
+	"bk_kms/model/db"
 	"bk_kms/model/dto"
 	"bk_kms/repo"
 	"bk_kms/utils"
 )
 
+const (
+	maxTOTPFailures = 5                // 两步验证连续失败次数上限
+	totpLockout     = 15 * time.Minute // 达到上限后的锁定时长
+)
+
 type AuthController struct {
 	userRepo *repo.UserRepo
 }
@@ -41,6 +49,16 @@ func (ac *AuthController) GetCaptcha(c *gin.Context) {
 		CaptchaID: id,
 	}
 
+	// 按需返回语音验证码（需在配置中开启）
+	if lib.GlobalConfig.Captcha.Audio && c.Query("audio") == "true" {
+		audio, err := utils.GenerateCaptchaAudio(id)
+		if err != nil {
+			lib.Logger.Error("生成语音验证码失败: " + err.Error())
+		} else {
+			data.Audio = audio
+		}
+	}
+
 	// 非 release 环境返回验证码实际值（用于自动化测试）
 	ginMode := lib.GlobalConfig.Server.GinMode
 	if ginMode != "release" {
@@ -79,6 +97,7 @@ func (ac *AuthController) Login(c *gin.Context) {
 	user, err := ac.userRepo.FindByUsername(req.Username)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
+			writeAuditAs(c, 0, req.Username, db.AuditActionLoginFailed, db.AuditEntityUser, nil, nil, gin.H{"reason": "user_not_found"})
 			c.JSON(http.StatusOK, dto.Response{
 				Code: 1,
 				Msg:  "用户名或密码错误",
@@ -95,6 +114,7 @@ func (ac *AuthController) Login(c *gin.Context) {
 
 	// 验证密码
 	if !utils.VerifyPassword(req.Pwd, user.Salt, user.Password) {
+		writeAuditAs(c, user.ID, user.Username, db.AuditActionLoginFailed, db.AuditEntityUser, []int{user.ID}, nil, gin.H{"reason": "wrong_password"})
 		c.JSON(http.StatusOK, dto.Response{
 			Code: 1,
 			Msg:  "用户名或密码错误",
@@ -102,13 +122,131 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	// 生成 JWT token
-	expDuration, _ := time.ParseDuration(lib.GlobalConfig.JWT.Exp)
-	if expDuration == 0 {
-		expDuration = 24 * time.Hour
+	// 已开启两步验证：签发预认证 token，等待提交动态码
+	if user.TOTPEnabled {
+		preAuthExp, _ := time.ParseDuration(lib.GlobalConfig.TOTP.PreAuthExp)
+		if preAuthExp == 0 {
+			preAuthExp = 5 * time.Minute
+		}
+
+		preAuthToken, err := utils.GeneratePreAuthToken(user.ID, user.Username, lib.GlobalConfig.JWT.Secret, preAuthExp)
+		if err != nil {
+			lib.Logger.Error("生成预认证token失败: " + err.Error())
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "登录失败",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.LoginResponse{
+			Code: 0,
+			Msg:  "请输入两步验证码",
+			Data: dto.LoginData{
+				ID:           user.ID,
+				Username:     user.Username,
+				NeedTOTP:     true,
+				PreAuthToken: preAuthToken,
+			},
+		})
+		return
+	}
+
+	ac.loginSuccess(c, user, "password")
+}
+
+// LoginTOTP 两步验证登录（登录第二步）
+func (ac *AuthController) LoginTOTP(c *gin.Context) {
+	var req dto.LoginTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	claims, err := utils.ParseToken(req.PreAuthToken, lib.GlobalConfig.JWT.Secret)
+	if err != nil || claims.Purpose != utils.TokenPurposePreAuth {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "登录已过期，请重新登录",
+		})
+		return
 	}
 
-	token, err := utils.GenerateToken(user.ID, user.Username, lib.GlobalConfig.JWT.Secret, expDuration)
+	user, err := ac.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "用户不存在",
+			})
+			return
+		}
+		lib.Logger.Error("查询用户失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "登录失败",
+		})
+		return
+	}
+
+	// 连续失败次数过多时锁定一段时间，锁定之前签发的预认证 token 全部失效
+	if user.TOTPLockedAt != nil {
+		if remaining := time.Until(user.TOTPLockedAt.Add(totpLockout)); remaining > 0 {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  fmt.Sprintf("两步验证失败次数过多，请 %d 分钟后重新登录", int(math.Ceil(remaining.Minutes()))),
+			})
+			return
+		}
+		if claims.IssuedAt == nil || !claims.IssuedAt.After(*user.TOTPLockedAt) {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "登录已过期，请重新登录",
+			})
+			return
+		}
+	}
+
+	ok, err := verifySecondFactor(user, req.Code)
+	if err != nil {
+		lib.Logger.Error("校验两步验证码失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "登录失败",
+		})
+		return
+	}
+	if !ok {
+		writeAuditAs(c, user.ID, user.Username, db.AuditActionLoginFailed, db.AuditEntityUser, []int{user.ID}, nil, gin.H{"reason": "wrong_totp"})
+		locked, err := ac.userRepo.RecordTOTPFailure(user.ID, maxTOTPFailures)
+		if err != nil {
+			lib.Logger.Error("记录两步验证失败次数失败: " + err.Error())
+		}
+		msg := "两步验证码错误"
+		if locked {
+			msg = fmt.Sprintf("两步验证码连续错误 %d 次，请 %d 分钟后重新登录", maxTOTPFailures, int(totpLockout.Minutes()))
+		}
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  msg,
+		})
+		return
+	}
+	if user.TOTPFailures > 0 {
+		if err := ac.userRepo.ResetTOTPFailures(user.ID); err != nil {
+			lib.Logger.Error("清零两步验证失败次数失败: " + err.Error())
+		}
+	}
+
+	ac.loginSuccess(c, user, "totp")
+}
+
+// loginSuccess 签发访问 token 并返回登录成功响应，method 为登录方式（记录到审计日志）
+func (ac *AuthController) loginSuccess(c *gin.Context, user *db.User, method string) {
+	token, err := issueToken(user)
 	if err != nil {
 		lib.Logger.Error("生成token失败: " + err.Error())
 		c.JSON(http.StatusOK, dto.Response{
@@ -119,6 +257,7 @@ func (ac *AuthController) Login(c *gin.Context) {
 	}
 
 	lib.Logger.Info("用户登录成功: " + user.Username)
+	writeAuditAs(c, user.ID, user.Username, db.AuditActionLogin, db.AuditEntityUser, []int{user.ID}, nil, gin.H{"method": method})
 
 	c.JSON(http.StatusOK, dto.LoginResponse{
 		Code: 0,
@@ -130,3 +269,13 @@ func (ac *AuthController) Login(c *gin.Context) {
 		},
 	})
 }
+
+// issueToken 签发访问 token（密码登录与单点登录共用）
+func issueToken(user *db.User) (string, error) {
+	expDuration, _ := time.ParseDuration(lib.GlobalConfig.JWT.Exp)
+	if expDuration == 0 {
+		expDuration = 24 * time.Hour
+	}
+
+	return utils.GenerateToken(user.ID, user.Username, lib.GlobalConfig.JWT.Secret, expDuration)
+}
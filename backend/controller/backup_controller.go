@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bk_kms/backup"
+	"bk_kms/index"
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+)
+
+type BackupController struct{}
+
+func NewBackupController() *BackupController {
+	return &BackupController{}
+}
+
+// Backup 下载全量备份（zip）：各表数据为 JSON Lines，书签归档 HTML 为快照文件，附带版本清单
+func (bc *BackupController) Backup(c *gin.Context) {
+	// 开始写入响应后无法再返回错误信息，出错时只记录日志并中断
+	name := "bk_kms-backup-" + time.Now().Format("20060102150405") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Status(http.StatusOK)
+
+	manifest, err := backup.Write(c.Writer)
+	if err != nil {
+		lib.Logger.Error("生成备份失败: " + err.Error())
+		return
+	}
+	lib.Logger.Info(fmt.Sprintf("生成备份成功: %d 个归档快照", manifest.Snapshots))
+	writeAudit(c, db.AuditActionBackup, db.AuditEntityBackup, nil, nil, gin.H{
+		"schema_version":    manifest.SchemaVersion,
+		"migration_version": manifest.MigrationVersion,
+		"tables":            manifest.Tables,
+		"snapshots":         manifest.Snapshots,
+	})
+}
+
+// Restore 上传备份文件并恢复，mode 为 merge（合并，默认）或 replace（清空后替换）
+func (bc *BackupController) Restore(c *gin.Context) {
+	file, err := c.FormFile("backup_file")
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "文件上传失败: " + err.Error(),
+		})
+		return
+	}
+	if !strings.HasSuffix(strings.ToLower(file.Filename), ".zip") {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "仅支持 .zip 文件",
+		})
+		return
+	}
+	mode := c.DefaultPostForm("mode", backup.ModeMerge)
+
+	fileReader, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "打开文件失败: " + err.Error(),
+		})
+		return
+	}
+	defer fileReader.Close()
+
+	zr, err := zip.NewReader(fileReader, file.Size)
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "备份文件无效: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := backup.Restore(zr, mode)
+	if err != nil {
+		if errors.Is(err, backup.ErrInvalidBackup) {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  err.Error(),
+			})
+			return
+		}
+		lib.Logger.Error("恢复备份失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "恢复失败",
+		})
+		return
+	}
+
+	// 书签数据已整体变化，重新构建索引
+	go func() {
+		if err := index.Build(); err != nil {
+			lib.Logger.Error("书签索引构建失败: " + err.Error())
+		}
+	}()
+
+	writeAudit(c, db.AuditActionRestore, db.AuditEntityBackup, nil, nil, gin.H{
+		"mode":              result.Mode,
+		"schema_version":    result.Manifest.SchemaVersion,
+		"migration_version": result.Manifest.MigrationVersion,
+		"created_at":        result.Manifest.CreatedAt,
+		"tables":            result.Tables,
+	})
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "恢复成功",
+		Data: result,
+	})
+}
@@ -2,14 +2,19 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"bk_kms/index"
 	"bk_kms/lib"
 	"bk_kms/model/db"
 	"bk_kms/model/dto"
@@ -18,12 +23,18 @@ import (
 )
 
 type BookmarkController struct {
-	bookmarkRepo *repo.BookmarkRepo
+	bookmarkRepo   *repo.BookmarkRepo
+	collectionRepo *repo.CollectionRepo
+	stateRepo      *repo.BookmarkStateRepo
+	highlightRepo  *repo.HighlightRepo
 }
 
 func NewBookmarkController() *BookmarkController {
 	return &BookmarkController{
-		bookmarkRepo: &repo.BookmarkRepo{},
+		bookmarkRepo:   &repo.BookmarkRepo{},
+		collectionRepo: &repo.CollectionRepo{},
+		stateRepo:      &repo.BookmarkStateRepo{},
+		highlightRepo:  &repo.HighlightRepo{},
 	}
 }
 
@@ -43,17 +54,13 @@ func (bc *BookmarkController) List(c *gin.Context) {
 		req.PageSize = 10
 	}
 
-	// 解析 tags
-	var tags []string
-	if req.Tags != "" {
-		tags = strings.Split(req.Tags, ",")
-		for i := range tags {
-			tags[i] = strings.TrimSpace(tags[i])
-		}
+	filter, ok := bookmarkListFilter(c, req.Keyword, req.Tags, req.State, req.Favorite)
+	if !ok {
+		return
 	}
 
 	// 查询书签列表
-	bookmarks, total, err := bc.bookmarkRepo.List(req.Keyword, tags, req.Page, req.PageSize)
+	bookmarks, total, err := bc.bookmarkRepo.List(filter, req.Page, req.PageSize)
 	if err != nil {
 		lib.Logger.Error("查询书签列表失败: " + err.Error())
 		c.JSON(http.StatusOK, dto.Response{
@@ -65,27 +72,10 @@ func (bc *BookmarkController) List(c *gin.Context) {
 
 	// 转换为 DTO
 	items := make([]dto.BookmarkListItem, 0, len(bookmarks))
-	for _, bm := range bookmarks {
-		tagItems := make([]dto.TagItem, 0, len(bm.Tags))
-		for _, tag := range bm.Tags {
-			tagItems = append(tagItems, dto.TagItem{
-				ID:   tag.ID,
-				Name: tag.Name,
-			})
-		}
-
-		items = append(items, dto.BookmarkListItem{
-			ID:        bm.ID,
-			URL:       bm.URL,
-			Title:     bm.Title,
-			Excerpt:   bm.Excerpt,
-			Author:    bm.Author,
-			IsArchive: bm.IsArchive,
-			CreatedAt: bm.CreatedAt.Unix(),
-			UpdatedAt: bm.UpdatedAt.Unix(),
-			Tags:      tagItems,
-		})
+	for i := range bookmarks {
+		items = append(items, toBookmarkListItem(&bookmarks[i]))
 	}
+	fillBookmarkStates(bc.stateRepo, c.GetInt("user_id"), items)
 
 	c.JSON(http.StatusOK, dto.BookmarkListResponse{
 		Code: 0,
@@ -179,6 +169,25 @@ func (bc *BookmarkController) Create(c *gin.Context) {
 		Tags:      tags,
 	}
 
+	// 执行自动标签规则，并按需添加推荐标签
+	added := applyTagRules(bookmark)
+	if req.AutoTag {
+		tagger, err := loadAutoTagger()
+		if err != nil {
+			lib.Logger.Error("查询标签失败: " + err.Error())
+		}
+		suggested := tagger.suggest(bookmark.URL, title+"\n"+content, bookmark.Tags)
+		bookmark.Tags = append(bookmark.Tags, suggested...)
+		added = append(added, suggested...)
+	}
+	autoTagItems := make([]dto.TagItem, 0, len(added))
+	for _, tag := range added {
+		autoTagItems = append(autoTagItems, dto.TagItem{
+			ID:   tag.ID,
+			Name: tag.Name,
+		})
+	}
+
 	if err := bc.bookmarkRepo.Create(bookmark); err != nil {
 		lib.Logger.Error("创建书签失败: " + err.Error())
 		c.JSON(http.StatusOK, dto.Response{
@@ -189,10 +198,24 @@ func (bc *BookmarkController) Create(c *gin.Context) {
 	}
 
 	lib.Logger.Info("创建书签成功: " + req.Title)
+	writeAudit(c, db.AuditActionCreate, db.AuditEntityBookmark, []int{bookmark.ID}, nil, newBookmarkAuditSnapshot(bookmark))
+	index.Default().Put(bookmark)
+
+	// 正文与已有书签相似时提示用户
+	similar := bc.findSimilar(bookmark)
+	msg := "创建成功"
+	if len(similar) > 0 {
+		msg = fmt.Sprintf("创建成功，但内容与 %d 个已有书签相似", len(similar))
+	}
 
 	c.JSON(http.StatusOK, dto.Response{
 		Code: 0,
-		Msg:  "创建成功",
+		Msg:  msg,
+		Data: dto.CreateBookmarkData{
+			ID:       bookmark.ID,
+			AutoTags: autoTagItems,
+			Similar:  similar,
+		},
 	})
 }
 
@@ -224,6 +247,7 @@ func (bc *BookmarkController) Update(c *gin.Context) {
 		})
 		return
 	}
+	before := newBookmarkAuditSnapshot(bookmark)
 
 	// 查找或创建标签
 	tagNames := make([]string, 0, len(req.Tags))
@@ -248,6 +272,7 @@ func (bc *BookmarkController) Update(c *gin.Context) {
 	bookmark.IsArchive = req.CreateArchive
 	bookmark.Tags = tags
 	// 如果需要创建归档，则获取网页内容
+	contentChanged := false
 	if req.CreateArchive {
 		lib.Logger.Info("开始获取书签内容: " + req.URL)
 		bookmarkContent, err := utils.FetchBookmarkContent(req.URL, false, false)
@@ -261,10 +286,14 @@ func (bc *BookmarkController) Update(c *gin.Context) {
 			bookmark.Author = bookmarkContent.Author
 			bookmark.Content = bookmarkContent.Content
 			bookmark.HTML = bookmarkContent.HTML
+			contentChanged = true
 			lib.Logger.Info("书签内容获取成功")
 		}
 	}
 
+	// 执行自动标签规则
+	applyTagRules(bookmark)
+
 	if err := bc.bookmarkRepo.Update(bookmark); err != nil {
 		lib.Logger.Error("更新书签失败: " + err.Error())
 		c.JSON(http.StatusOK, dto.Response{
@@ -275,6 +304,11 @@ func (bc *BookmarkController) Update(c *gin.Context) {
 	}
 
 	lib.Logger.Info("更新书签成功: " + req.Title)
+	writeAudit(c, db.AuditActionUpdate, db.AuditEntityBookmark, []int{bookmark.ID}, before, newBookmarkAuditSnapshot(bookmark))
+	index.Default().Put(bookmark)
+	if contentChanged {
+		bc.reanchorHighlights(bookmark)
+	}
 
 	c.JSON(http.StatusOK, dto.Response{
 		Code: 0,
@@ -301,6 +335,23 @@ func (bc *BookmarkController) Delete(c *gin.Context) {
 		return
 	}
 
+	// 记录删除前的快照
+	bookmarks, err := bc.bookmarkRepo.FindByIDs(ids)
+	if err != nil {
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+	before := make(map[string]*bookmarkAuditSnapshot, len(bookmarks))
+	deletedIDs := make([]int, 0, len(bookmarks))
+	for i := range bookmarks {
+		before[strconv.Itoa(bookmarks[i].ID)] = newBookmarkAuditSnapshot(&bookmarks[i])
+		deletedIDs = append(deletedIDs, bookmarks[i].ID)
+	}
+
 	if err := bc.bookmarkRepo.Delete(ids); err != nil {
 		lib.Logger.Error("删除书签失败: " + err.Error())
 		c.JSON(http.StatusOK, dto.Response{
@@ -310,7 +361,9 @@ func (bc *BookmarkController) Delete(c *gin.Context) {
 		return
 	}
 
-	lib.Logger.Info("删除书签成功")
+	lib.Logger.Info(fmt.Sprintf("删除书签成功: %v", deletedIDs))
+	writeAudit(c, db.AuditActionDelete, db.AuditEntityBookmark, deletedIDs, before, nil)
+	index.Default().Remove(deletedIDs...)
 
 	c.JSON(http.StatusOK, dto.Response{
 		Code: 0,
@@ -347,6 +400,24 @@ func (bc *BookmarkController) GetContent(c *gin.Context) {
 		return
 	}
 
+	// 相关书签（索引构建中时不返回）
+	related, err := bc.findRelated(bookmark.ID, 5)
+	if err != nil && err != index.ErrNotReady {
+		lib.Logger.Error("查询相关书签失败: " + err.Error())
+	}
+
+	// 记录打开时间，返回阅读进度以便继续阅读
+	userID := c.GetInt("user_id")
+	if err := bc.stateRepo.MarkOpened(userID, bookmark.ID); err != nil {
+		lib.Logger.Error("记录打开时间失败: " + err.Error())
+	}
+	var reading *dto.BookmarkStateItem
+	if state, err := bc.stateRepo.Find(userID, bookmark.ID); err != nil {
+		lib.Logger.Error("查询阅读状态失败: " + err.Error())
+	} else {
+		reading = toBookmarkStateItem(state)
+	}
+
 	c.JSON(http.StatusOK, dto.BookmarkContentResponse{
 		Code: 0,
 		Msg:  "成功",
@@ -357,10 +428,123 @@ func (bc *BookmarkController) GetContent(c *gin.Context) {
 			HTML:      bookmark.HTML,
 			CreatedAt: bookmark.CreatedAt.Unix(),
 			UpdatedAt: bookmark.UpdatedAt.Unix(),
+			Related:   related,
+			Reading:   reading,
 		},
 	})
 }
 
+// Related 相关书签：综合共同标签、相同域名与正文 TF-IDF 相似度
+func (bc *BookmarkController) Related(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return
+	}
+
+	var req dto.RelatedBookmarkRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	related, err := bc.findRelated(id, req.Limit)
+	if err != nil {
+		switch err {
+		case index.ErrNotReady:
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  err.Error(),
+			})
+		case index.ErrNotFound:
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "书签不存在",
+			})
+		default:
+			lib.Logger.Error("查询相关书签失败: " + err.Error())
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "查询失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: related,
+	})
+}
+
+// findRelated 从索引中查找相关书签并补充书签信息
+func (bc *BookmarkController) findRelated(id int, limit int) ([]dto.RelatedBookmark, error) {
+	results, err := index.Default().Related(id, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return []dto.RelatedBookmark{}, nil
+	}
+
+	ids := make([]int, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	bookmarks, err := bc.bookmarkRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	bookmarkMap := make(map[int]*db.Bookmark, len(bookmarks))
+	for i := range bookmarks {
+		bookmarkMap[bookmarks[i].ID] = &bookmarks[i]
+	}
+
+	items := make([]dto.RelatedBookmark, 0, len(results))
+	for _, result := range results {
+		bm, ok := bookmarkMap[result.ID]
+		if !ok {
+			continue
+		}
+
+		shared := make(map[int]struct{}, len(result.SharedTags))
+		for _, tagID := range result.SharedTags {
+			shared[tagID] = struct{}{}
+		}
+		sharedTags := make([]dto.TagItem, 0, len(result.SharedTags))
+		for _, tag := range bm.Tags {
+			if _, ok := shared[tag.ID]; ok {
+				sharedTags = append(sharedTags, dto.TagItem{
+					ID:   tag.ID,
+					Name: tag.Name,
+				})
+			}
+		}
+
+		items = append(items, dto.RelatedBookmark{
+			BookmarkListItem: toBookmarkListItem(bm),
+			Score:            result.Score,
+			Scores: dto.RelatedScores{
+				Tag:    result.TagScore,
+				Domain: result.DomainScore,
+				Text:   result.TextScore,
+			},
+			SharedTags: sharedTags,
+		})
+	}
+	return items, nil
+}
+
 // Import 批量导入书签（使用 SSE 实时响应）
 func (bc *BookmarkController) Import(c *gin.Context) {
 	// 获取上传的文件
@@ -397,6 +581,20 @@ func (bc *BookmarkController) Import(c *gin.Context) {
 	// 从 form-data 中获取参数
 	generateTag := c.PostForm("generate_tag") == "true"
 	createArchive := c.PostForm("create_archive") == "true"
+	autoTag := c.PostForm("auto_tag") == "true"
+	mapFolders := c.PostForm("map_folders") == "true"
+
+	// 自动标签规则与推荐的候选标签在导入开始时加载一次
+	rules, err := loadTagRules(nil)
+	if err != nil {
+		lib.Logger.Error("查询自动标签规则失败: " + err.Error())
+	}
+	tagger := &autoTagger{}
+	if autoTag {
+		if tagger, err = loadAutoTagger(); err != nil {
+			lib.Logger.Error("查询标签失败: " + err.Error())
+		}
+	}
 	bookmarks, err := utils.ParseNetscapeBookmarkHTML(fileReader, generateTag)
 	if err != nil {
 		c.JSON(http.StatusOK, dto.Response{
@@ -435,7 +633,11 @@ func (bc *BookmarkController) Import(c *gin.Context) {
 		Total:   len(bookmarks),
 	})
 
+	// 文件夹路径 -> 收藏夹ID，避免重复查询
+	collectionIDs := make(map[string]int)
+
 	// 统计信息
+	createdIDs := make([]int, 0, len(bookmarks))
 	successCount := 0
 	skipCount := 0
 	errorCount := 0
@@ -474,6 +676,7 @@ func (bc *BookmarkController) Import(c *gin.Context) {
 			URL:       bm.URL,
 			Title:     bm.Title,
 			Tags:      tags,
+			Folder:    strings.Join(bm.Folders, "/"),
 			IsArchive: createArchive,
 		}
 
@@ -495,6 +698,19 @@ func (bc *BookmarkController) Import(c *gin.Context) {
 			}
 		}
 
+		// 执行自动标签规则，并按需添加推荐标签
+		added, _ := rules.match(bookmark)
+		bookmark.Tags = append(bookmark.Tags, added...)
+		if autoTag {
+			suggested := tagger.suggest(bookmark.URL, bookmark.Title+"\n"+bookmark.Content, bookmark.Tags)
+			bookmark.Tags = append(bookmark.Tags, suggested...)
+			added = append(added, suggested...)
+		}
+		autoTagNames := make([]string, 0, len(added))
+		for _, tag := range added {
+			autoTagNames = append(autoTagNames, tag.Name)
+		}
+
 		if err := bc.bookmarkRepo.Create(bookmark); err != nil {
 			errorCount++
 			sendEvent(dto.ImportProgressEvent{
@@ -508,13 +724,40 @@ func (bc *BookmarkController) Import(c *gin.Context) {
 		}
 
 		successCount++
+		createdIDs = append(createdIDs, bookmark.ID)
+		index.Default().Put(bookmark)
+		message := fmt.Sprintf("成功导入: %s", bm.Title)
+		if len(autoTagNames) > 0 {
+			message += fmt.Sprintf("（自动标签: %s）", strings.Join(autoTagNames, ", "))
+		}
+
+		// 按书签文件夹路径添加到收藏夹，添加失败不影响导入
+		if mapFolders && len(bm.Folders) > 0 {
+			if err := bc.addToFolderCollection(collectionIDs, bm.Folders, bookmark.ID); err != nil {
+				lib.Logger.Error("添加书签到收藏夹失败: " + err.Error())
+			} else {
+				message += fmt.Sprintf("（收藏夹: %s）", bookmark.Folder)
+			}
+		}
 		sendEvent(dto.ImportProgressEvent{
 			Type:    "success",
-			Message: fmt.Sprintf("成功导入: %s", bm.Title),
+			Message: message,
 			Current: i + 1,
 			Total:   len(bookmarks),
 			URL:     bm.URL,
 		})
+
+		// 正文与已有书签相似时发送提示
+		if similar := bc.findSimilar(bookmark); len(similar) > 0 {
+			sendEvent(dto.ImportProgressEvent{
+				Type:    "warning",
+				Message: fmt.Sprintf("内容与已有书签相似: %s - %s", bm.URL, similar[0].URL),
+				Current: i + 1,
+				Total:   len(bookmarks),
+				URL:     bm.URL,
+				Similar: similar,
+			})
+		}
 	}
 
 	// 发送完成事件
@@ -526,6 +769,605 @@ func (bc *BookmarkController) Import(c *gin.Context) {
 	})
 
 	lib.Logger.Info(fmt.Sprintf("书签导入完成: 成功=%d, 跳过=%d, 失败=%d", successCount, skipCount, errorCount))
+	writeAudit(c, db.AuditActionImport, db.AuditEntityBookmark, createdIDs, nil, gin.H{
+		"file":           file.Filename,
+		"generate_tag":   generateTag,
+		"create_archive": createArchive,
+		"auto_tag":       autoTag,
+		"map_folders":    mapFolders,
+		"success":        successCount,
+		"skip":           skipCount,
+		"error":          errorCount,
+	})
+}
+
+// 原生格式导入时 URL 已存在的处理方式
+const (
+	importConflictSkip      = "skip"       // 跳过
+	importConflictOverwrite = "overwrite"  // 用记录中出现的字段覆盖
+	importConflictMergeTags = "merge_tags" // 只追加标签
+)
+
+// 单条记录的导入结果
+const (
+	importCreated = "created"
+	importUpdated = "updated"
+	importSkipped = "skipped"
+)
+
+// ImportRecords 导入原生格式（JSON Lines / CSV）的书签，按 URL 判断是否已存在，
+// 已存在时按 conflict 处理：skip（默认）、overwrite、merge_tags。id、canonical_url、simhash 由系统生成，导入时忽略
+func (bc *BookmarkController) ImportRecords(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "文件上传失败: " + err.Error(),
+		})
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(file.Filename)) {
+		case ".jsonl", ".ndjson":
+			format = utils.BookmarkRecordJSONL
+		case ".csv":
+			format = utils.BookmarkRecordCSV
+		}
+	}
+	if format != utils.BookmarkRecordJSONL && format != utils.BookmarkRecordCSV {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "仅支持 .jsonl 与 .csv 文件",
+		})
+		return
+	}
+	conflict := c.DefaultPostForm("conflict", importConflictSkip)
+	if conflict != importConflictSkip && conflict != importConflictOverwrite && conflict != importConflictMergeTags {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "不支持的冲突处理方式: " + conflict,
+		})
+		return
+	}
+
+	fileReader, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "打开文件失败: " + err.Error(),
+		})
+		return
+	}
+	defer fileReader.Close()
+
+	records, recordErrs, err := utils.ParseBookmarkRecords(fileReader, format)
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "解析文件失败: " + err.Error(),
+		})
+		return
+	}
+
+	result := dto.BookmarkRecordImportResult{Errors: make([]dto.RecordImportError, 0, len(recordErrs))}
+	for _, recordErr := range recordErrs {
+		result.Failed++
+		result.Errors = append(result.Errors, dto.RecordImportError{Line: recordErr.Line, URL: recordErr.URL, Message: recordErr.Message})
+	}
+	var createdIDs, updatedIDs []int
+	for i := range records {
+		record := &records[i]
+		bookmark, status, err := bc.importRecord(record, conflict)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, dto.RecordImportError{Line: record.Line, URL: record.URL, Message: err.Error()})
+			continue
+		}
+		switch status {
+		case importCreated:
+			result.Created++
+			createdIDs = append(createdIDs, bookmark.ID)
+		case importUpdated:
+			result.Updated++
+			updatedIDs = append(updatedIDs, bookmark.ID)
+		default:
+			result.Skipped++
+		}
+	}
+	sort.Slice(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Line < result.Errors[j].Line
+	})
+
+	lib.Logger.Info(fmt.Sprintf("原生格式书签导入完成: 新建=%d, 更新=%d, 跳过=%d, 失败=%d",
+		result.Created, result.Updated, result.Skipped, result.Failed))
+	writeAudit(c, db.AuditActionImport, db.AuditEntityBookmark, append(createdIDs, updatedIDs...), nil, gin.H{
+		"file":     file.Filename,
+		"format":   format,
+		"conflict": conflict,
+		"created":  createdIDs,
+		"updated":  updatedIDs,
+	})
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "导入完成",
+		Data: result,
+	})
+}
+
+// importRecord 导入一条记录，返回的错误信息直接展示给用户
+func (bc *BookmarkController) importRecord(record *utils.BookmarkRecord, conflict string) (*db.Bookmark, string, error) {
+	if u, err := url.Parse(record.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, "", errors.New("无效的 URL")
+	}
+
+	existing, err := bc.bookmarkRepo.FindByURL(record.URL)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		return nil, "", errors.New("查询书签失败")
+	}
+	if err == nil && conflict == importConflictSkip {
+		return existing, importSkipped, nil
+	}
+	tags, err := bc.bookmarkRepo.FindOrCreateTags(record.Tags)
+	if err != nil {
+		lib.Logger.Error("处理标签失败: " + err.Error())
+		return nil, "", errors.New("处理标签失败")
+	}
+
+	// 新建书签，保留记录中的创建与更新时间
+	if existing == nil {
+		bookmark := &db.Bookmark{
+			URL:       record.URL,
+			Title:     utils.ValidateTitle(record.Title, record.URL),
+			Excerpt:   record.Excerpt,
+			Author:    record.Author,
+			Content:   record.Content,
+			HTML:      record.HTML,
+			Folder:    record.Folder,
+			IsArchive: record.IsArchive,
+			Tags:      tags,
+			CreatedAt: record.CreatedAt,
+			UpdatedAt: record.UpdatedAt,
+		}
+		if err := bc.bookmarkRepo.Create(bookmark); err != nil {
+			lib.Logger.Error("创建书签失败: " + err.Error())
+			return nil, "", errors.New("创建书签失败")
+		}
+		index.Default().Put(bookmark)
+		return bookmark, importCreated, nil
+	}
+
+	bookmark, err := bc.bookmarkRepo.FindByID(existing.ID)
+	if err != nil {
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		return nil, "", errors.New("查询书签失败")
+	}
+
+	// 只追加标签
+	if conflict == importConflictMergeTags {
+		added := make([]db.Tag, 0, len(tags))
+		for _, tag := range tags {
+			if !containsTag(bookmark.Tags, tag.ID) {
+				added = append(added, tag)
+			}
+		}
+		if len(added) == 0 {
+			return bookmark, importSkipped, nil
+		}
+		if err := bc.bookmarkRepo.AddTags(bookmark, added); err != nil {
+			lib.Logger.Error("更新书签失败: " + err.Error())
+			return nil, "", errors.New("更新书签失败")
+		}
+		index.Default().Put(bookmark)
+		return bookmark, importUpdated, nil
+	}
+
+	// 覆盖记录中出现的字段
+	contentChanged := record.Has("content") && record.Content != bookmark.Content
+	if record.Has("title") {
+		bookmark.Title = utils.ValidateTitle(record.Title, bookmark.URL)
+	}
+	if record.Has("excerpt") {
+		bookmark.Excerpt = record.Excerpt
+	}
+	if record.Has("author") {
+		bookmark.Author = record.Author
+	}
+	if record.Has("content") {
+		bookmark.Content = record.Content
+	}
+	if record.Has("html") {
+		bookmark.HTML = record.HTML
+	}
+	if record.Has("folder") {
+		bookmark.Folder = record.Folder
+	}
+	if record.Has("is_archive") {
+		bookmark.IsArchive = record.IsArchive
+	}
+	if record.Has("tags") {
+		bookmark.Tags = tags
+	}
+	if record.Has("created_at") && !record.CreatedAt.IsZero() {
+		bookmark.CreatedAt = record.CreatedAt
+	}
+	if err := bc.bookmarkRepo.Replace(bookmark); err != nil {
+		lib.Logger.Error("更新书签失败: " + err.Error())
+		return nil, "", errors.New("更新书签失败")
+	}
+	index.Default().Put(bookmark)
+	if contentChanged {
+		bc.reanchorHighlights(bookmark)
+	}
+	return bookmark, importUpdated, nil
+}
+
+// containsTag 标签列表中是否包含指定ID的标签
+func containsTag(tags []db.Tag, id int) bool {
+	for _, tag := range tags {
+		if tag.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// addToFolderCollection 将导入的书签添加到与其文件夹路径对应的收藏夹，收藏夹不存在时逐级创建
+func (bc *BookmarkController) addToFolderCollection(collectionIDs map[string]int, folders []string, bookmarkID int) error {
+	path := strings.Join(folders, "\x00") // 文件夹名称中可能包含 /
+	collectionID, ok := collectionIDs[path]
+	if !ok {
+		collection, err := bc.collectionRepo.FindOrCreatePath(folders)
+		if err != nil {
+			return err
+		}
+		collectionID = collection.ID
+		collectionIDs[path] = collectionID
+	}
+	_, err := bc.collectionRepo.AddBookmarks(collectionID, []int{bookmarkID})
+	return err
+}
+
+// Duplicates 重复书签报告（规范化 URL 相同的书签）
+func (bc *BookmarkController) Duplicates(c *gin.Context) {
+	groups, err := bc.bookmarkRepo.FindDuplicates()
+	if err != nil {
+		lib.Logger.Error("查询重复书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.DuplicateGroup, 0, len(groups))
+	for _, group := range groups {
+		bookmarks := make([]dto.BookmarkListItem, 0, len(group))
+		for i := range group {
+			bookmarks = append(bookmarks, toBookmarkListItem(&group[i]))
+		}
+		items = append(items, dto.DuplicateGroup{
+			CanonicalURL: group[0].CanonicalURL,
+			Bookmarks:    bookmarks,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// Merge 合并重复书签：合并标签，保留最完整的归档，其余书签移入回收站
+func (bc *BookmarkController) Merge(c *gin.Context) {
+	var req dto.MergeBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	bookmarks, err := bc.bookmarkRepo.FindByIDs(req.IDs)
+	if err != nil {
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "合并失败",
+		})
+		return
+	}
+	if len(bookmarks) < 2 {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "请至少选择两个书签",
+		})
+		return
+	}
+	// 合并会把其余书签移入回收站，网址不同的书签需要显式确认
+	if !req.Force {
+		for i := range bookmarks {
+			if bookmarks[i].CanonicalURL != bookmarks[0].CanonicalURL {
+				c.JSON(http.StatusOK, dto.Response{
+					Code: 1,
+					Msg:  "书签的网址不同，确认合并请设置 force",
+				})
+				return
+			}
+		}
+	}
+
+	// 确定保留的书签：指定的书签，默认最早创建的书签
+	keep := &bookmarks[0]
+	for i := range bookmarks {
+		if req.KeepID != 0 {
+			if bookmarks[i].ID == req.KeepID {
+				keep = &bookmarks[i]
+			}
+		} else if bookmarks[i].CreatedAt.Before(keep.CreatedAt) {
+			keep = &bookmarks[i]
+		}
+	}
+	if req.KeepID != 0 && keep.ID != req.KeepID {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "保留的书签不在合并列表中",
+		})
+		return
+	}
+
+	before := make(map[string]*bookmarkAuditSnapshot, len(bookmarks))
+	for i := range bookmarks {
+		before[strconv.Itoa(bookmarks[i].ID)] = newBookmarkAuditSnapshot(&bookmarks[i])
+	}
+
+	// 合并标签，并找出内容最完整的归档
+	tagSeen := make(map[int]struct{})
+	var tags []db.Tag
+	var best *db.Bookmark
+	var removeIDs []int
+	for i := range bookmarks {
+		bm := &bookmarks[i]
+		for _, tag := range bm.Tags {
+			if _, ok := tagSeen[tag.ID]; !ok {
+				tagSeen[tag.ID] = struct{}{}
+				tags = append(tags, tag)
+			}
+		}
+		if bm.IsArchive && bm.Content != "" && (best == nil || len(bm.Content) > len(best.Content)) {
+			best = bm
+		}
+		if bm.ID != keep.ID {
+			removeIDs = append(removeIDs, bm.ID)
+		}
+	}
+	keep.Tags = tags
+
+	if best != nil && best.ID != keep.ID && (!keep.IsArchive || len(keep.Content) < len(best.Content)) {
+		keep.IsArchive = true
+		keep.Content = best.Content
+		keep.HTML = best.HTML
+		keep.Author = best.Author
+		if keep.Excerpt == "" {
+			keep.Excerpt = best.Excerpt
+		}
+		if keep.Title == "" || keep.Title == keep.URL {
+			keep.Title = best.Title
+		}
+	}
+
+	if err := bc.bookmarkRepo.Merge(keep, removeIDs); err != nil {
+		lib.Logger.Error("合并书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "合并失败",
+		})
+		return
+	}
+
+	lib.Logger.Info(fmt.Sprintf("合并书签成功: 保留 %d, 合并 %v", keep.ID, removeIDs))
+	writeAudit(c, db.AuditActionMerge, db.AuditEntityBookmark, append([]int{keep.ID}, removeIDs...), before,
+		map[string]*bookmarkAuditSnapshot{strconv.Itoa(keep.ID): newBookmarkAuditSnapshot(keep)})
+	index.Default().Put(keep)
+	index.Default().Remove(removeIDs...)
+	bc.reanchorHighlights(keep)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "合并成功",
+		Data: dto.MergeBookmarkData{
+			KeepID:    keep.ID,
+			MergedIDs: removeIDs,
+		},
+	})
+}
+
+// NearDuplicates 相似内容报告：按正文指纹聚类，相似度达到阈值的书签归为一组
+func (bc *BookmarkController) NearDuplicates(c *gin.Context) {
+	var req dto.NearDuplicateRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.Threshold <= 0 {
+		req.Threshold = lib.GlobalConfig.Similar.Threshold
+	}
+
+	groups, err := bc.bookmarkRepo.FindNearDuplicates(utils.SimHashMaxDistance(req.Threshold))
+	if err != nil {
+		lib.Logger.Error("查询相似书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.NearDuplicateGroup, 0, len(groups))
+	for _, group := range groups {
+		bookmarks := make([]dto.NearDuplicateItem, 0, len(group))
+		for i := range group {
+			bookmarks = append(bookmarks, dto.NearDuplicateItem{
+				BookmarkListItem: toBookmarkListItem(&group[i]),
+				Similarity:       utils.SimHashSimilarity(group[0].SimHash, group[i].SimHash),
+			})
+		}
+		items = append(items, dto.NearDuplicateGroup{
+			Bookmarks: bookmarks,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// RefreshSimHashes 重新计算全部书签的正文指纹（旧数据迁移时使用）
+func (bc *BookmarkController) RefreshSimHashes(c *gin.Context) {
+	updated, empty, err := bc.bookmarkRepo.RefreshSimHashes()
+	if err != nil {
+		lib.Logger.Error("更新正文指纹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	lib.Logger.Info(fmt.Sprintf("更新正文指纹成功: %d, 无正文: %d", updated, empty))
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: dto.RefreshSimHashData{
+			Updated: updated,
+			Empty:   empty,
+		},
+	})
+}
+
+// findSimilar 查找正文与指定书签相似的已有书签，查询失败只记录日志
+func (bc *BookmarkController) findSimilar(bookmark *db.Bookmark) []dto.SimilarBookmark {
+	if bookmark.SimHash == 0 {
+		return nil
+	}
+
+	threshold := lib.GlobalConfig.Similar.Threshold
+	bookmarks, err := bc.bookmarkRepo.FindSimilar(bookmark.SimHash, utils.SimHashMaxDistance(threshold), bookmark.ID, 5)
+	if err != nil {
+		lib.Logger.Error("查询相似书签失败: " + err.Error())
+		return nil
+	}
+
+	similar := make([]dto.SimilarBookmark, 0, len(bookmarks))
+	for _, bm := range bookmarks {
+		similar = append(similar, dto.SimilarBookmark{
+			ID:         bm.ID,
+			URL:        bm.URL,
+			Title:      bm.Title,
+			Similarity: utils.SimHashSimilarity(bookmark.SimHash, bm.SimHash),
+		})
+	}
+	return similar
+}
+
+// RefreshCanonicalURLs 重新计算全部书签的规范化 URL（修改追踪参数规则后使用）
+func (bc *BookmarkController) RefreshCanonicalURLs(c *gin.Context) {
+	updated, err := bc.bookmarkRepo.RefreshCanonicalURLs()
+	if err != nil {
+		lib.Logger.Error("更新规范化URL失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	lib.Logger.Info(fmt.Sprintf("更新规范化URL成功: %d", updated))
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: dto.RefreshCanonicalURLData{
+			Updated: updated,
+		},
+	})
+}
+
+// splitTags 解析英文逗号分隔的标签列表
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// bookmarkListFilter 根据书签列表的查询参数构造查询条件，参数错误时返回错误响应
+func bookmarkListFilter(c *gin.Context, keyword, tags, state string, favorite *bool) (repo.BookmarkFilter, bool) {
+	states, ok := parseBookmarkStates(state)
+	if !ok {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: 不支持的阅读状态",
+		})
+		return repo.BookmarkFilter{}, false
+	}
+	return repo.BookmarkFilter{
+		Keyword:  keyword,
+		Tags:     splitTags(tags),
+		UserID:   c.GetInt("user_id"),
+		States:   states,
+		Favorite: favorite,
+	}, true
+}
+
+// reanchorHighlights 书签正文变化后重新定位书签中的高亮，失败只记录日志
+func (bc *BookmarkController) reanchorHighlights(bookmark *db.Bookmark) {
+	orphaned, err := bc.highlightRepo.Reanchor(bookmark.ID, bookmark.Content)
+	if err != nil {
+		lib.Logger.Error("重新定位高亮失败: " + err.Error())
+		return
+	}
+	if orphaned > 0 {
+		lib.Logger.Info(fmt.Sprintf("书签 %d 有 %d 个高亮在新的归档内容中找不到原文", bookmark.ID, orphaned))
+	}
+}
+
+// toBookmarkListItem 将书签转换为列表项 DTO
+func toBookmarkListItem(bm *db.Bookmark) dto.BookmarkListItem {
+	tagItems := make([]dto.TagItem, 0, len(bm.Tags))
+	for _, tag := range bm.Tags {
+		tagItems = append(tagItems, dto.TagItem{
+			ID:   tag.ID,
+			Name: tag.Name,
+		})
+	}
+
+	return dto.BookmarkListItem{
+		ID:        bm.ID,
+		URL:       bm.URL,
+		Title:     bm.Title,
+		Excerpt:   bm.Excerpt,
+		Author:    bm.Author,
+		IsArchive: bm.IsArchive,
+		CreatedAt: bm.CreatedAt.Unix(),
+		UpdatedAt: bm.UpdatedAt.Unix(),
+		Tags:      tagItems,
+	}
 }
 
 // toJSON 将对象转换为 JSON 字符串
@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+type BookmarkNoteController struct {
+	noteRepo     *repo.BookmarkNoteRepo
+	bookmarkRepo *repo.BookmarkRepo
+}
+
+func NewBookmarkNoteController() *BookmarkNoteController {
+	return &BookmarkNoteController{
+		noteRepo:     &repo.BookmarkNoteRepo{},
+		bookmarkRepo: &repo.BookmarkRepo{},
+	}
+}
+
+// List 当前用户在书签中的笔记，render=true 时同时返回渲染后的 HTML
+func (nc *BookmarkNoteController) List(c *gin.Context) {
+	bookmarkID, ok := nc.findBookmarkID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.BookmarkNoteListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	notes, err := nc.noteRepo.ListByBookmark(c.GetInt("user_id"), bookmarkID)
+	if err != nil {
+		lib.Logger.Error("查询笔记失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.BookmarkNoteItem, 0, len(notes))
+	for i := range notes {
+		items = append(items, toBookmarkNoteItem(&notes[i], req.Render))
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// Create 为书签添加笔记
+func (nc *BookmarkNoteController) Create(c *gin.Context) {
+	bookmarkID, ok := nc.findBookmarkID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveBookmarkNoteRequest
+	if !bindBookmarkNoteRequest(c, &req) {
+		return
+	}
+
+	note := &db.BookmarkNote{
+		UserID:     c.GetInt("user_id"),
+		BookmarkID: bookmarkID,
+		Content:    req.Content,
+	}
+	if err := nc.noteRepo.Create(note); err != nil {
+		lib.Logger.Error("创建笔记失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "创建成功",
+		Data: toBookmarkNoteItem(note, true),
+	})
+}
+
+// Update 编辑笔记
+func (nc *BookmarkNoteController) Update(c *gin.Context) {
+	note, ok := nc.findOwned(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveBookmarkNoteRequest
+	if !bindBookmarkNoteRequest(c, &req) {
+		return
+	}
+
+	note.Content = req.Content
+	if err := nc.noteRepo.Update(note); err != nil {
+		lib.Logger.Error("更新笔记失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: toBookmarkNoteItem(note, true),
+	})
+}
+
+// Delete 删除笔记
+func (nc *BookmarkNoteController) Delete(c *gin.Context) {
+	note, ok := nc.findOwned(c)
+	if !ok {
+		return
+	}
+
+	if err := nc.noteRepo.Delete(note.ID); err != nil {
+		lib.Logger.Error("删除笔记失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "删除成功",
+	})
+}
+
+// Preview 将 Markdown 渲染为过滤后的 HTML，用于编辑时预览
+func (nc *BookmarkNoteController) Preview(c *gin.Context) {
+	var req dto.PreviewNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: gin.H{"html": utils.RenderMarkdown(req.Content)},
+	})
+}
+
+// findBookmarkID 根据路径参数查找书签，返回书签ID
+func (nc *BookmarkNoteController) findBookmarkID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return 0, false
+	}
+
+	if _, err := nc.bookmarkRepo.FindByID(id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "书签不存在",
+			})
+			return 0, false
+		}
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// findOwned 根据路径参数查找当前用户的笔记
+func (nc *BookmarkNoteController) findOwned(c *gin.Context) (*db.BookmarkNote, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return nil, false
+	}
+
+	note, err := nc.noteRepo.FindByID(id)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		lib.Logger.Error("查询笔记失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return nil, false
+	}
+	if err == gorm.ErrRecordNotFound || note.UserID != c.GetInt("user_id") {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "笔记不存在",
+		})
+		return nil, false
+	}
+	return note, true
+}
+
+// bindBookmarkNoteRequest 绑定并校验创建、编辑请求
+func bindBookmarkNoteRequest(c *gin.Context, req *dto.SaveBookmarkNoteRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return false
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "笔记内容不能为空",
+		})
+		return false
+	}
+	return true
+}
+
+// toBookmarkNoteItem 将笔记转换为 DTO，render 为 true 时附带渲染后的 HTML
+func toBookmarkNoteItem(note *db.BookmarkNote, render bool) dto.BookmarkNoteItem {
+	item := dto.BookmarkNoteItem{
+		ID:         note.ID,
+		BookmarkID: note.BookmarkID,
+		Content:    note.Content,
+		CreatedAt:  note.CreatedAt.Unix(),
+		UpdatedAt:  note.UpdatedAt.Unix(),
+	}
+	if render {
+		item.HTML = utils.RenderMarkdown(note.Content)
+	}
+	return item
+}
@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+)
+
+type BookmarkStateController struct {
+	stateRepo    *repo.BookmarkStateRepo
+	bookmarkRepo *repo.BookmarkRepo
+}
+
+func NewBookmarkStateController() *BookmarkStateController {
+	return &BookmarkStateController{
+		stateRepo:    &repo.BookmarkStateRepo{},
+		bookmarkRepo: &repo.BookmarkRepo{},
+	}
+}
+
+// Update 修改当前用户对书签的阅读状态、收藏与阅读进度
+func (sc *BookmarkStateController) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return
+	}
+
+	var req dto.UpdateBookmarkStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if _, err := sc.bookmarkRepo.FindByID(id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "书签不存在",
+			})
+			return
+		}
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	state, err := sc.stateRepo.Find(userID, id)
+	if err != nil {
+		lib.Logger.Error("查询阅读状态失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	// 只修改进度时根据进度推断阅读状态
+	if req.State == nil && req.Progress != nil {
+		next := ""
+		if *req.Progress >= 100 {
+			next = db.BookmarkStateRead
+		} else if *req.Progress > 0 && state.State == db.BookmarkStateUnread {
+			next = db.BookmarkStateReading
+		}
+		if next != "" {
+			req.State = &next
+		}
+	}
+
+	update := repo.BookmarkStateUpdate{State: req.State, Favorite: req.Favorite, Progress: req.Progress}
+	if err := sc.stateRepo.Update(userID, []int{id}, update); err != nil {
+		lib.Logger.Error("更新阅读状态失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	state, err = sc.stateRepo.Find(userID, id)
+	if err != nil {
+		lib.Logger.Error("查询阅读状态失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: toBookmarkStateItem(state),
+	})
+}
+
+// BulkUpdate 批量修改当前用户对书签的阅读状态与收藏，不存在的书签跳过
+func (sc *BookmarkStateController) BulkUpdate(c *gin.Context) {
+	var req dto.BulkBookmarkStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.State == nil && req.Favorite == nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "请设置阅读状态或收藏",
+		})
+		return
+	}
+
+	bookmarks, err := sc.bookmarkRepo.FindByIDs(req.IDs)
+	if err != nil {
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+	ids := make([]int, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		ids = append(ids, bookmark.ID)
+	}
+
+	update := repo.BookmarkStateUpdate{State: req.State, Favorite: req.Favorite}
+	if err := sc.stateRepo.Update(c.GetInt("user_id"), ids, update); err != nil {
+		lib.Logger.Error("更新阅读状态失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: ids,
+	})
+}
+
+// fillBookmarkStates 为书签列表附加当前用户的阅读状态，查询失败时不附加
+func fillBookmarkStates(stateRepo *repo.BookmarkStateRepo, userID int, items []dto.BookmarkListItem) {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	states, err := stateRepo.FindByBookmarkIDs(userID, ids)
+	if err != nil {
+		lib.Logger.Error("查询阅读状态失败: " + err.Error())
+		return
+	}
+	for i := range items {
+		state := states[items[i].ID]
+		items[i].Reading = toBookmarkStateItem(&state)
+	}
+}
+
+// toBookmarkStateItem 将阅读状态转换为 DTO
+func toBookmarkStateItem(state *db.BookmarkState) *dto.BookmarkStateItem {
+	item := &dto.BookmarkStateItem{
+		State:    state.State,
+		Favorite: state.Favorite,
+		Progress: state.Progress,
+	}
+	if state.LastOpenedAt != nil {
+		item.LastOpenedAt = state.LastOpenedAt.Unix()
+	}
+	return item
+}
+
+// parseBookmarkStates 解析逗号分隔的阅读状态过滤条件
+func parseBookmarkStates(raw string) ([]string, bool) {
+	states := splitTags(raw)
+	for _, state := range states {
+		valid := false
+		for _, s := range db.BookmarkStates {
+			if state == s {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, false
+		}
+	}
+	return states, true
+}
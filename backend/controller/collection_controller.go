@@ -0,0 +1,583 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+)
+
+type CollectionController struct {
+	collectionRepo *repo.CollectionRepo
+	bookmarkRepo   *repo.BookmarkRepo
+	stateRepo      *repo.BookmarkStateRepo
+}
+
+func NewCollectionController() *CollectionController {
+	return &CollectionController{
+		collectionRepo: &repo.CollectionRepo{},
+		bookmarkRepo:   &repo.BookmarkRepo{},
+		stateRepo:      &repo.BookmarkStateRepo{},
+	}
+}
+
+// List 收藏夹树
+func (cc *CollectionController) List(c *gin.Context) {
+	collections, err := cc.collectionRepo.List()
+	if err != nil {
+		lib.Logger.Error("查询收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	counts, err := cc.collectionRepo.CountBookmarks()
+	if err != nil {
+		lib.Logger.Error("统计收藏夹书签数量失败: " + err.Error())
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: buildCollectionTree(collections, counts, 0),
+	})
+}
+
+// Create 创建收藏夹
+func (cc *CollectionController) Create(c *gin.Context) {
+	var req dto.SaveCollectionRequest
+	if !bindCollectionRequest(c, &req) {
+		return
+	}
+	if req.ParentID > 0 && !cc.checkParent(c, 0, req.ParentID) {
+		return
+	}
+	if !cc.checkName(c, 0, req.ParentID, req.Name) {
+		return
+	}
+
+	collection := &db.Collection{
+		ParentID: req.ParentID,
+		Name:     req.Name,
+	}
+	if err := cc.collectionRepo.Create(collection); err != nil {
+		lib.Logger.Error("创建收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("创建收藏夹成功: " + collection.Name)
+	item := toCollectionItem(collection, 0)
+	writeAudit(c, db.AuditActionCreate, db.AuditEntityCollection, []int{collection.ID}, nil, item)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "创建成功",
+		Data: item,
+	})
+}
+
+// Update 重命名或移动收藏夹，移动到其他上级收藏夹时排在最后
+func (cc *CollectionController) Update(c *gin.Context) {
+	collection, ok := cc.find(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveCollectionRequest
+	if !bindCollectionRequest(c, &req) {
+		return
+	}
+	if req.ParentID > 0 && !cc.checkParent(c, collection.ID, req.ParentID) {
+		return
+	}
+	if !cc.checkName(c, collection.ID, req.ParentID, req.Name) {
+		return
+	}
+
+	before := toCollectionItem(collection, 0)
+	if req.ParentID != collection.ParentID {
+		position, err := cc.collectionRepo.NextPosition(req.ParentID)
+		if err != nil {
+			lib.Logger.Error("查询收藏夹排序失败: " + err.Error())
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "更新失败",
+			})
+			return
+		}
+		collection.ParentID = req.ParentID
+		collection.Position = position
+	}
+	collection.Name = req.Name
+
+	if err := cc.collectionRepo.Update(collection); err != nil {
+		lib.Logger.Error("更新收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("更新收藏夹成功: " + collection.Name)
+	item := toCollectionItem(collection, 0)
+	writeAudit(c, db.AuditActionUpdate, db.AuditEntityCollection, []int{collection.ID}, before, item)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: item,
+	})
+}
+
+// Delete 删除收藏夹及其全部下级收藏夹，其中的书签不会被删除
+func (cc *CollectionController) Delete(c *gin.Context) {
+	collection, ok := cc.find(c)
+	if !ok {
+		return
+	}
+
+	collections, err := cc.collectionRepo.List()
+	if err != nil {
+		lib.Logger.Error("查询收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+	ids := append([]int{collection.ID}, collectionDescendants(collections, collection.ID)...)
+
+	if err := cc.collectionRepo.Delete(ids); err != nil {
+		lib.Logger.Error("删除收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("删除收藏夹成功: " + collection.Name)
+	writeAudit(c, db.AuditActionDelete, db.AuditEntityCollection, ids, toCollectionItem(collection, 0), nil)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "删除成功",
+	})
+}
+
+// Reorder 调整同级收藏夹的顺序
+func (cc *CollectionController) Reorder(c *gin.Context) {
+	var req dto.ReorderCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	collections, err := cc.collectionRepo.List()
+	if err != nil {
+		lib.Logger.Error("查询收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "排序失败",
+		})
+		return
+	}
+	siblings := make([]int, 0)
+	for _, collection := range collections {
+		if collection.ParentID == req.ParentID {
+			siblings = append(siblings, collection.ID)
+		}
+	}
+
+	if err := cc.collectionRepo.Reorder(req.ParentID, reorderIDs(siblings, req.IDs)); err != nil {
+		lib.Logger.Error("收藏夹排序失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "排序失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "排序成功",
+	})
+}
+
+// Bookmarks 按手动排序查询收藏夹中的书签
+func (cc *CollectionController) Bookmarks(c *gin.Context) {
+	collection, ok := cc.find(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CollectionBookmarksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 设置默认分页大小
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	bookmarks, total, err := cc.collectionRepo.ListBookmarks(collection.ID, req.Page, req.PageSize)
+	if err != nil {
+		lib.Logger.Error("查询收藏夹书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.BookmarkListItem, 0, len(bookmarks))
+	for i := range bookmarks {
+		items = append(items, toBookmarkListItem(&bookmarks[i]))
+	}
+	fillBookmarkStates(cc.stateRepo, c.GetInt("user_id"), items)
+
+	c.JSON(http.StatusOK, dto.BookmarkListResponse{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.PageData{
+			Rows:  items,
+			Total: int(total),
+		},
+	})
+}
+
+// AddBookmarks 将书签添加到收藏夹末尾
+func (cc *CollectionController) AddBookmarks(c *gin.Context) {
+	collection, ok := cc.find(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CollectionBookmarkIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 只添加存在（且不在回收站中）的书签，保持请求中的顺序
+	bookmarks, err := cc.bookmarkRepo.FindByIDs(req.BookmarkIDs)
+	if err != nil {
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "添加失败",
+		})
+		return
+	}
+	exists := make(map[int]struct{}, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		exists[bookmark.ID] = struct{}{}
+	}
+	ids := make([]int, 0, len(bookmarks))
+	for _, id := range req.BookmarkIDs {
+		if _, ok := exists[id]; ok {
+			ids = append(ids, id)
+			delete(exists, id)
+		}
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "书签不存在",
+		})
+		return
+	}
+
+	added, err := cc.collectionRepo.AddBookmarks(collection.ID, ids)
+	if err != nil {
+		lib.Logger.Error("添加书签到收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "添加失败",
+		})
+		return
+	}
+
+	writeAudit(c, db.AuditActionUpdate, db.AuditEntityCollection, []int{collection.ID}, nil, gin.H{
+		"add_bookmarks": ids,
+	})
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "添加成功",
+		Data: gin.H{"added": added},
+	})
+}
+
+// RemoveBookmarks 从收藏夹中移除书签
+func (cc *CollectionController) RemoveBookmarks(c *gin.Context) {
+	collection, ok := cc.find(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CollectionBookmarkIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := cc.collectionRepo.RemoveBookmarks(collection.ID, req.BookmarkIDs); err != nil {
+		lib.Logger.Error("从收藏夹移除书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "移除失败",
+		})
+		return
+	}
+
+	writeAudit(c, db.AuditActionUpdate, db.AuditEntityCollection, []int{collection.ID}, gin.H{
+		"remove_bookmarks": req.BookmarkIDs,
+	}, nil)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "移除成功",
+	})
+}
+
+// ReorderBookmarks 调整收藏夹中书签的顺序
+func (cc *CollectionController) ReorderBookmarks(c *gin.Context) {
+	collection, ok := cc.find(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CollectionBookmarkIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	current, err := cc.collectionRepo.BookmarkIDs(collection.ID)
+	if err == nil {
+		err = cc.collectionRepo.ReorderBookmarks(collection.ID, reorderIDs(current, req.BookmarkIDs))
+	}
+	if err != nil {
+		lib.Logger.Error("收藏夹书签排序失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "排序失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "排序成功",
+	})
+}
+
+// find 根据路径参数查找收藏夹
+func (cc *CollectionController) find(c *gin.Context) (*db.Collection, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return nil, false
+	}
+
+	collection, err := cc.collectionRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "收藏夹不存在",
+			})
+			return nil, false
+		}
+		lib.Logger.Error("查询收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return nil, false
+	}
+	return collection, true
+}
+
+// checkParent 校验上级收藏夹存在，且不是收藏夹自身或其下级收藏夹（避免形成环）
+func (cc *CollectionController) checkParent(c *gin.Context, id, parentID int) bool {
+	collections, err := cc.collectionRepo.List()
+	if err != nil {
+		lib.Logger.Error("查询收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return false
+	}
+
+	found := false
+	for _, collection := range collections {
+		if collection.ID == parentID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "上级收藏夹不存在",
+		})
+		return false
+	}
+
+	if id > 0 {
+		invalid := parentID == id
+		for _, descendant := range collectionDescendants(collections, id) {
+			invalid = invalid || descendant == parentID
+		}
+		if invalid {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "不能移动到自身或下级收藏夹中",
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// checkName 校验同级收藏夹中没有同名收藏夹
+func (cc *CollectionController) checkName(c *gin.Context, id, parentID int, name string) bool {
+	existing, err := cc.collectionRepo.FindByName(parentID, name)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		lib.Logger.Error("查询收藏夹失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return false
+	}
+	if err == nil && existing.ID != id {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "同级已存在同名收藏夹",
+		})
+		return false
+	}
+	return true
+}
+
+// bindCollectionRequest 绑定并校验创建、编辑请求
+func bindCollectionRequest(c *gin.Context, req *dto.SaveCollectionRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "收藏夹名称不能为空",
+		})
+		return false
+	}
+	return true
+}
+
+// toCollectionItem 将收藏夹转换为 DTO（不含下级收藏夹）
+func toCollectionItem(collection *db.Collection, count int) dto.CollectionItem {
+	return dto.CollectionItem{
+		ID:        collection.ID,
+		ParentID:  collection.ParentID,
+		Name:      collection.Name,
+		Position:  collection.Position,
+		Count:     count,
+		CreatedAt: collection.CreatedAt.Unix(),
+		UpdatedAt: collection.UpdatedAt.Unix(),
+		Children:  []dto.CollectionItem{},
+	}
+}
+
+// buildCollectionTree 构建指定收藏夹下的收藏夹树，collections 需已按排序排列
+func buildCollectionTree(collections []db.Collection, counts map[int]int, parentID int) []dto.CollectionItem {
+	items := make([]dto.CollectionItem, 0)
+	for i := range collections {
+		if collections[i].ParentID != parentID {
+			continue
+		}
+		item := toCollectionItem(&collections[i], counts[collections[i].ID])
+		item.Children = buildCollectionTree(collections, counts, collections[i].ID)
+		items = append(items, item)
+	}
+	return items
+}
+
+// collectionDescendants 指定收藏夹的全部下级收藏夹ID
+func collectionDescendants(collections []db.Collection, id int) []int {
+	var ids []int
+	for _, collection := range collections {
+		if collection.ParentID == id {
+			ids = append(ids, collection.ID)
+			ids = append(ids, collectionDescendants(collections, collection.ID)...)
+		}
+	}
+	return ids
+}
+
+// reorderIDs 按请求的顺序重新排列：请求中列出的ID排在前面，其余ID保持原有顺序排在后面，
+// 请求中不属于 current 的ID被忽略
+func reorderIDs(current, requested []int) []int {
+	members := make(map[int]struct{}, len(current))
+	for _, id := range current {
+		members[id] = struct{}{}
+	}
+
+	ordered := make([]int, 0, len(current))
+	for _, id := range requested {
+		if _, ok := members[id]; ok {
+			ordered = append(ordered, id)
+			delete(members, id)
+		}
+	}
+	for _, id := range current {
+		if _, ok := members[id]; ok {
+			ordered = append(ordered, id)
+		}
+	}
+	return ordered
+}
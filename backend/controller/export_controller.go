@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+// exportBatchSize 导出时每批查询的书签数量
+const exportBatchSize = 100
+
+type ExportController struct {
+	bookmarkRepo  *repo.BookmarkRepo
+	highlightRepo *repo.HighlightRepo
+	noteRepo      *repo.BookmarkNoteRepo
+}
+
+func NewExportController() *ExportController {
+	return &ExportController{
+		bookmarkRepo:  &repo.BookmarkRepo{},
+		highlightRepo: &repo.HighlightRepo{},
+		noteRepo:      &repo.BookmarkNoteRepo{},
+	}
+}
+
+// Markdown 将书签导出为 Markdown 文件夹（zip）：每个书签一个文件，包含 YAML front matter、
+// 转换为 Markdown 的归档内容以及当前用户的高亮与笔记，过滤条件与书签列表相同
+func (ec *ExportController) Markdown(c *gin.Context) {
+	ids, ok := ec.exportIDs(c)
+	if !ok {
+		return
+	}
+
+	// 开始写入响应后无法再返回错误信息，出错时只记录日志并中断
+	folder := "bk_kms-" + time.Now().Format("20060102")
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+folder+`.zip"`)
+	c.Status(http.StatusOK)
+
+	userID := c.GetInt("user_id")
+	zw := zip.NewWriter(c.Writer)
+	names := make(map[string]bool, len(ids))
+	err := ec.eachBatch(ids, func(batchIDs []int, bookmarks []*db.Bookmark) error {
+		return ec.writeMarkdownBatch(zw, folder, userID, batchIDs, bookmarks, names)
+	})
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		lib.Logger.Error("导出 Markdown 失败: " + err.Error())
+		return
+	}
+	lib.Logger.Info(fmt.Sprintf("导出 Markdown 成功: %d 个书签", len(ids)))
+}
+
+// JSONL 将书签导出为 JSON Lines：每行一个书签，包含书签的全部字段与标签，可通过原生格式导入还原
+func (ec *ExportController) JSONL(c *gin.Context) {
+	ids, ok := ec.exportIDs(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="bk_kms-`+time.Now().Format("20060102")+`.jsonl"`)
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+	enc.SetEscapeHTML(false)
+	err := ec.eachBatch(ids, func(_ []int, bookmarks []*db.Bookmark) error {
+		for _, bookmark := range bookmarks {
+			if err := enc.Encode(toBookmarkRecord(bookmark)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		lib.Logger.Error("导出 JSON Lines 失败: " + err.Error())
+		return
+	}
+	lib.Logger.Info(fmt.Sprintf("导出 JSON Lines 成功: %d 个书签", len(ids)))
+}
+
+// CSV 将书签导出为 CSV：字段与 JSON Lines 相同，标签使用英文逗号分隔，带 UTF-8 BOM 便于表格软件识别编码
+func (ec *ExportController) CSV(c *gin.Context) {
+	ids, ok := ec.exportIDs(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="bk_kms-`+time.Now().Format("20060102")+`.csv"`)
+	c.Status(http.StatusOK)
+
+	c.Writer.WriteString("\xef\xbb\xbf")
+	cw := csv.NewWriter(c.Writer)
+	err := cw.Write(utils.BookmarkRecordColumns)
+	if err == nil {
+		err = ec.eachBatch(ids, func(_ []int, bookmarks []*db.Bookmark) error {
+			for _, bookmark := range bookmarks {
+				record := toBookmarkRecord(bookmark)
+				if err := cw.Write(record.CSVRow()); err != nil {
+					return err
+				}
+			}
+			cw.Flush()
+			return cw.Error()
+		})
+	}
+	if err != nil {
+		lib.Logger.Error("导出 CSV 失败: " + err.Error())
+		return
+	}
+	lib.Logger.Info(fmt.Sprintf("导出 CSV 成功: %d 个书签", len(ids)))
+}
+
+// exportIDs 按书签列表的过滤条件查询要导出的书签ID，失败时写入错误响应
+func (ec *ExportController) exportIDs(c *gin.Context) ([]int, bool) {
+	var req dto.BookmarkExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return nil, false
+	}
+	filter, ok := bookmarkListFilter(c, req.Keyword, req.Tags, req.State, req.Favorite)
+	if !ok {
+		return nil, false
+	}
+
+	ids, err := ec.bookmarkRepo.ListIDs(filter)
+	if err != nil {
+		lib.Logger.Error("查询书签列表失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "导出失败",
+		})
+		return nil, false
+	}
+	return ids, true
+}
+
+// eachBatch 按 exportBatchSize 分批查询书签，每批按 ids 的顺序回调
+func (ec *ExportController) eachBatch(ids []int, fn func(batchIDs []int, bookmarks []*db.Bookmark) error) error {
+	for start := 0; start < len(ids); start += exportBatchSize {
+		end := start + exportBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batchIDs := ids[start:end]
+		found, err := ec.bookmarkRepo.FindByIDs(batchIDs)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[int]*db.Bookmark, len(found))
+		for i := range found {
+			byID[found[i].ID] = &found[i]
+		}
+		bookmarks := make([]*db.Bookmark, 0, len(found))
+		for _, id := range batchIDs {
+			if bookmark, ok := byID[id]; ok {
+				bookmarks = append(bookmarks, bookmark)
+			}
+		}
+		if err := fn(batchIDs, bookmarks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdownBatch 查询一批书签的高亮、笔记，写入 zip
+func (ec *ExportController) writeMarkdownBatch(zw *zip.Writer, folder string, userID int, ids []int, bookmarks []*db.Bookmark, names map[string]bool) error {
+	highlights, err := ec.highlightRepo.ListByBookmarks(userID, ids)
+	if err != nil {
+		return err
+	}
+	notes, err := ec.noteRepo.ListByBookmarks(userID, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, bookmark := range bookmarks {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     folder + "/" + markdownFileName(bookmark, names),
+			Method:   zip.Deflate,
+			Modified: bookmark.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		doc := toMarkdownDocument(bookmark, highlights[bookmark.ID], notes[bookmark.ID])
+		if _, err := w.Write([]byte(utils.RenderMarkdownDocument(doc))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownFileName 书签的 Markdown 文件名：使用标题，标题为空或重名时附加书签ID
+// 重名不区分大小写，避免在 macOS、Windows 等大小写不敏感的文件系统中解压时互相覆盖
+func markdownFileName(bookmark *db.Bookmark, names map[string]bool) string {
+	name := utils.SafeFileName(bookmark.Title, 100)
+	if name == "" {
+		name = "bookmark"
+	}
+	if names[strings.ToLower(name)] {
+		name += " (" + strconv.Itoa(bookmark.ID) + ")"
+	}
+	names[strings.ToLower(name)] = true
+	return name + ".md"
+}
+
+// toMarkdownDocument 将书签及其高亮、笔记转换为 Markdown 文件内容
+func toMarkdownDocument(bookmark *db.Bookmark, highlights []db.Highlight, notes []db.BookmarkNote) utils.MarkdownDocument {
+	tags := make([]string, 0, len(bookmark.Tags))
+	for _, tag := range bookmark.Tags {
+		tags = append(tags, tag.Name)
+	}
+	body := bookmark.Excerpt
+	if bookmark.IsArchive && bookmark.HTML != "" {
+		body = utils.HTMLToMarkdown(bookmark.HTML)
+	}
+	noteContents := make([]string, 0, len(notes))
+	for _, note := range notes {
+		noteContents = append(noteContents, note.Content)
+	}
+
+	return utils.MarkdownDocument{
+		URL:        bookmark.URL,
+		Title:      bookmark.Title,
+		Tags:       tags,
+		Author:     bookmark.Author,
+		CreatedAt:  bookmark.CreatedAt,
+		UpdatedAt:  bookmark.UpdatedAt,
+		Body:       body,
+		Highlights: toMarkdownHighlights(highlights),
+		Notes:      noteContents,
+	}
+}
+
+// toBookmarkRecord 将书签转换为原生导出格式
+func toBookmarkRecord(bookmark *db.Bookmark) utils.BookmarkRecord {
+	tags := make([]string, 0, len(bookmark.Tags))
+	for _, tag := range bookmark.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return utils.BookmarkRecord{
+		ID:           bookmark.ID,
+		URL:          bookmark.URL,
+		CanonicalURL: bookmark.CanonicalURL,
+		Title:        bookmark.Title,
+		Excerpt:      bookmark.Excerpt,
+		Author:       bookmark.Author,
+		Content:      bookmark.Content,
+		HTML:         bookmark.HTML,
+		Folder:       bookmark.Folder,
+		SimHash:      bookmark.SimHash,
+		IsArchive:    bookmark.IsArchive,
+		Tags:         tags,
+		CreatedAt:    bookmark.CreatedAt,
+		UpdatedAt:    bookmark.UpdatedAt,
+	}
+}
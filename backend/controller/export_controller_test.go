@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"testing"
+
+	"bk_kms/model/db"
+)
+
+func TestMarkdownFileName(t *testing.T) {
+	bookmarks := []struct {
+		bookmark db.Bookmark
+		want     string
+	}{
+		{db.Bookmark{ID: 1, Title: "Go 语言"}, "Go 语言.md"},
+		{db.Bookmark{ID: 2, Title: "Go 语言"}, "Go 语言 (2).md"},
+		{db.Bookmark{ID: 3, Title: "go 语言"}, "go 语言 (3).md"},
+		{db.Bookmark{ID: 4, Title: "GO 语言 (2)"}, "GO 语言 (2) (4).md"},
+		{db.Bookmark{ID: 5, Title: ""}, "bookmark.md"},
+		{db.Bookmark{ID: 6, Title: "Bookmark"}, "Bookmark (6).md"},
+	}
+	names := make(map[string]bool)
+	for _, tt := range bookmarks {
+		if got := markdownFileName(&tt.bookmark, names); got != tt.want {
+			t.Errorf("markdownFileName(%d %q) = %q, want %q", tt.bookmark.ID, tt.bookmark.Title, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+const feedItemLimit = 50 // 订阅源条目数
+
+type FeedController struct {
+	userRepo     *repo.UserRepo
+	bookmarkRepo *repo.BookmarkRepo
+}
+
+func NewFeedController() *FeedController {
+	return &FeedController{
+		userRepo:     &repo.UserRepo{},
+		bookmarkRepo: &repo.BookmarkRepo{},
+	}
+}
+
+// Token 当前用户的书签订阅地址，首次访问时生成令牌
+func (fc *FeedController) Token(c *gin.Context) {
+	user, err := fc.userRepo.FindByID(c.GetInt("user_id"))
+	if err != nil {
+		lib.Logger.Error("查询用户失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "用户不存在",
+		})
+		return
+	}
+
+	token := user.FeedToken
+	if token == "" {
+		if token, err = fc.generateToken(user.ID); err != nil {
+			lib.Logger.Error("生成订阅令牌失败: " + err.Error())
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "生成失败",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: feedTokenData(c, token),
+	})
+}
+
+// RegenerateToken 重新生成书签订阅地址，旧的订阅地址失效
+func (fc *FeedController) RegenerateToken(c *gin.Context) {
+	token, err := fc.generateToken(c.GetInt("user_id"))
+	if err != nil {
+		lib.Logger.Error("生成订阅令牌失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "生成失败",
+		})
+		return
+	}
+
+	writeAudit(c, db.AuditActionUpdate, db.AuditEntityUser, []int{c.GetInt("user_id")}, nil, gin.H{
+		"feed_token": "regenerated",
+	})
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "生成成功",
+		Data: feedTokenData(c, token),
+	})
+}
+
+// Bookmarks 最新书签的订阅源（RSS 2.0 / Atom / JSON Feed），支持按关键字与标签过滤，
+// 通过 URL 中的令牌访问，无需登录
+func (fc *FeedController) Bookmarks(c *gin.Context) {
+	if _, err := fc.userRepo.FindByFeedToken(c.Param("token")); err != nil {
+		if err != gorm.ErrRecordNotFound {
+			lib.Logger.Error("查询用户失败: " + err.Error())
+		}
+		c.String(http.StatusNotFound, "feed not found")
+		return
+	}
+
+	var req dto.BookmarkFeedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.String(http.StatusBadRequest, "invalid parameters")
+		return
+	}
+
+	filter := repo.BookmarkFilter{Keyword: req.Keyword, Tags: splitTags(req.Tags)}
+	bookmarks, _, err := fc.bookmarkRepo.List(filter, 1, feedItemLimit)
+	if err != nil {
+		lib.Logger.Error("查询书签列表失败: " + err.Error())
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	var conditions []string
+	if req.Keyword != "" {
+		conditions = append(conditions, "关键字: "+req.Keyword)
+	}
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, "标签: "+strings.Join(filter.Tags, ","))
+	}
+	title := "最新书签"
+	if len(conditions) > 0 {
+		title += " - " + strings.Join(conditions, "; ")
+	}
+	renderBookmarkFeed(c, title, strings.Join(conditions, "; "), bookmarks)
+}
+
+// generateToken 生成并保存新的订阅令牌
+func (fc *FeedController) generateToken(userID int) (string, error) {
+	token, err := utils.RandomString(24)
+	if err != nil {
+		return "", err
+	}
+	return token, fc.userRepo.UpdateFeedToken(userID, token)
+}
+
+// feedTokenData 各格式的书签订阅地址
+func feedTokenData(c *gin.Context, token string) dto.FeedTokenData {
+	base := requestBaseURL(c) + "/feeds/bookmarks/" + token
+	return dto.FeedTokenData{
+		RSS:  base,
+		Atom: base + "?format=" + utils.FeedFormatAtom,
+		JSON: base + "?format=" + utils.FeedFormatJSON,
+	}
+}
+
+// renderBookmarkFeed 按请求参数 format 输出书签订阅源
+func renderBookmarkFeed(c *gin.Context, title, description string, bookmarks []db.Bookmark) {
+	baseURL := requestBaseURL(c)
+	feed := &utils.Feed{
+		Title:       title,
+		Link:        baseURL,
+		FeedURL:     baseURL + c.Request.URL.RequestURI(),
+		Description: description,
+		Updated:     time.Now(),
+	}
+	for i := range bookmarks {
+		feed.Items = append(feed.Items, bookmarkFeedItem(&bookmarks[i]))
+	}
+	if len(bookmarks) > 0 {
+		feed.Updated = bookmarks[0].CreatedAt
+	}
+
+	format := c.DefaultQuery("format", utils.FeedFormatRSS)
+	data, err := utils.RenderFeed(feed, format)
+	if err != nil {
+		lib.Logger.Error("生成订阅源失败: " + err.Error())
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+	c.Data(http.StatusOK, utils.FeedContentType(format), data)
+}
+
+// bookmarkFeedItem 将书签转换为订阅源条目：摘要为摘录，已归档时附带过滤后的归档内容
+func bookmarkFeedItem(bookmark *db.Bookmark) utils.FeedItem {
+	tags := make([]string, 0, len(bookmark.Tags))
+	for _, tag := range bookmark.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	item := utils.FeedItem{
+		ID:        fmt.Sprintf("urn:bk-kms:bookmark:%d", bookmark.ID),
+		Title:     bookmark.Title,
+		Link:      bookmark.URL,
+		Summary:   bookmark.Excerpt,
+		Author:    bookmark.Author,
+		Tags:      tags,
+		Published: bookmark.CreatedAt,
+		Updated:   bookmark.UpdatedAt,
+	}
+	if bookmark.IsArchive && bookmark.HTML != "" {
+		item.Content = utils.SanitizeHTML(bookmark.HTML)
+	}
+	return item
+}
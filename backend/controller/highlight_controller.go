@@ -0,0 +1,352 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+type HighlightController struct {
+	highlightRepo *repo.HighlightRepo
+	bookmarkRepo  *repo.BookmarkRepo
+}
+
+func NewHighlightController() *HighlightController {
+	return &HighlightController{
+		highlightRepo: &repo.HighlightRepo{},
+		bookmarkRepo:  &repo.BookmarkRepo{},
+	}
+}
+
+// List 当前用户在书签中的高亮，format=markdown 时导出为 Markdown
+func (hc *HighlightController) List(c *gin.Context) {
+	bookmark, ok := hc.findBookmark(c)
+	if !ok {
+		return
+	}
+
+	highlights, err := hc.highlightRepo.ListByBookmark(c.GetInt("user_id"), bookmark.ID)
+	if err != nil {
+		lib.Logger.Error("查询高亮失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	if c.Query("format") == "markdown" {
+		markdown := ""
+		if len(highlights) > 0 {
+			markdown = utils.RenderHighlightsMarkdown(bookmark.Title, bookmark.URL, toMarkdownHighlights(highlights))
+		}
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(markdown))
+		return
+	}
+
+	items := make([]dto.HighlightItem, 0, len(highlights))
+	for i := range highlights {
+		items = append(items, toHighlightItem(&highlights[i]))
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// Create 在书签的归档内容中创建高亮，选中的文本必须出现在归档正文中
+func (hc *HighlightController) Create(c *gin.Context) {
+	bookmark, ok := hc.findBookmark(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateHighlightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if !bookmark.IsArchive || bookmark.Content == "" {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "书签未归档，无法添加高亮",
+		})
+		return
+	}
+	anchor, found := utils.AnchorText(bookmark.Content, req.Quote, req.Prefix, req.Suffix, req.StartOffset)
+	if !found {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "选中的文本不在归档内容中",
+		})
+		return
+	}
+
+	if req.Color == "" {
+		req.Color = "yellow"
+	}
+	highlight := &db.Highlight{
+		UserID:      c.GetInt("user_id"),
+		BookmarkID:  bookmark.ID,
+		Quote:       req.Quote,
+		Prefix:      req.Prefix,
+		Suffix:      req.Suffix,
+		StartOffset: anchor.StartOffset,
+		EndOffset:   anchor.EndOffset,
+		Note:        strings.TrimSpace(req.Note),
+		Color:       req.Color,
+	}
+	if err := hc.highlightRepo.Create(highlight); err != nil {
+		lib.Logger.Error("创建高亮失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "创建成功",
+		Data: toHighlightItem(highlight),
+	})
+}
+
+// Update 修改高亮的批注与颜色
+func (hc *HighlightController) Update(c *gin.Context) {
+	highlight, ok := hc.findOwned(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateHighlightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	highlight.Note = strings.TrimSpace(req.Note)
+	if req.Color != "" {
+		highlight.Color = req.Color
+	}
+	if err := hc.highlightRepo.Update(highlight); err != nil {
+		lib.Logger.Error("更新高亮失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: toHighlightItem(highlight),
+	})
+}
+
+// Delete 删除高亮
+func (hc *HighlightController) Delete(c *gin.Context) {
+	highlight, ok := hc.findOwned(c)
+	if !ok {
+		return
+	}
+
+	if err := hc.highlightRepo.Delete(highlight.ID); err != nil {
+		lib.Logger.Error("删除高亮失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "删除成功",
+	})
+}
+
+// Search 搜索当前用户的全部高亮（选中的文本与批注）
+func (hc *HighlightController) Search(c *gin.Context) {
+	var req dto.HighlightSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 设置默认分页大小
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	highlights, total, err := hc.highlightRepo.Search(c.GetInt("user_id"), strings.TrimSpace(req.Keyword), req.Page, req.PageSize)
+	if err != nil {
+		lib.Logger.Error("查询高亮失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.HighlightItem, 0, len(highlights))
+	for i := range highlights {
+		items = append(items, toHighlightItem(&highlights[i]))
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.PageData{
+			Rows:  items,
+			Total: int(total),
+		},
+	})
+}
+
+// Export 将当前用户的全部高亮导出为 Markdown 文件，按书签分组
+func (hc *HighlightController) Export(c *gin.Context) {
+	highlights, err := hc.highlightRepo.ListAll(c.GetInt("user_id"))
+	if err != nil {
+		lib.Logger.Error("查询高亮失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "导出失败",
+		})
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Highlights\n\n")
+	for start := 0; start < len(highlights); {
+		end := start
+		for end < len(highlights) && highlights[end].BookmarkID == highlights[start].BookmarkID {
+			end++
+		}
+		if bookmark := highlights[start].Bookmark; bookmark != nil {
+			sb.WriteString(utils.RenderHighlightsMarkdown(bookmark.Title, bookmark.URL, toMarkdownHighlights(highlights[start:end])))
+		}
+		start = end
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="highlights.md"`)
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(sb.String()))
+}
+
+// findBookmark 根据路径参数查找书签
+func (hc *HighlightController) findBookmark(c *gin.Context) (*db.Bookmark, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return nil, false
+	}
+
+	bookmark, err := hc.bookmarkRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "书签不存在",
+			})
+			return nil, false
+		}
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return nil, false
+	}
+	return bookmark, true
+}
+
+// findOwned 根据路径参数查找当前用户的高亮
+func (hc *HighlightController) findOwned(c *gin.Context) (*db.Highlight, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return nil, false
+	}
+
+	highlight, err := hc.highlightRepo.FindByID(id)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		lib.Logger.Error("查询高亮失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return nil, false
+	}
+	if err == gorm.ErrRecordNotFound || highlight.UserID != c.GetInt("user_id") {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "高亮不存在",
+		})
+		return nil, false
+	}
+	return highlight, true
+}
+
+// toHighlightItem 将高亮转换为 DTO
+func toHighlightItem(highlight *db.Highlight) dto.HighlightItem {
+	item := dto.HighlightItem{
+		ID:          highlight.ID,
+		BookmarkID:  highlight.BookmarkID,
+		Quote:       highlight.Quote,
+		Prefix:      highlight.Prefix,
+		Suffix:      highlight.Suffix,
+		StartOffset: highlight.StartOffset,
+		EndOffset:   highlight.EndOffset,
+		Orphaned:    highlight.Orphaned,
+		Note:        highlight.Note,
+		Color:       highlight.Color,
+		CreatedAt:   highlight.CreatedAt.Unix(),
+		UpdatedAt:   highlight.UpdatedAt.Unix(),
+	}
+	if highlight.Bookmark != nil {
+		item.BookmarkURL = highlight.Bookmark.URL
+		item.BookmarkTitle = highlight.Bookmark.Title
+	}
+	return item
+}
+
+// toMarkdownHighlights 转换为 Markdown 导出使用的高亮
+func toMarkdownHighlights(highlights []db.Highlight) []utils.MarkdownHighlight {
+	items := make([]utils.MarkdownHighlight, 0, len(highlights))
+	for _, highlight := range highlights {
+		items = append(items, utils.MarkdownHighlight{
+			Quote:     highlight.Quote,
+			Note:      highlight.Note,
+			CreatedAt: highlight.CreatedAt,
+		})
+	}
+	return items
+}
@@ -0,0 +1,451 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+const (
+	oidcStateCookie = "bk_kms_oidc_state"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+	oidcStateExp    = 10 * time.Minute
+)
+
+// 关联用户时可以直接展示给用户的错误，其他错误（数据库等）只记录日志
+var (
+	errOIDCIdentityTaken = errors.New("该身份已绑定其他用户")
+	errOIDCNoUsername    = errors.New("身份信息中缺少用户名")
+	errOIDCUserExists    = errors.New("本地用户已存在，请使用密码登录后绑定该身份")
+	errOIDCUserDisabled  = errors.New("用户未开通，请联系管理员")
+)
+
+type OIDCController struct {
+	userRepo     *repo.UserRepo
+	identityRepo *repo.UserIdentityRepo
+
+	mu       sync.Mutex
+	provider *utils.OIDCProvider
+}
+
+func NewOIDCController() *OIDCController {
+	return &OIDCController{
+		userRepo:     &repo.UserRepo{},
+		identityRepo: &repo.UserIdentityRepo{},
+	}
+}
+
+// Login 跳转到身份提供方登录
+func (oc *OIDCController) Login(c *gin.Context) {
+	authURL, err := oc.startFlow(c, 0)
+	if err != nil {
+		oc.fail(c, err.Error())
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Link 为当前登录用户绑定第三方身份，返回身份提供方登录地址
+func (oc *OIDCController) Link(c *gin.Context) {
+	authURL, err := oc.startFlow(c, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.OIDCLinkData{
+			AuthURL: authURL,
+		},
+	})
+}
+
+// Callback 身份提供方回调：校验 state、换取 token、校验 id_token、关联本地用户并签发 JWT
+func (oc *OIDCController) Callback(c *gin.Context) {
+	cfg := lib.GlobalConfig.OIDC
+	if !cfg.Enabled {
+		oc.fail(c, "未开启单点登录")
+		return
+	}
+
+	if errMsg := c.Query("error"); errMsg != "" {
+		oc.fail(c, "身份提供方登录失败: "+errMsg)
+		return
+	}
+
+	// 校验 state，state cookie 只能使用一次
+	cookie, err := c.Cookie(oidcStateCookie)
+	oc.clearStateCookie(c)
+	if err != nil {
+		oc.fail(c, "登录已过期，请重新登录")
+		return
+	}
+	state, err := utils.ParseOIDCStateToken(cookie, lib.GlobalConfig.JWT.Secret)
+	if err != nil || state.State != c.Query("state") {
+		oc.fail(c, "登录状态校验失败，请重新登录")
+		return
+	}
+
+	provider, err := oc.getProvider()
+	if err != nil {
+		lib.Logger.Error("获取 OIDC 配置失败: " + err.Error())
+		oc.fail(c, "单点登录服务不可用")
+		return
+	}
+
+	token, err := provider.Exchange(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, c.Query("code"), state.CodeVerifier)
+	if err != nil {
+		lib.Logger.Error("OIDC 换取 token 失败: " + err.Error())
+		oc.fail(c, "单点登录失败")
+		return
+	}
+
+	claims, err := provider.VerifyIDToken(token.IDToken, cfg.ClientID, state.Nonce)
+	if err != nil {
+		lib.Logger.Error("OIDC id_token 校验失败: " + err.Error())
+		oc.fail(c, "单点登录失败")
+		return
+	}
+
+	// id_token 中缺少用户名或角色 claim 时，从 userinfo 端点补充
+	if _, ok := claims[oc.usernameClaim()]; !ok || (cfg.RoleClaim != "" && claims[cfg.RoleClaim] == nil) {
+		info, err := provider.UserInfo(token.AccessToken)
+		if err != nil {
+			lib.Logger.Warn("获取 OIDC 用户信息失败: " + err.Error())
+		}
+		for k, v := range info {
+			if _, exists := claims[k]; !exists && k != "sub" {
+				claims[k] = v
+			}
+		}
+	}
+
+	user, err := oc.resolveUser(provider.Issuer, claims, state.LinkUserID)
+	if err != nil {
+		lib.Logger.Error("OIDC 关联用户失败: " + err.Error())
+		switch {
+		case errors.Is(err, errOIDCIdentityTaken), errors.Is(err, errOIDCNoUsername),
+			errors.Is(err, errOIDCUserExists), errors.Is(err, errOIDCUserDisabled):
+			oc.fail(c, err.Error())
+		default:
+			oc.fail(c, "登录失败")
+		}
+		return
+	}
+
+	jwtToken, err := issueToken(user)
+	if err != nil {
+		lib.Logger.Error("生成token失败: " + err.Error())
+		oc.fail(c, "登录失败")
+		return
+	}
+
+	lib.Logger.Info("用户单点登录成功: " + user.Username)
+	writeAuditAs(c, user.ID, user.Username, db.AuditActionLogin, db.AuditEntityUser, []int{user.ID}, nil, gin.H{"method": "oidc", "issuer": provider.Issuer})
+
+	if cfg.FrontendRedirect != "" {
+		c.Redirect(http.StatusFound, cfg.FrontendRedirect+"#token="+url.QueryEscape(jwtToken))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.LoginResponse{
+		Code: 0,
+		Msg:  "登录成功",
+		Data: dto.LoginData{
+			ID:       user.ID,
+			Username: user.Username,
+			Token:    jwtToken,
+		},
+	})
+}
+
+// Identities 查询当前用户绑定的第三方身份
+func (oc *OIDCController) Identities(c *gin.Context) {
+	identities, err := oc.identityRepo.ListByUserID(c.GetInt("user_id"))
+	if err != nil {
+		lib.Logger.Error("查询第三方身份失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.UserIdentityItem, 0, len(identities))
+	for _, identity := range identities {
+		items = append(items, dto.UserIdentityItem{
+			ID:        identity.ID,
+			Issuer:    identity.Issuer,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt.Unix(),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// Unlink 解除第三方身份绑定
+func (oc *OIDCController) Unlink(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return
+	}
+
+	deleted, err := oc.identityRepo.Delete(id, c.GetInt("user_id"))
+	if err != nil {
+		lib.Logger.Error("解除绑定失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "解除绑定失败",
+		})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "绑定不存在",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "解除绑定成功",
+	})
+}
+
+// startFlow 生成 state、nonce、PKCE verifier，写入签名 cookie 并返回登录地址
+// 返回的错误信息可以直接展示给用户，内部错误在此记录日志
+func (oc *OIDCController) startFlow(c *gin.Context, linkUserID int) (string, error) {
+	cfg := lib.GlobalConfig.OIDC
+	if !cfg.Enabled {
+		return "", errors.New("未开启单点登录")
+	}
+
+	provider, err := oc.getProvider()
+	if err != nil {
+		lib.Logger.Error("获取 OIDC 配置失败: " + err.Error())
+		return "", errors.New("单点登录服务不可用")
+	}
+
+	state := utils.OIDCState{LinkUserID: linkUserID}
+	for _, field := range []*string{&state.State, &state.Nonce, &state.CodeVerifier} {
+		if *field, err = utils.RandomString(32); err != nil {
+			lib.Logger.Error("生成 OIDC 登录状态失败: " + err.Error())
+			return "", errors.New("生成登录状态失败")
+		}
+	}
+
+	stateToken, err := utils.GenerateOIDCStateToken(state, lib.GlobalConfig.JWT.Secret, oidcStateExp)
+	if err != nil {
+		lib.Logger.Error("生成 OIDC 登录状态失败: " + err.Error())
+		return "", errors.New("生成登录状态失败")
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, stateToken, int(oidcStateExp.Seconds()), oidcCookiePath, "", oc.secureCookie(), true)
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	return provider.AuthCodeURL(cfg.ClientID, cfg.RedirectURL, scopes, state.State, state.Nonce, state.CodeVerifier), nil
+}
+
+// resolveUser 根据 id_token 找到（或绑定、创建）本地用户，并同步角色
+func (oc *OIDCController) resolveUser(issuer string, claims map[string]interface{}, linkUserID int) (*db.User, error) {
+	cfg := lib.GlobalConfig.OIDC
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+
+	var user *db.User
+	identity, err := oc.identityRepo.FindByIssuerSubject(issuer, subject)
+	switch {
+	case err == nil:
+		if linkUserID != 0 && identity.UserID != linkUserID {
+			return nil, errOIDCIdentityTaken
+		}
+		if user, err = oc.userRepo.FindByID(identity.UserID); err != nil {
+			return nil, fmt.Errorf("查询用户失败: %w", err)
+		}
+
+	case err == gorm.ErrRecordNotFound:
+		if linkUserID != 0 {
+			// 已登录用户主动绑定
+			if user, err = oc.userRepo.FindByID(linkUserID); err != nil {
+				return nil, fmt.Errorf("查询用户失败: %w", err)
+			}
+		} else {
+			var username string
+			if values := utils.ClaimStrings(claims, oc.usernameClaim()); len(values) > 0 {
+				username = strings.TrimSpace(values[0])
+			}
+			if username == "" {
+				return nil, errOIDCNoUsername
+			}
+
+			// 同名的本地用户不自动绑定：身份提供方的用户名可能被他人注册，只能由本地用户登录后主动绑定
+			_, err := oc.userRepo.FindByUsername(username)
+			switch {
+			case err == nil:
+				return nil, errOIDCUserExists
+			case err == gorm.ErrRecordNotFound:
+				if !cfg.AutoCreate {
+					return nil, errOIDCUserDisabled
+				}
+				if user, err = oc.createUser(username, oc.mapRole(claims)); err != nil {
+					return nil, fmt.Errorf("创建用户失败: %w", err)
+				}
+			default:
+				return nil, fmt.Errorf("查询用户失败: %w", err)
+			}
+		}
+
+		if err := oc.identityRepo.Create(&db.UserIdentity{
+			UserID:  user.ID,
+			Issuer:  issuer,
+			Subject: subject,
+			Email:   email,
+		}); err != nil {
+			return nil, fmt.Errorf("绑定身份失败: %w", err)
+		}
+		lib.Logger.Info(fmt.Sprintf("绑定第三方身份成功: user=%s, sub=%s", user.Username, subject))
+
+	default:
+		return nil, fmt.Errorf("查询绑定关系失败: %w", err)
+	}
+
+	// 配置了角色映射时，每次登录同步角色
+	if cfg.RoleClaim != "" {
+		role := oc.mapRole(claims)
+		if role != user.Role {
+			if err := oc.userRepo.UpdateRole(user.ID, role); err != nil {
+				return nil, fmt.Errorf("更新用户角色失败: %w", err)
+			}
+			user.Role = role
+		}
+	}
+
+	return user, nil
+}
+
+// createUser 自动创建本地用户，密码随机生成（只能通过单点登录或重置密码登录）
+func (oc *OIDCController) createUser(username, role string) (*db.User, error) {
+	salt, err := utils.GenerateSalt()
+	if err != nil {
+		return nil, err
+	}
+	password, err := utils.RandomString(24)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &db.User{
+		Username: username,
+		Password: utils.HashPassword(password, salt),
+		Salt:     salt,
+		Role:     role,
+	}
+	if err := oc.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// mapRole 将身份提供方的 claim 映射为本地角色，匹配到 admin 优先
+func (oc *OIDCController) mapRole(claims map[string]interface{}) string {
+	cfg := lib.GlobalConfig.OIDC
+	role := cfg.DefaultRole
+	if role == "" {
+		role = db.RoleUser
+	}
+	if cfg.RoleClaim == "" {
+		return role
+	}
+
+	for _, value := range utils.ClaimStrings(claims, cfg.RoleClaim) {
+		mapped, ok := cfg.RoleMapping[value]
+		if !ok {
+			continue
+		}
+		if mapped == db.RoleAdmin {
+			return db.RoleAdmin
+		}
+		role = mapped
+	}
+	return role
+}
+
+// getProvider 获取（首次使用时发现）身份提供方配置，失败时下次请求重试
+func (oc *OIDCController) getProvider() (*utils.OIDCProvider, error) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	if oc.provider == nil {
+		provider, err := utils.DiscoverOIDC(lib.GlobalConfig.OIDC.Issuer)
+		if err != nil {
+			return nil, err
+		}
+		oc.provider = provider
+	}
+	return oc.provider, nil
+}
+
+// usernameClaim 用作本地用户名的 claim
+func (oc *OIDCController) usernameClaim() string {
+	if claim := lib.GlobalConfig.OIDC.UsernameClaim; claim != "" {
+		return claim
+	}
+	return "preferred_username"
+}
+
+// secureCookie 回调地址为 https 时 cookie 仅通过 https 发送
+func (oc *OIDCController) secureCookie() bool {
+	return strings.HasPrefix(lib.GlobalConfig.OIDC.RedirectURL, "https://")
+}
+
+// clearStateCookie 删除 state cookie
+func (oc *OIDCController) clearStateCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", oc.secureCookie(), true)
+}
+
+// fail 登录失败：配置了前端地址时跳转并携带错误信息，否则返回 JSON
+func (oc *OIDCController) fail(c *gin.Context, msg string) {
+	if redirect := lib.GlobalConfig.OIDC.FrontendRedirect; redirect != "" {
+		c.Redirect(http.StatusFound, redirect+"#error="+url.QueryEscape(msg))
+		return
+	}
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 1,
+		Msg:  msg,
+	})
+}
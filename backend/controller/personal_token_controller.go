@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+// personalTokenPrefixLen 保存用于识别的令牌前缀长度（含 bkms_）
+const personalTokenPrefixLen = 12
+
+type PersonalTokenController struct {
+	tokenRepo *repo.PersonalTokenRepo
+}
+
+func NewPersonalTokenController() *PersonalTokenController {
+	return &PersonalTokenController{
+		tokenRepo: &repo.PersonalTokenRepo{},
+	}
+}
+
+// List 当前用户的个人访问令牌
+func (pc *PersonalTokenController) List(c *gin.Context) {
+	tokens, err := pc.tokenRepo.ListByUser(c.GetInt("user_id"))
+	if err != nil {
+		lib.Logger.Error("查询个人访问令牌失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.PersonalTokenItem, 0, len(tokens))
+	for i := range tokens {
+		items = append(items, toPersonalTokenItem(&tokens[i]))
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// Create 创建个人访问令牌，令牌明文只在本次响应中返回，数据库只保存哈希
+func (pc *PersonalTokenController) Create(c *gin.Context) {
+	var req dto.CreatePersonalTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	token := &db.PersonalToken{
+		UserID: c.GetInt("user_id"),
+		Name:   req.Name,
+	}
+	if req.ExpiresAt > 0 {
+		expiresAt := time.Unix(req.ExpiresAt, 0)
+		if !expiresAt.After(time.Now()) {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "过期时间必须晚于当前时间",
+			})
+			return
+		}
+		token.ExpiresAt = &expiresAt
+	}
+
+	plain, err := utils.GeneratePersonalToken()
+	if err == nil {
+		token.TokenHash = utils.HashPersonalToken(plain)
+		token.Prefix = plain[:personalTokenPrefixLen]
+		err = pc.tokenRepo.Create(token)
+	}
+	if err != nil {
+		lib.Logger.Error("创建个人访问令牌失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("创建个人访问令牌成功: " + token.Name)
+	item := toPersonalTokenItem(token)
+	writeAudit(c, db.AuditActionCreate, db.AuditEntityPersonalToken, []int{token.ID}, nil, item)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "创建成功",
+		Data: dto.CreatePersonalTokenData{
+			PersonalTokenItem: item,
+			Token:             plain,
+		},
+	})
+}
+
+// Delete 撤销个人访问令牌，撤销后令牌立即失效
+func (pc *PersonalTokenController) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return
+	}
+
+	token, err := pc.tokenRepo.FindByID(c.GetInt("user_id"), id)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			lib.Logger.Error("查询个人访问令牌失败: " + err.Error())
+		}
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "令牌不存在",
+		})
+		return
+	}
+
+	if err := pc.tokenRepo.Delete(token.ID); err != nil {
+		lib.Logger.Error("撤销个人访问令牌失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "撤销失败",
+		})
+		return
+	}
+
+	writeAudit(c, db.AuditActionDelete, db.AuditEntityPersonalToken, []int{token.ID}, toPersonalTokenItem(token), nil)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "撤销成功",
+	})
+}
+
+// toPersonalTokenItem 将个人访问令牌转换为 DTO
+func toPersonalTokenItem(token *db.PersonalToken) dto.PersonalTokenItem {
+	item := dto.PersonalTokenItem{
+		ID:        token.ID,
+		Name:      token.Name,
+		Prefix:    token.Prefix,
+		Expired:   token.Expired(),
+		CreatedAt: token.CreatedAt.Unix(),
+	}
+	if token.ExpiresAt != nil {
+		item.ExpiresAt = token.ExpiresAt.Unix()
+	}
+	if token.LastUsedAt != nil {
+		item.LastUsedAt = token.LastUsedAt.Unix()
+	}
+	return item
+}
@@ -0,0 +1,417 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+type SavedSearchController struct {
+	savedSearchRepo *repo.SavedSearchRepo
+	bookmarkRepo    *repo.BookmarkRepo
+	stateRepo       *repo.BookmarkStateRepo
+}
+
+func NewSavedSearchController() *SavedSearchController {
+	return &SavedSearchController{
+		savedSearchRepo: &repo.SavedSearchRepo{},
+		bookmarkRepo:    &repo.BookmarkRepo{},
+		stateRepo:       &repo.BookmarkStateRepo{},
+	}
+}
+
+// List 保存的搜索列表：自己创建的以及其他用户共享的，附带实时数量
+func (sc *SavedSearchController) List(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	searches, err := sc.savedSearchRepo.ListVisible(userID)
+	if err != nil {
+		lib.Logger.Error("查询保存的搜索失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.SavedSearchItem, 0, len(searches))
+	for i := range searches {
+		count, err := sc.bookmarkRepo.Count(savedSearchFilter(&searches[i]))
+		if err != nil {
+			lib.Logger.Error("统计书签数量失败: " + err.Error())
+		}
+		items = append(items, sc.toItem(c, &searches[i], count))
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// Create 创建保存的搜索
+func (sc *SavedSearchController) Create(c *gin.Context) {
+	var req dto.SaveSavedSearchRequest
+	if !bindSavedSearchRequest(c, &req) {
+		return
+	}
+
+	token, err := utils.RandomString(24)
+	if err != nil {
+		lib.Logger.Error("生成订阅令牌失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	search := &db.SavedSearch{
+		UserID:    c.GetInt("user_id"),
+		Name:      req.Name,
+		Keyword:   req.Keyword,
+		Tags:      strings.Join(req.Tags, ","),
+		Shared:    req.Shared,
+		FeedToken: token,
+	}
+	if err := sc.savedSearchRepo.Create(search); err != nil {
+		lib.Logger.Error("创建保存的搜索失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("创建保存的搜索成功: " + search.Name)
+	writeAudit(c, db.AuditActionCreate, db.AuditEntitySavedSearch, []int{search.ID}, nil, savedSearchSnapshot(search))
+	search.User = &db.User{ID: search.UserID, Username: c.GetString("username")}
+	count, _ := sc.bookmarkRepo.Count(savedSearchFilter(search))
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "创建成功",
+		Data: sc.toItem(c, search, count),
+	})
+}
+
+// Update 编辑保存的搜索（仅创建者）
+func (sc *SavedSearchController) Update(c *gin.Context) {
+	search, ok := sc.findOwned(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveSavedSearchRequest
+	if !bindSavedSearchRequest(c, &req) {
+		return
+	}
+
+	before := savedSearchSnapshot(search)
+	search.Name = req.Name
+	search.Keyword = req.Keyword
+	search.Tags = strings.Join(req.Tags, ",")
+	search.Shared = req.Shared
+	if err := sc.savedSearchRepo.Update(search); err != nil {
+		lib.Logger.Error("更新保存的搜索失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("更新保存的搜索成功: " + search.Name)
+	writeAudit(c, db.AuditActionUpdate, db.AuditEntitySavedSearch, []int{search.ID}, before, savedSearchSnapshot(search))
+	count, _ := sc.bookmarkRepo.Count(savedSearchFilter(search))
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: sc.toItem(c, search, count),
+	})
+}
+
+// Delete 删除保存的搜索（仅创建者）
+func (sc *SavedSearchController) Delete(c *gin.Context) {
+	search, ok := sc.findOwned(c)
+	if !ok {
+		return
+	}
+
+	if err := sc.savedSearchRepo.Delete(search.ID); err != nil {
+		lib.Logger.Error("删除保存的搜索失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("删除保存的搜索成功: " + search.Name)
+	writeAudit(c, db.AuditActionDelete, db.AuditEntitySavedSearch, []int{search.ID}, savedSearchSnapshot(search), nil)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "删除成功",
+	})
+}
+
+// Bookmarks 保存的搜索中的书签（按保存的条件实时查询）
+func (sc *SavedSearchController) Bookmarks(c *gin.Context) {
+	search, ok := sc.findVisible(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SavedSearchBookmarksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 设置默认分页大小
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	bookmarks, total, err := sc.bookmarkRepo.List(savedSearchFilter(search), req.Page, req.PageSize)
+	if err != nil {
+		lib.Logger.Error("查询书签列表失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.BookmarkListItem, 0, len(bookmarks))
+	for i := range bookmarks {
+		items = append(items, toBookmarkListItem(&bookmarks[i]))
+	}
+	fillBookmarkStates(sc.stateRepo, c.GetInt("user_id"), items)
+
+	c.JSON(http.StatusOK, dto.BookmarkListResponse{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.PageData{
+			Rows:  items,
+			Total: int(total),
+		},
+	})
+}
+
+// RegenerateFeedToken 重新生成订阅地址，旧的订阅地址失效（仅创建者）
+func (sc *SavedSearchController) RegenerateFeedToken(c *gin.Context) {
+	search, ok := sc.findOwned(c)
+	if !ok {
+		return
+	}
+
+	token, err := utils.RandomString(24)
+	if err == nil {
+		err = sc.savedSearchRepo.UpdateFeedToken(search.ID, token)
+	}
+	if err != nil {
+		lib.Logger.Error("更新订阅令牌失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+	search.FeedToken = token
+
+	count, _ := sc.bookmarkRepo.Count(savedSearchFilter(search))
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: sc.toItem(c, search, count),
+	})
+}
+
+// Feed 保存的搜索的订阅源（RSS 2.0 / Atom / JSON Feed），通过 URL 中的令牌访问，无需登录
+func (sc *SavedSearchController) Feed(c *gin.Context) {
+	search, err := sc.savedSearchRepo.FindByFeedToken(c.Param("token"))
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			lib.Logger.Error("查询保存的搜索失败: " + err.Error())
+		}
+		c.String(http.StatusNotFound, "feed not found")
+		return
+	}
+
+	bookmarks, _, err := sc.bookmarkRepo.List(savedSearchFilter(search), 1, feedItemLimit)
+	if err != nil {
+		lib.Logger.Error("查询书签列表失败: " + err.Error())
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	renderBookmarkFeed(c, search.Name, savedSearchDescription(search), bookmarks)
+}
+
+// findVisible 查找当前用户可见的保存的搜索（自己创建的或共享的）
+func (sc *SavedSearchController) findVisible(c *gin.Context) (*db.SavedSearch, bool) {
+	search, ok := sc.find(c)
+	if !ok {
+		return nil, false
+	}
+	if search.UserID != c.GetInt("user_id") && !search.Shared {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "保存的搜索不存在",
+		})
+		return nil, false
+	}
+	return search, true
+}
+
+// findOwned 查找当前用户创建的保存的搜索
+func (sc *SavedSearchController) findOwned(c *gin.Context) (*db.SavedSearch, bool) {
+	search, ok := sc.find(c)
+	if !ok {
+		return nil, false
+	}
+	if search.UserID != c.GetInt("user_id") {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "只能修改自己创建的搜索",
+		})
+		return nil, false
+	}
+	return search, true
+}
+
+// find 根据路径参数查找保存的搜索
+func (sc *SavedSearchController) find(c *gin.Context) (*db.SavedSearch, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return nil, false
+	}
+
+	search, err := sc.savedSearchRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "保存的搜索不存在",
+			})
+			return nil, false
+		}
+		lib.Logger.Error("查询保存的搜索失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return nil, false
+	}
+	return search, true
+}
+
+// toItem 将保存的搜索转换为 DTO
+func (sc *SavedSearchController) toItem(c *gin.Context, search *db.SavedSearch, count int64) dto.SavedSearchItem {
+	owner := ""
+	if search.User != nil {
+		owner = search.User.Username
+	}
+	tags := splitTags(search.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return dto.SavedSearchItem{
+		ID:        search.ID,
+		Name:      search.Name,
+		Keyword:   search.Keyword,
+		Tags:      tags,
+		Shared:    search.Shared,
+		Owner:     owner,
+		IsOwner:   search.UserID == c.GetInt("user_id"),
+		Count:     count,
+		FeedURL:   requestBaseURL(c) + "/feeds/saved-search/" + search.FeedToken,
+		CreatedAt: search.CreatedAt.Unix(),
+		UpdatedAt: search.UpdatedAt.Unix(),
+	}
+}
+
+// bindSavedSearchRequest 绑定并校验创建、编辑请求
+func bindSavedSearchRequest(c *gin.Context, req *dto.SaveSavedSearchRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return false
+	}
+
+	req.Keyword = strings.TrimSpace(req.Keyword)
+	req.Tags = splitTags(strings.Join(req.Tags, ","))
+	if req.Keyword == "" && len(req.Tags) == 0 {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "请设置搜索关键字或标签",
+		})
+		return false
+	}
+	return true
+}
+
+// savedSearchSnapshot 审计日志中记录的保存的搜索内容（不含订阅令牌）
+func savedSearchSnapshot(search *db.SavedSearch) gin.H {
+	return gin.H{
+		"name":    search.Name,
+		"keyword": search.Keyword,
+		"tags":    search.Tags,
+		"shared":  search.Shared,
+	}
+}
+
+// savedSearchFilter 保存的搜索对应的书签查询条件
+func savedSearchFilter(search *db.SavedSearch) repo.BookmarkFilter {
+	return repo.BookmarkFilter{
+		Keyword: search.Keyword,
+		Tags:    splitTags(search.Tags),
+	}
+}
+
+// savedSearchDescription 订阅源描述
+func savedSearchDescription(search *db.SavedSearch) string {
+	var conditions []string
+	if search.Keyword != "" {
+		conditions = append(conditions, "关键字: "+search.Keyword)
+	}
+	if search.Tags != "" {
+		conditions = append(conditions, "标签: "+search.Tags)
+	}
+	return strings.Join(conditions, "; ")
+}
+
+// requestBaseURL 根据请求推断服务的访问地址（支持反向代理的 X-Forwarded-Proto）
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0]))
+	}
+	return scheme + "://" + c.Request.Host
+}
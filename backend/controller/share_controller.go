@@ -0,0 +1,538 @@
+package controller
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+const sharePageSize = 20 // 分享列表页每页书签数
+
+// 访问密码错误次数限制：超过允许次数后按指数退避锁定
+const (
+	shareUnlockFreeAttempts   = 5           // 每个分享链接允许连续错误的次数
+	shareUnlockIPFreeAttempts = 20          // 每个 IP 允许连续错误的次数（所有分享链接合计）
+	shareUnlockBackoff        = time.Minute // 首次锁定时长
+	shareUnlockMaxBackoff     = time.Hour   // 最长锁定时长
+)
+
+// shareUnlockIPLimiter 按客户端 IP 限制访问密码的尝试次数
+var shareUnlockIPLimiter = utils.NewAttemptLimiter(shareUnlockIPFreeAttempts, shareUnlockBackoff, shareUnlockMaxBackoff)
+
+type ShareController struct {
+	shareRepo       *repo.ShareRepo
+	bookmarkRepo    *repo.BookmarkRepo
+	tagRepo         *repo.TagRepo
+	savedSearchRepo *repo.SavedSearchRepo
+	collectionRepo  *repo.CollectionRepo
+}
+
+func NewShareController() *ShareController {
+	return &ShareController{
+		shareRepo:       &repo.ShareRepo{},
+		bookmarkRepo:    &repo.BookmarkRepo{},
+		tagRepo:         &repo.TagRepo{},
+		savedSearchRepo: &repo.SavedSearchRepo{},
+		collectionRepo:  &repo.CollectionRepo{},
+	}
+}
+
+// List 当前用户创建的分享链接
+func (sc *ShareController) List(c *gin.Context) {
+	shares, err := sc.shareRepo.ListByUser(c.GetInt("user_id"))
+	if err != nil {
+		lib.Logger.Error("查询分享链接失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.ShareItem, 0, len(shares))
+	for i := range shares {
+		name, err := sc.targetName(&shares[i])
+		if err != nil && err != gorm.ErrRecordNotFound {
+			lib.Logger.Error("查询分享对象失败: " + err.Error())
+		}
+		items = append(items, toShareItem(c, &shares[i], name))
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// Create 创建分享链接
+func (sc *ShareController) Create(c *gin.Context) {
+	var req dto.CreateShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	share := &db.Share{
+		UserID:     c.GetInt("user_id"),
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+	}
+	if req.ExpiresAt > 0 {
+		expiresAt := time.Unix(req.ExpiresAt, 0)
+		if !expiresAt.After(time.Now()) {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "过期时间必须晚于当前时间",
+			})
+			return
+		}
+		share.ExpiresAt = &expiresAt
+	}
+
+	// 校验分享对象存在，保存的搜索需要对当前用户可见
+	name, err := sc.targetName(share)
+	if err == nil && share.TargetType == db.ShareTargetSavedSearch {
+		var search *db.SavedSearch
+		search, err = sc.savedSearchRepo.FindByID(share.TargetID)
+		if err == nil && search.UserID != share.UserID && !search.Shared {
+			err = gorm.ErrRecordNotFound
+		}
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "分享对象不存在",
+			})
+			return
+		}
+		lib.Logger.Error("查询分享对象失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	share.Token, err = utils.RandomString(24)
+	if err == nil && req.Password != "" {
+		share.Salt, err = utils.GenerateSalt()
+		share.Password = utils.HashPassword(req.Password, share.Salt)
+	}
+	if err == nil {
+		err = sc.shareRepo.Create(share)
+	}
+	if err != nil {
+		lib.Logger.Error("创建分享链接失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("创建分享链接成功: " + share.TargetType + " " + name)
+	item := toShareItem(c, share, name)
+	writeAudit(c, db.AuditActionCreate, db.AuditEntityShare, []int{share.ID}, nil, item)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "创建成功",
+		Data: item,
+	})
+}
+
+// Delete 撤销分享链接（仅创建者），撤销后链接立即失效
+func (sc *ShareController) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return
+	}
+
+	share, err := sc.shareRepo.FindByID(id)
+	if err != nil || share.UserID != c.GetInt("user_id") {
+		if err != nil && err != gorm.ErrRecordNotFound {
+			lib.Logger.Error("查询分享链接失败: " + err.Error())
+		}
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "分享链接不存在",
+		})
+		return
+	}
+
+	if err := sc.shareRepo.Delete(share.ID); err != nil {
+		lib.Logger.Error("撤销分享链接失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "撤销失败",
+		})
+		return
+	}
+
+	name, _ := sc.targetName(share)
+	writeAudit(c, db.AuditActionDelete, db.AuditEntityShare, []int{share.ID}, toShareItem(c, share, name), nil)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "撤销成功",
+	})
+}
+
+// View 公开访问分享链接：单个书签显示归档内容，标签、保存的搜索、收藏夹显示书签列表
+func (sc *ShareController) View(c *gin.Context) {
+	share, name, ok := sc.open(c)
+	if !ok {
+		return
+	}
+	if err := sc.shareRepo.RecordView(share.ID); err != nil {
+		lib.Logger.Error("记录分享访问失败: " + err.Error())
+	}
+
+	if share.TargetType == db.ShareTargetBookmark {
+		sc.renderBookmark(c, share.TargetID, "")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	bookmarks, total, err := sc.listBookmarks(share, page)
+	if err != nil {
+		lib.Logger.Error("查询分享书签失败: " + err.Error())
+		renderShareError(c, http.StatusInternalServerError, "页面加载失败，请稍后重试")
+		return
+	}
+
+	data := gin.H{
+		"Title":     name,
+		"Base":      shareBase(share),
+		"Total":     total,
+		"Bookmarks": bookmarks,
+	}
+	if page > 1 {
+		data["PrevPage"] = page - 1
+	}
+	if int64(page*sharePageSize) < total {
+		data["NextPage"] = page + 1
+	}
+	c.HTML(http.StatusOK, "share_list.html", data)
+}
+
+// ViewBookmark 公开访问书签列表分享中的单个书签
+func (sc *ShareController) ViewBookmark(c *gin.Context) {
+	share, _, ok := sc.open(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || share.TargetType == db.ShareTargetBookmark {
+		renderShareError(c, http.StatusNotFound, "书签不存在")
+		return
+	}
+	contains, err := sc.containsBookmark(share, id)
+	if err != nil {
+		lib.Logger.Error("查询分享书签失败: " + err.Error())
+		renderShareError(c, http.StatusInternalServerError, "页面加载失败，请稍后重试")
+		return
+	}
+	if !contains {
+		renderShareError(c, http.StatusNotFound, "书签不存在")
+		return
+	}
+
+	sc.renderBookmark(c, id, shareBase(share))
+}
+
+// Unlock 提交分享链接的访问密码，验证通过后写入 Cookie
+func (sc *ShareController) Unlock(c *gin.Context) {
+	share, _, ok := sc.find(c)
+	if !ok {
+		return
+	}
+	if share.Password == "" {
+		c.Redirect(http.StatusSeeOther, shareBase(share))
+		return
+	}
+
+	// 分享链接或客户端 IP 连续错误次数过多时暂时拒绝尝试
+	ip := c.ClientIP()
+	now := time.Now()
+	wait := shareUnlockIPLimiter.Locked(ip, now)
+	if share.UnlockLockedUntil != nil {
+		wait = max(wait, share.UnlockLockedUntil.Sub(now))
+	}
+	if wait > 0 {
+		renderSharePassword(c, share, http.StatusTooManyRequests,
+			fmt.Sprintf("密码错误次数过多，请 %d 分钟后重试", int(math.Ceil(wait.Minutes()))))
+		return
+	}
+
+	if !utils.VerifyPassword(c.PostForm("password"), share.Salt, share.Password) {
+		shareUnlockIPLimiter.Fail(ip, now)
+		if _, err := sc.shareRepo.RecordUnlockFailure(share.ID, shareUnlockFreeAttempts, shareUnlockBackoff, shareUnlockMaxBackoff); err != nil {
+			lib.Logger.Error("记录分享链接密码错误失败: " + err.Error())
+		}
+		renderSharePassword(c, share, http.StatusForbidden, "密码错误")
+		return
+	}
+	if share.UnlockFailures > 0 {
+		if err := sc.shareRepo.ResetUnlockFailures(share.ID); err != nil {
+			lib.Logger.Error("清零分享链接密码错误次数失败: " + err.Error())
+		}
+	}
+
+	maxAge := 86400
+	if share.ExpiresAt != nil {
+		if remain := int(time.Until(*share.ExpiresAt).Seconds()); remain < maxAge {
+			maxAge = remain
+		}
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(shareCookieName(share), shareUnlockValue(share), maxAge, shareBase(share), "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusSeeOther, shareBase(share))
+}
+
+// open 查找分享链接并校验访问密码，未通过时显示密码页
+func (sc *ShareController) open(c *gin.Context) (*db.Share, string, bool) {
+	share, name, ok := sc.find(c)
+	if !ok {
+		return nil, "", false
+	}
+	if share.Password != "" {
+		value, err := c.Cookie(shareCookieName(share))
+		if err != nil || !hmac.Equal([]byte(value), []byte(shareUnlockValue(share))) {
+			setShareHeaders(c)
+			// 验证密码前不显示分享对象的名称
+			c.HTML(http.StatusUnauthorized, "share_password.html", gin.H{
+				"Title": "需要密码",
+				"Base":  shareBase(share),
+			})
+			return nil, "", false
+		}
+	}
+	setShareHeaders(c)
+	return share, name, true
+}
+
+// find 根据令牌查找有效的分享链接（未过期且分享对象仍存在）
+func (sc *ShareController) find(c *gin.Context) (*db.Share, string, bool) {
+	share, err := sc.shareRepo.FindByToken(c.Param("token"))
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			lib.Logger.Error("查询分享链接失败: " + err.Error())
+		}
+		renderShareError(c, http.StatusNotFound, "分享链接不存在或已被撤销")
+		return nil, "", false
+	}
+	if share.Expired() {
+		renderShareError(c, http.StatusGone, "分享链接已过期")
+		return nil, "", false
+	}
+
+	name, err := sc.targetName(share)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			lib.Logger.Error("查询分享对象失败: " + err.Error())
+		}
+		renderShareError(c, http.StatusNotFound, "分享的内容已被删除")
+		return nil, "", false
+	}
+	return share, name, true
+}
+
+// renderBookmark 渲染单个书签：已归档时显示过滤后的归档 HTML，否则显示摘录与原文链接
+func (sc *ShareController) renderBookmark(c *gin.Context, id int, back string) {
+	bookmark, err := sc.bookmarkRepo.FindByID(id)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			lib.Logger.Error("查询书签失败: " + err.Error())
+		}
+		renderShareError(c, http.StatusNotFound, "书签不存在")
+		return
+	}
+
+	data := gin.H{
+		"Title":    bookmark.Title,
+		"Bookmark": bookmark,
+		"Back":     back,
+	}
+	if bookmark.IsArchive && bookmark.HTML != "" {
+		// 归档内容已按白名单过滤，可以直接输出
+		data["Content"] = template.HTML(utils.SanitizeHTML(bookmark.HTML))
+	}
+	c.HTML(http.StatusOK, "share_bookmark.html", data)
+}
+
+// targetName 分享对象的名称，同时用于校验分享对象是否存在
+func (sc *ShareController) targetName(share *db.Share) (string, error) {
+	switch share.TargetType {
+	case db.ShareTargetBookmark:
+		bookmark, err := sc.bookmarkRepo.FindByID(share.TargetID)
+		if err != nil {
+			return "", err
+		}
+		return bookmark.Title, nil
+	case db.ShareTargetTag:
+		tag, err := sc.tagRepo.FindByID(share.TargetID)
+		if err != nil {
+			return "", err
+		}
+		return tag.Name, nil
+	case db.ShareTargetSavedSearch:
+		search, err := sc.savedSearchRepo.FindByID(share.TargetID)
+		if err != nil {
+			return "", err
+		}
+		return search.Name, nil
+	case db.ShareTargetCollection:
+		collection, err := sc.collectionRepo.FindByID(share.TargetID)
+		if err != nil {
+			return "", err
+		}
+		return collection.Name, nil
+	}
+	return "", gorm.ErrRecordNotFound
+}
+
+// shareFilter 标签与保存的搜索对应的书签查询条件
+func (sc *ShareController) shareFilter(share *db.Share) (repo.BookmarkFilter, error) {
+	if share.TargetType == db.ShareTargetTag {
+		tag, err := sc.tagRepo.FindByID(share.TargetID)
+		if err != nil {
+			return repo.BookmarkFilter{}, err
+		}
+		return repo.BookmarkFilter{Tags: []string{tag.Name}}, nil
+	}
+	search, err := sc.savedSearchRepo.FindByID(share.TargetID)
+	if err != nil {
+		return repo.BookmarkFilter{}, err
+	}
+	return savedSearchFilter(search), nil
+}
+
+// listBookmarks 分页查询书签列表分享中的书签
+func (sc *ShareController) listBookmarks(share *db.Share, page int) ([]db.Bookmark, int64, error) {
+	if share.TargetType == db.ShareTargetCollection {
+		return sc.collectionRepo.ListBookmarks(share.TargetID, page, sharePageSize)
+	}
+	filter, err := sc.shareFilter(share)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sc.bookmarkRepo.List(filter, page, sharePageSize)
+}
+
+// containsBookmark 判断书签是否属于书签列表分享
+func (sc *ShareController) containsBookmark(share *db.Share, id int) (bool, error) {
+	if share.TargetType == db.ShareTargetCollection {
+		ids, err := sc.collectionRepo.BookmarkIDs(share.TargetID)
+		if err != nil {
+			return false, err
+		}
+		for _, bookmarkID := range ids {
+			if bookmarkID == id {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	filter, err := sc.shareFilter(share)
+	if err != nil {
+		return false, err
+	}
+	filter.IDs = []int{id}
+	count, err := sc.bookmarkRepo.Count(filter)
+	return count > 0, err
+}
+
+// toShareItem 将分享链接转换为 DTO
+func toShareItem(c *gin.Context, share *db.Share, name string) dto.ShareItem {
+	item := dto.ShareItem{
+		ID:          share.ID,
+		TargetType:  share.TargetType,
+		TargetID:    share.TargetID,
+		TargetName:  name,
+		HasPassword: share.Password != "",
+		Expired:     share.Expired(),
+		ViewCount:   share.ViewCount,
+		URL:         requestBaseURL(c) + shareBase(share),
+		CreatedAt:   share.CreatedAt.Unix(),
+	}
+	if share.ExpiresAt != nil {
+		item.ExpiresAt = share.ExpiresAt.Unix()
+	}
+	if share.LastViewedAt != nil {
+		item.LastViewedAt = share.LastViewedAt.Unix()
+	}
+	return item
+}
+
+// shareBase 分享链接的访问路径
+func shareBase(share *db.Share) string {
+	return "/s/" + share.Token
+}
+
+// shareCookieName 记录密码验证通过的 Cookie 名称
+func shareCookieName(share *db.Share) string {
+	return "share_" + strconv.Itoa(share.ID)
+}
+
+// shareUnlockValue 密码验证通过后的 Cookie 值：与令牌、密码绑定，修改密码或撤销后失效
+func shareUnlockValue(share *db.Share) string {
+	mac := hmac.New(sha256.New, []byte(lib.GlobalConfig.JWT.Secret))
+	mac.Write([]byte(share.Token + ":" + share.Password))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// setShareHeaders 公开分享页面的安全响应头：禁止脚本与嵌入，不向外部网站发送来源地址，不被搜索引擎收录
+func setShareHeaders(c *gin.Context) {
+	c.Header("Content-Security-Policy", "default-src 'none'; img-src * data:; style-src 'unsafe-inline'; form-action 'self'; base-uri 'none'; frame-ancestors 'none'")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+}
+
+// renderSharePassword 显示密码页与错误信息
+func renderSharePassword(c *gin.Context, share *db.Share, status int, message string) {
+	setShareHeaders(c)
+	c.HTML(status, "share_password.html", gin.H{
+		"Title": "需要密码",
+		"Base":  shareBase(share),
+		"Error": message,
+	})
+}
+
+// renderShareError 渲染分享页面的错误提示
+func renderShareError(c *gin.Context, status int, message string) {
+	setShareHeaders(c)
+	c.HTML(status, "share_error.html", gin.H{
+		"Title":   "无法访问",
+		"Message": message,
+	})
+}
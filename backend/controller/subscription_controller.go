@@ -0,0 +1,267 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/task"
+)
+
+type SubscriptionController struct {
+	subscriptionRepo *repo.SubscriptionRepo
+}
+
+func NewSubscriptionController() *SubscriptionController {
+	return &SubscriptionController{
+		subscriptionRepo: &repo.SubscriptionRepo{},
+	}
+}
+
+// List 订阅源列表，包含每个订阅源的拉取状态与错误信息
+func (sc *SubscriptionController) List(c *gin.Context) {
+	subscriptions, err := sc.subscriptionRepo.List()
+	if err != nil {
+		lib.Logger.Error("查询订阅源失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.SubscriptionItem, 0, len(subscriptions))
+	for i := range subscriptions {
+		items = append(items, toSubscriptionItem(&subscriptions[i]))
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// Create 创建订阅源，下一次定时拉取时生效，也可以手动立即拉取
+func (sc *SubscriptionController) Create(c *gin.Context) {
+	var req dto.SaveSubscriptionRequest
+	if !bindSubscriptionRequest(c, &req) {
+		return
+	}
+
+	subscription := &db.Subscription{
+		Name:    req.Name,
+		URL:     req.URL,
+		Tags:    strings.Join(req.Tags, ","),
+		Archive: req.Archive,
+		Enabled: req.Enabled == nil || *req.Enabled,
+	}
+	if err := sc.subscriptionRepo.Create(subscription); err != nil {
+		lib.Logger.Error("创建订阅源失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("创建订阅源成功: " + subscription.Name)
+	writeAudit(c, db.AuditActionCreate, db.AuditEntitySubscription, []int{subscription.ID}, nil, subscriptionSnapshot(subscription))
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "创建成功",
+		Data: toSubscriptionItem(subscription),
+	})
+}
+
+// Update 编辑订阅源
+func (sc *SubscriptionController) Update(c *gin.Context) {
+	subscription, ok := sc.find(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveSubscriptionRequest
+	if !bindSubscriptionRequest(c, &req) {
+		return
+	}
+
+	before := subscriptionSnapshot(subscription)
+	if subscription.URL != req.URL {
+		// 地址变化后旧的条件请求缓存不再适用
+		subscription.ETag = ""
+		subscription.LastModified = ""
+	}
+	subscription.Name = req.Name
+	subscription.URL = req.URL
+	subscription.Tags = strings.Join(req.Tags, ",")
+	subscription.Archive = req.Archive
+	if req.Enabled != nil {
+		subscription.Enabled = *req.Enabled
+	}
+	if err := sc.subscriptionRepo.Update(subscription); err != nil {
+		lib.Logger.Error("更新订阅源失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("更新订阅源成功: " + subscription.Name)
+	writeAudit(c, db.AuditActionUpdate, db.AuditEntitySubscription, []int{subscription.ID}, before, subscriptionSnapshot(subscription))
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: toSubscriptionItem(subscription),
+	})
+}
+
+// Delete 删除订阅源，已创建的书签保留
+func (sc *SubscriptionController) Delete(c *gin.Context) {
+	subscription, ok := sc.find(c)
+	if !ok {
+		return
+	}
+
+	if err := sc.subscriptionRepo.Delete(subscription.ID); err != nil {
+		lib.Logger.Error("删除订阅源失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("删除订阅源成功: " + subscription.Name)
+	writeAudit(c, db.AuditActionDelete, db.AuditEntitySubscription, []int{subscription.ID}, subscriptionSnapshot(subscription), nil)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "删除成功",
+	})
+}
+
+// Poll 立即拉取订阅源（不受启用状态限制），返回本次拉取结果
+func (sc *SubscriptionController) Poll(c *gin.Context) {
+	subscription, ok := sc.find(c)
+	if !ok {
+		return
+	}
+
+	result, err := task.PollSubscription(subscription)
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "拉取失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "拉取成功",
+		Data: result,
+	})
+}
+
+// find 根据路径参数查找订阅源
+func (sc *SubscriptionController) find(c *gin.Context) (*db.Subscription, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return nil, false
+	}
+
+	subscription, err := sc.subscriptionRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "订阅源不存在",
+			})
+			return nil, false
+		}
+		lib.Logger.Error("查询订阅源失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return nil, false
+	}
+	return subscription, true
+}
+
+// bindSubscriptionRequest 绑定并校验创建、编辑请求
+func bindSubscriptionRequest(c *gin.Context, req *dto.SaveSubscriptionRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.URL = strings.TrimSpace(req.URL)
+	req.Tags = splitTags(strings.Join(req.Tags, ","))
+	if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "订阅源地址只支持 http 与 https",
+		})
+		return false
+	}
+	return true
+}
+
+// toSubscriptionItem 将订阅源转换为 DTO
+func toSubscriptionItem(subscription *db.Subscription) dto.SubscriptionItem {
+	tags := splitTags(subscription.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	item := dto.SubscriptionItem{
+		ID:         subscription.ID,
+		Name:       subscription.Name,
+		URL:        subscription.URL,
+		Tags:       tags,
+		Archive:    subscription.Archive,
+		Enabled:    subscription.Enabled,
+		LastError:  subscription.LastError,
+		ErrorCount: subscription.ErrorCount,
+		ItemCount:  subscription.ItemCount,
+		CreatedAt:  subscription.CreatedAt.Unix(),
+		UpdatedAt:  subscription.UpdatedAt.Unix(),
+	}
+	if subscription.LastCheckedAt != nil {
+		item.LastCheckedAt = subscription.LastCheckedAt.Unix()
+	}
+	if subscription.LastSuccessAt != nil {
+		item.LastSuccessAt = subscription.LastSuccessAt.Unix()
+	}
+	return item
+}
+
+// subscriptionSnapshot 审计日志中记录的订阅源设置
+func subscriptionSnapshot(subscription *db.Subscription) gin.H {
+	return gin.H{
+		"name":    subscription.Name,
+		"url":     subscription.URL,
+		"tags":    subscription.Tags,
+		"archive": subscription.Archive,
+		"enabled": subscription.Enabled,
+	}
+}
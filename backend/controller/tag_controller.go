@@ -3,22 +3,28 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"bk_kms/index"
 	"bk_kms/lib"
+	"bk_kms/model/db"
 	"bk_kms/model/dto"
 	"bk_kms/repo"
+	"bk_kms/utils"
 )
 
 type TagController struct {
-	tagRepo *repo.TagRepo
+	tagRepo      *repo.TagRepo
+	bookmarkRepo *repo.BookmarkRepo
 }
 
 func NewTagController() *TagController {
 	return &TagController{
-		tagRepo: &repo.TagRepo{},
+		tagRepo:      &repo.TagRepo{},
+		bookmarkRepo: &repo.BookmarkRepo{},
 	}
 }
 
@@ -100,6 +106,7 @@ func (tc *TagController) Update(c *gin.Context) {
 	}
 
 	// 更新标签
+	oldName := tag.Name
 	tag.Name = req.Name
 	if err := tc.tagRepo.Update(tag); err != nil {
 		lib.Logger.Error("更新标签失败: " + err.Error())
@@ -111,6 +118,7 @@ func (tc *TagController) Update(c *gin.Context) {
 	}
 
 	lib.Logger.Info("更新标签成功: " + req.Name)
+	writeAudit(c, db.AuditActionTagRename, db.AuditEntityTag, []int{tag.ID}, gin.H{"name": oldName}, gin.H{"name": tag.Name})
 
 	c.JSON(http.StatusOK, dto.Response{
 		Code: 0,
@@ -118,3 +126,280 @@ func (tc *TagController) Update(c *gin.Context) {
 		Data: struct{}{},
 	})
 }
+
+// Merge 将多个tag合并到目标tag，合并后删除被合并的tag
+func (tc *TagController) Merge(c *gin.Context) {
+	var req dto.MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	for _, id := range req.SourceIDs {
+		if id == req.TargetID {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "不能将标签合并到自身",
+			})
+			return
+		}
+	}
+
+	target, err := tc.tagRepo.FindByID(req.TargetID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "标签不存在",
+			})
+			return
+		}
+		lib.Logger.Error("查询标签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "合并失败",
+		})
+		return
+	}
+	sources, err := tc.tagRepo.FindByIDs(req.SourceIDs)
+	if err != nil {
+		lib.Logger.Error("查询标签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "合并失败",
+		})
+		return
+	}
+	if len(sources) == 0 {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "标签不存在",
+		})
+		return
+	}
+	sourceIDs := make([]int, 0, len(sources))
+	sourceNames := make([]string, 0, len(sources))
+	for _, tag := range sources {
+		sourceIDs = append(sourceIDs, tag.ID)
+		sourceNames = append(sourceNames, tag.Name)
+	}
+
+	bookmarkIDs, err := tc.tagRepo.Merge(sourceIDs, target.ID)
+	if err != nil {
+		lib.Logger.Error("合并标签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "合并失败",
+		})
+		return
+	}
+
+	// 更新受影响书签在索引中的标签
+	if len(bookmarkIDs) > 0 {
+		bookmarks, err := tc.bookmarkRepo.FindByIDs(bookmarkIDs)
+		if err != nil {
+			lib.Logger.Error("查询书签失败: " + err.Error())
+		}
+		for i := range bookmarks {
+			index.Default().Put(&bookmarks[i])
+		}
+	}
+
+	lib.Logger.Info("合并标签成功: " + strings.Join(sourceNames, ", ") + " -> " + target.Name)
+	writeAudit(c, db.AuditActionTagMerge, db.AuditEntityTag, append(sourceIDs, target.ID), gin.H{"names": sourceNames}, gin.H{
+		"name":      target.Name,
+		"bookmarks": len(bookmarkIDs),
+	})
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "合并成功",
+		Data: gin.H{"bookmarks": len(bookmarkIDs)},
+	})
+}
+
+// Suggest 根据网址与正文从已有标签中推荐标签
+func (tc *TagController) Suggest(c *gin.Context) {
+	var req dto.SuggestTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	// 已有书签使用归档内容
+	if req.BookmarkID != 0 {
+		bookmark, err := tc.bookmarkRepo.FindByID(req.BookmarkID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusOK, dto.Response{
+					Code: 1,
+					Msg:  "书签不存在",
+				})
+				return
+			}
+			lib.Logger.Error("查询书签失败: " + err.Error())
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "推荐失败",
+			})
+			return
+		}
+		req.URL = bookmark.URL
+		req.Title = bookmark.Title
+		req.Content = bookmark.Content
+		if len(req.Tags) == 0 {
+			for _, tag := range bookmark.Tags {
+				req.Tags = append(req.Tags, tag.Name)
+			}
+		}
+	}
+	if req.URL == "" && req.Title == "" && req.Content == "" {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "请提供网址或正文",
+		})
+		return
+	}
+
+	// 未提供正文时抓取网页内容
+	if req.Content == "" && req.FetchContent && req.URL != "" {
+		bookmarkContent, err := utils.FetchBookmarkContent(req.URL, req.Title != "", true)
+		if err != nil {
+			lib.Logger.Error("获取书签内容失败: " + err.Error())
+		} else {
+			if req.Title == "" {
+				req.Title = bookmarkContent.Title
+			}
+			req.Content = bookmarkContent.Content
+		}
+	}
+
+	tags, err := tc.tagRepo.FindAll()
+	if err != nil {
+		lib.Logger.Error("查询标签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "推荐失败",
+		})
+		return
+	}
+	chosen := make(map[string]struct{}, len(req.Tags))
+	for _, name := range req.Tags {
+		chosen[strings.TrimSpace(name)] = struct{}{}
+	}
+	var chosenIDs []int
+	for _, tag := range tags {
+		if _, ok := chosen[tag.Name]; ok {
+			chosenIDs = append(chosenIDs, tag.ID)
+		}
+	}
+
+	text := req.Title + "\n" + req.Content
+	suggestions, err := index.Default().SuggestTags(index.SuggestInput{
+		URL:     req.URL,
+		Text:    text,
+		TagIDs:  chosenIDs,
+		Exclude: req.BookmarkID,
+	}, tags, req.Limit)
+	if err != nil {
+		if err == index.ErrNotReady {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  err.Error(),
+			})
+			return
+		}
+		lib.Logger.Error("推荐标签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "推荐失败",
+		})
+		return
+	}
+
+	keywords := make([]string, 0)
+	for _, keyword := range index.Default().Keywords(text, 10) {
+		keywords = append(keywords, keyword.Term)
+	}
+	items := make([]dto.SuggestTagItem, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		items = append(items, dto.SuggestTagItem{
+			ID:    suggestion.Tag.ID,
+			Name:  suggestion.Tag.Name,
+			Score: suggestion.Score,
+			Scores: dto.SuggestTagScores{
+				Keyword: suggestion.KeywordScore,
+				Similar: suggestion.SimilarScore,
+				Cooccur: suggestion.CooccurScore,
+				Domain:  suggestion.DomainScore,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.SuggestTagData{
+			Keywords: keywords,
+			Tags:     items,
+		},
+	})
+}
+
+// autoTagger 按配置阈值自动添加推荐标签，候选标签在创建时加载一次，批量导入时复用
+type autoTagger struct {
+	tags []db.Tag
+}
+
+// loadAutoTagger 加载推荐的候选标签，未开启自动标签（阈值或数量为 0）时不查询
+func loadAutoTagger() (*autoTagger, error) {
+	cfg := lib.GlobalConfig.TagAuto
+	if cfg.Threshold <= 0 || cfg.MaxTags <= 0 {
+		return &autoTagger{}, nil
+	}
+	tags, err := (&repo.TagRepo{}).FindAll()
+	if err != nil {
+		return &autoTagger{}, err
+	}
+	return &autoTagger{tags: tags}, nil
+}
+
+// suggest 返回置信度达到配置阈值的推荐标签（不含已选标签），推荐失败只记录日志
+func (at *autoTagger) suggest(url, text string, chosen []db.Tag) []db.Tag {
+	cfg := lib.GlobalConfig.TagAuto
+	if len(at.tags) == 0 || cfg.Threshold <= 0 || cfg.MaxTags <= 0 {
+		return nil
+	}
+	chosenIDs := make([]int, 0, len(chosen))
+	for _, tag := range chosen {
+		chosenIDs = append(chosenIDs, tag.ID)
+	}
+
+	suggestions, err := index.Default().SuggestTags(index.SuggestInput{
+		URL:    url,
+		Text:   text,
+		TagIDs: chosenIDs,
+	}, at.tags, cfg.MaxTags)
+	if err != nil {
+		if err != index.ErrNotReady {
+			lib.Logger.Error("推荐标签失败: " + err.Error())
+		}
+		return nil
+	}
+
+	var result []db.Tag
+	for _, suggestion := range suggestions {
+		if suggestion.Score >= cfg.Threshold {
+			result = append(result, suggestion.Tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,420 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bk_kms/index"
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+type TagRuleController struct {
+	tagRuleRepo  *repo.TagRuleRepo
+	bookmarkRepo *repo.BookmarkRepo
+}
+
+func NewTagRuleController() *TagRuleController {
+	return &TagRuleController{
+		tagRuleRepo:  &repo.TagRuleRepo{},
+		bookmarkRepo: &repo.BookmarkRepo{},
+	}
+}
+
+// List 自动标签规则列表
+func (tc *TagRuleController) List(c *gin.Context) {
+	rules, err := tc.tagRuleRepo.List()
+	if err != nil {
+		lib.Logger.Error("查询自动标签规则失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	items := make([]dto.TagRuleItem, 0, len(rules))
+	for i := range rules {
+		items = append(items, toTagRuleItem(&rules[i]))
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: items,
+	})
+}
+
+// Create 创建自动标签规则
+func (tc *TagRuleController) Create(c *gin.Context) {
+	var req dto.SaveTagRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if _, err := utils.NewTagRuleMatcher(req.Field, req.MatchType, req.Pattern); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "规则错误: " + err.Error(),
+		})
+		return
+	}
+
+	tags, err := tc.bookmarkRepo.FindOrCreateTags(req.Tags)
+	if err != nil {
+		lib.Logger.Error("处理标签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	rule := &db.TagRule{
+		Name:      req.Name,
+		Field:     req.Field,
+		MatchType: req.MatchType,
+		Pattern:   req.Pattern,
+		Enabled:   req.Enabled,
+		Tags:      tags,
+	}
+	if err := tc.tagRuleRepo.Create(rule); err != nil {
+		lib.Logger.Error("创建自动标签规则失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "创建失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("创建自动标签规则成功: " + rule.Name)
+	writeAudit(c, db.AuditActionCreate, db.AuditEntityTagRule, []int{rule.ID}, nil, toTagRuleItem(rule))
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "创建成功",
+		Data: toTagRuleItem(rule),
+	})
+}
+
+// Update 编辑自动标签规则
+func (tc *TagRuleController) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return
+	}
+
+	var req dto.SaveTagRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if _, err := utils.NewTagRuleMatcher(req.Field, req.MatchType, req.Pattern); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "规则错误: " + err.Error(),
+		})
+		return
+	}
+
+	rule, err := tc.tagRuleRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "规则不存在",
+			})
+			return
+		}
+		lib.Logger.Error("查询自动标签规则失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+	before := toTagRuleItem(rule)
+
+	tags, err := tc.bookmarkRepo.FindOrCreateTags(req.Tags)
+	if err != nil {
+		lib.Logger.Error("处理标签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	rule.Name = req.Name
+	rule.Field = req.Field
+	rule.MatchType = req.MatchType
+	rule.Pattern = req.Pattern
+	rule.Enabled = req.Enabled
+	rule.Tags = tags
+	if err := tc.tagRuleRepo.Update(rule); err != nil {
+		lib.Logger.Error("更新自动标签规则失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "更新失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("更新自动标签规则成功: " + rule.Name)
+	writeAudit(c, db.AuditActionUpdate, db.AuditEntityTagRule, []int{rule.ID}, before, toTagRuleItem(rule))
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "更新成功",
+		Data: toTagRuleItem(rule),
+	})
+}
+
+// Delete 删除自动标签规则（已添加的标签不受影响）
+func (tc *TagRuleController) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误",
+		})
+		return
+	}
+
+	rule, err := tc.tagRuleRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, dto.Response{
+				Code: 1,
+				Msg:  "规则不存在",
+			})
+			return
+		}
+		lib.Logger.Error("查询自动标签规则失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+
+	if err := tc.tagRuleRepo.Delete(id); err != nil {
+		lib.Logger.Error("删除自动标签规则失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("删除自动标签规则成功: " + rule.Name)
+	writeAudit(c, db.AuditActionDelete, db.AuditEntityTagRule, []int{rule.ID}, toTagRuleItem(rule), nil)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "删除成功",
+	})
+}
+
+// Apply 对已有书签执行自动标签规则，dry_run 时只返回将要发生的变更
+func (tc *TagRuleController) Apply(c *gin.Context) {
+	var req dto.ApplyTagRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	rules, err := loadTagRules(req.RuleIDs)
+	if err != nil {
+		lib.Logger.Error("查询自动标签规则失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "执行失败",
+		})
+		return
+	}
+
+	changes := make([]dto.TagRuleChange, 0)
+	err = tc.bookmarkRepo.EachBatch(200, func(bookmarks []db.Bookmark) error {
+		for i := range bookmarks {
+			bookmark := &bookmarks[i]
+			added, ruleIDs := rules.match(bookmark)
+			if len(added) == 0 {
+				continue
+			}
+
+			names := make([]string, 0, len(added))
+			for _, tag := range added {
+				names = append(names, tag.Name)
+			}
+			changes = append(changes, dto.TagRuleChange{
+				BookmarkID: bookmark.ID,
+				URL:        bookmark.URL,
+				Title:      bookmark.Title,
+				AddTags:    names,
+				RuleIDs:    ruleIDs,
+			})
+
+			if req.DryRun {
+				continue
+			}
+			if err := tc.bookmarkRepo.AddTags(bookmark, added); err != nil {
+				return err
+			}
+			index.Default().Put(bookmark)
+		}
+		return nil
+	})
+	if err != nil {
+		lib.Logger.Error("执行自动标签规则失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "执行失败",
+		})
+		return
+	}
+
+	if !req.DryRun && len(changes) > 0 {
+		lib.Logger.Info(fmt.Sprintf("执行自动标签规则成功: %d 个书签", len(changes)))
+		ids := make([]int, 0, len(changes))
+		diff := make(map[string][]string, len(changes))
+		for _, change := range changes {
+			ids = append(ids, change.BookmarkID)
+			diff[strconv.Itoa(change.BookmarkID)] = change.AddTags
+		}
+		writeAudit(c, db.AuditActionApply, db.AuditEntityBookmark, ids, nil, gin.H{
+			"rule_ids": req.RuleIDs,
+			"add_tags": diff,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.ApplyTagRulesData{
+			DryRun:  req.DryRun,
+			Total:   len(changes),
+			Changes: changes,
+		},
+	})
+}
+
+// toTagRuleItem 将自动标签规则转换为 DTO
+func toTagRuleItem(rule *db.TagRule) dto.TagRuleItem {
+	tags := make([]dto.TagItem, 0, len(rule.Tags))
+	for _, tag := range rule.Tags {
+		tags = append(tags, dto.TagItem{
+			ID:   tag.ID,
+			Name: tag.Name,
+		})
+	}
+
+	return dto.TagRuleItem{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		Field:     rule.Field,
+		MatchType: rule.MatchType,
+		Pattern:   rule.Pattern,
+		Enabled:   rule.Enabled,
+		Tags:      tags,
+		CreatedAt: rule.CreatedAt.Unix(),
+		UpdatedAt: rule.UpdatedAt.Unix(),
+	}
+}
+
+// compiledTagRule 编译后的自动标签规则
+type compiledTagRule struct {
+	rule    db.TagRule
+	matcher *utils.TagRuleMatcher
+}
+
+// tagRuleSet 一组自动标签规则
+type tagRuleSet []compiledTagRule
+
+// loadTagRules 加载并编译启用的自动标签规则，ids 不为空时只加载指定规则
+func loadTagRules(ids []int) (tagRuleSet, error) {
+	rules, err := (&repo.TagRuleRepo{}).ListEnabled(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(tagRuleSet, 0, len(rules))
+	for _, rule := range rules {
+		matcher, err := utils.NewTagRuleMatcher(rule.Field, rule.MatchType, rule.Pattern)
+		if err != nil {
+			lib.Logger.Error(fmt.Sprintf("自动标签规则 %d 无效: %s", rule.ID, err.Error()))
+			continue
+		}
+		set = append(set, compiledTagRule{rule: rule, matcher: matcher})
+	}
+	return set, nil
+}
+
+// match 计算书签满足的规则需要添加的标签（不含书签已有的标签）
+func (rs tagRuleSet) match(bookmark *db.Bookmark) ([]db.Tag, []int) {
+	if len(rs) == 0 {
+		return nil, nil
+	}
+
+	existing := make(map[int]struct{}, len(bookmark.Tags))
+	for _, tag := range bookmark.Tags {
+		existing[tag.ID] = struct{}{}
+	}
+
+	target := utils.TagRuleTarget{
+		URL:     bookmark.URL,
+		Title:   bookmark.Title,
+		Content: bookmark.Content,
+		Folder:  bookmark.Folder,
+	}
+
+	var added []db.Tag
+	var ruleIDs []int
+	for _, r := range rs {
+		if !r.matcher.Match(target) {
+			continue
+		}
+		ruleIDs = append(ruleIDs, r.rule.ID)
+		for _, tag := range r.rule.Tags {
+			if _, ok := existing[tag.ID]; ok {
+				continue
+			}
+			existing[tag.ID] = struct{}{}
+			added = append(added, tag)
+		}
+	}
+	return added, ruleIDs
+}
+
+// applyTagRules 对书签执行全部启用的自动标签规则，将标签追加到书签并返回新增的标签
+// 规则加载失败只记录日志，不影响保存书签
+func applyTagRules(bookmark *db.Bookmark) []db.Tag {
+	rules, err := loadTagRules(nil)
+	if err != nil {
+		lib.Logger.Error("查询自动标签规则失败: " + err.Error())
+		return nil
+	}
+	added, _ := rules.match(bookmark)
+	bookmark.Tags = append(bookmark.Tags, added...)
+	return added
+}
@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+type TOTPController struct {
+	userRepo         *repo.UserRepo
+	recoveryCodeRepo *repo.RecoveryCodeRepo
+}
+
+func NewTOTPController() *TOTPController {
+	return &TOTPController{
+		userRepo:         &repo.UserRepo{},
+		recoveryCodeRepo: &repo.RecoveryCodeRepo{},
+	}
+}
+
+// Status 两步验证状态
+func (tc *TOTPController) Status(c *gin.Context) {
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	left, err := tc.recoveryCodeRepo.CountUnused(user.ID)
+	if err != nil {
+		lib.Logger.Error("查询恢复码失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.TOTPStatusData{
+			Enabled:           user.TOTPEnabled,
+			RecoveryCodesLeft: left,
+		},
+	})
+}
+
+// Enroll 生成 TOTP 密钥，返回 otpauth URI 与二维码（需调用 Activate 校验后才会开启）
+func (tc *TOTPController) Enroll(c *gin.Context) {
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "两步验证已开启，请先关闭",
+		})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		lib.Logger.Error("生成TOTP密钥失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "生成失败",
+		})
+		return
+	}
+
+	issuer := lib.GlobalConfig.TOTP.Issuer
+	if issuer == "" {
+		issuer = "bk_kms"
+	}
+	uri := utils.TOTPURI(issuer, user.Username, secret)
+	qrCode, err := utils.TOTPQRCode(uri)
+	if err != nil {
+		lib.Logger.Error("生成二维码失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "生成失败",
+		})
+		return
+	}
+
+	if err := tc.userRepo.UpdateTOTP(user.ID, secret, false); err != nil {
+		lib.Logger.Error("保存TOTP密钥失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "生成失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.TOTPEnrollData{
+			Secret: secret,
+			URI:    uri,
+			QRCode: qrCode,
+		},
+	})
+}
+
+// Activate 校验动态码并开启两步验证，返回一次性恢复码
+func (tc *TOTPController) Activate(c *gin.Context) {
+	var req dto.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "两步验证已开启",
+		})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "请先获取两步验证密钥",
+		})
+		return
+	}
+
+	step := utils.VerifyTOTP(user.TOTPSecret, req.Code, 0, time.Now())
+	if step == 0 {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "两步验证码错误",
+		})
+		return
+	}
+
+	if err := tc.userRepo.UpdateTOTP(user.ID, user.TOTPSecret, true); err != nil {
+		lib.Logger.Error("开启两步验证失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "开启失败",
+		})
+		return
+	}
+	if _, err := tc.userRepo.UseTOTPStep(user.ID, step); err != nil {
+		lib.Logger.Error("记录TOTP时间步失败: " + err.Error())
+	}
+
+	codes, err := tc.resetRecoveryCodes(user.ID)
+	if err != nil {
+		lib.Logger.Error("生成恢复码失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "生成恢复码失败",
+		})
+		return
+	}
+
+	lib.Logger.Info("开启两步验证成功: " + user.Username)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "开启成功",
+		Data: dto.TOTPRecoveryCodesData{
+			RecoveryCodes: codes,
+		},
+	})
+}
+
+// Disable 关闭两步验证（需提交动态码或恢复码）
+func (tc *TOTPController) Disable(c *gin.Context) {
+	var req dto.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if !tc.checkCode(c, user, req.Code) {
+		return
+	}
+
+	if err := tc.userRepo.UpdateTOTP(user.ID, "", false); err != nil {
+		lib.Logger.Error("关闭两步验证失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "关闭失败",
+		})
+		return
+	}
+	if err := tc.recoveryCodeRepo.Replace(user.ID, nil); err != nil {
+		lib.Logger.Error("删除恢复码失败: " + err.Error())
+	}
+
+	lib.Logger.Info("关闭两步验证成功: " + user.Username)
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "关闭成功",
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码（旧恢复码全部作废）
+func (tc *TOTPController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if !tc.checkCode(c, user, req.Code) {
+		return
+	}
+
+	codes, err := tc.resetRecoveryCodes(user.ID)
+	if err != nil {
+		lib.Logger.Error("生成恢复码失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "生成恢复码失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.TOTPRecoveryCodesData{
+			RecoveryCodes: codes,
+		},
+	})
+}
+
+// currentUser 查询当前登录用户，失败时直接写入响应
+func (tc *TOTPController) currentUser(c *gin.Context) (*db.User, bool) {
+	user, err := tc.userRepo.FindByID(c.GetInt("user_id"))
+	if err != nil {
+		lib.Logger.Error("查询用户失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "用户不存在",
+		})
+		return nil, false
+	}
+	return user, true
+}
+
+// checkCode 校验已开启两步验证用户提交的动态码或恢复码，失败时直接写入响应
+func (tc *TOTPController) checkCode(c *gin.Context, user *db.User, code string) bool {
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "两步验证未开启",
+		})
+		return false
+	}
+
+	ok, err := verifySecondFactor(user, code)
+	if err != nil {
+		lib.Logger.Error("校验两步验证码失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "校验失败",
+		})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "两步验证码错误",
+		})
+		return false
+	}
+	return true
+}
+
+// resetRecoveryCodes 生成新的恢复码并保存哈希，返回明文
+func (tc *TOTPController) resetRecoveryCodes(userID int) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, utils.HashRecoveryCode(code))
+	}
+	if err := tc.recoveryCodeRepo.Replace(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifySecondFactor 校验动态码（6 位数字）或一次性恢复码
+func verifySecondFactor(user *db.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return false, nil
+	}
+
+	// 恢复码格式 xxxxx-xxxxx，其余按动态码处理
+	if strings.Contains(code, "-") {
+		return (&repo.RecoveryCodeRepo{}).Use(user.ID, utils.HashRecoveryCode(code))
+	}
+
+	step := utils.VerifyTOTP(user.TOTPSecret, code, user.TOTPLastStep, time.Now())
+	if step == 0 {
+		return false, nil
+	}
+	return (&repo.UserRepo{}).UseTOTPStep(user.ID, step)
+}
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"bk_kms/index"
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+)
+
+type TrashController struct {
+	bookmarkRepo *repo.BookmarkRepo
+}
+
+func NewTrashController() *TrashController {
+	return &TrashController{
+		bookmarkRepo: &repo.BookmarkRepo{},
+	}
+}
+
+// List 回收站书签列表
+func (tc *TrashController) List(c *gin.Context) {
+	var req dto.TrashListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 设置默认分页大小
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	bookmarks, total, err := tc.bookmarkRepo.ListTrash(req.Keyword, req.Page, req.PageSize)
+	if err != nil {
+		lib.Logger.Error("查询回收站失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "查询失败",
+		})
+		return
+	}
+
+	// 转换为 DTO
+	items := make([]dto.TrashListItem, 0, len(bookmarks))
+	for i := range bookmarks {
+		items = append(items, dto.TrashListItem{
+			BookmarkListItem: toBookmarkListItem(&bookmarks[i]),
+			DeletedAt:        bookmarks[i].DeletedAt.Time.Unix(),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "成功",
+		Data: dto.PageData{
+			Rows:  items,
+			Total: int(total),
+		},
+	})
+}
+
+// Restore 从回收站恢复书签
+func (tc *TrashController) Restore(c *gin.Context) {
+	var ids dto.TrashIDsRequest
+	if err := c.ShouldBindJSON(&ids); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if len(ids) == 0 {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "请选择要恢复的书签",
+		})
+		return
+	}
+
+	restored, err := tc.bookmarkRepo.Restore(ids)
+	if err != nil {
+		lib.Logger.Error("恢复书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "恢复失败",
+		})
+		return
+	}
+
+	lib.Logger.Info(fmt.Sprintf("恢复书签成功: %v", restored))
+	if len(restored) > 0 {
+		writeAudit(c, db.AuditActionRestore, db.AuditEntityBookmark, restored, nil, nil)
+
+		// 恢复的书签重新加入索引
+		bookmarks, err := tc.bookmarkRepo.FindByIDs(restored)
+		if err != nil {
+			lib.Logger.Error("查询书签失败: " + err.Error())
+		}
+		for i := range bookmarks {
+			index.Default().Put(&bookmarks[i])
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  fmt.Sprintf("恢复成功 %d 个书签", len(restored)),
+		Data: dto.TrashOperationData{
+			IDs:     restored,
+			Skipped: skippedIDs(ids, restored),
+		},
+	})
+}
+
+// Purge 彻底删除回收站中的书签
+func (tc *TrashController) Purge(c *gin.Context) {
+	var ids dto.TrashIDsRequest
+	if err := c.ShouldBindJSON(&ids); err != nil {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if len(ids) == 0 {
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "请选择要彻底删除的书签",
+		})
+		return
+	}
+
+	// 记录删除前的快照
+	bookmarks, err := tc.bookmarkRepo.FindTrashByIDs(ids)
+	if err != nil {
+		lib.Logger.Error("查询书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+	before := make(map[string]*bookmarkAuditSnapshot, len(bookmarks))
+	for i := range bookmarks {
+		before[strconv.Itoa(bookmarks[i].ID)] = newBookmarkAuditSnapshot(&bookmarks[i])
+	}
+
+	purged, err := tc.bookmarkRepo.Purge(ids)
+	if err != nil {
+		lib.Logger.Error("彻底删除书签失败: " + err.Error())
+		c.JSON(http.StatusOK, dto.Response{
+			Code: 1,
+			Msg:  "删除失败",
+		})
+		return
+	}
+
+	lib.Logger.Info(fmt.Sprintf("彻底删除书签成功: %v", purged))
+	if len(purged) > 0 {
+		writeAudit(c, db.AuditActionPurge, db.AuditEntityBookmark, purged, before, nil)
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Code: 0,
+		Msg:  "删除成功",
+		Data: dto.TrashOperationData{
+			IDs:     purged,
+			Skipped: skippedIDs(ids, purged),
+		},
+	})
+}
+
+// skippedIDs 计算请求中未处理的ID
+func skippedIDs(requested, done []int) []int {
+	doneSet := make(map[int]struct{}, len(done))
+	for _, id := range done {
+		doneSet[id] = struct{}{}
+	}
+
+	skipped := make([]int, 0)
+	for _, id := range requested {
+		if _, ok := doneSet[id]; !ok {
+			skipped = append(skipped, id)
+		}
+	}
+	return skipped
+}
@@ -0,0 +1,45 @@
+package index
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/repo"
+)
+
+// buildMu 同一时间只进行一次构建
+var buildMu sync.Mutex
+
+// Build 从数据库加载全部书签重建全局索引，加载期间对索引的修改在重建后重放
+func Build() error {
+	buildMu.Lock()
+	defer buildMu.Unlock()
+	start := time.Now()
+	defaultIndex.beginBuild()
+
+	var bookmarks []db.Bookmark
+	err := (&repo.BookmarkRepo{}).EachBatch(200, func(batch []db.Bookmark) error {
+		for i := range batch {
+			// 只保留索引需要的字段，降低峰值内存
+			bookmarks = append(bookmarks, db.Bookmark{
+				ID:      batch[i].ID,
+				URL:     batch[i].URL,
+				Title:   batch[i].Title,
+				Content: batch[i].Content,
+				Tags:    batch[i].Tags,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		defaultIndex.endBuild()
+		return err
+	}
+
+	defaultIndex.Reset(bookmarks)
+	lib.Logger.Info(fmt.Sprintf("书签索引构建完成: %d 个书签, 耗时 %s", len(bookmarks), time.Since(start)))
+	return nil
+}
@@ -0,0 +1,268 @@
+// Package index 进程内的书签文本索引，用于相关书签推荐与标签推荐
+//
+// 文本使用 utils.Tokenize 切分：中日韩文字没有使用词典分词，而是按相邻两字（二元组）切分，
+// 与 MySQL ngram 全文解析器一致。这是有意的近似：不依赖词典、对新词和专有名词同样有效，
+// 但会产生跨词的二元组（如"中文分词"中的"文分"），连续文字中的单字词无法单独匹配，相似度只适合用于排序推荐
+package index
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+
+	"bk_kms/model/db"
+	"bk_kms/utils"
+)
+
+const maxTerms = 300 // 每个书签保留词频最高的词数，控制内存占用
+
+// ErrNotReady 索引尚未构建完成
+var ErrNotReady = errors.New("索引构建中，请稍后重试")
+
+// ErrNotFound 书签不在索引中
+var ErrNotFound = errors.New("书签不在索引中")
+
+// document 索引中的书签
+type document struct {
+	id     int
+	domain string
+	tags   map[int]struct{}
+	tf     map[string]float64 // 词频（已按文档长度归一化）
+}
+
+// Index 书签文本索引：TF-IDF 倒排索引 + 标签、域名
+type Index struct {
+	mu       sync.RWMutex
+	ready    bool
+	docs     map[int]*document
+	postings map[string]map[int]struct{} // 词 -> 包含该词的书签
+
+	// 从数据库加载书签期间的修改，Reset 时重放，避免新索引丢失加载期间新增、修改或删除的书签
+	building bool
+	changes  []change
+}
+
+// change 构建期间的一次修改，doc 为空表示移除
+type change struct {
+	id  int
+	doc *document
+}
+
+var defaultIndex = New()
+
+// New 创建空索引
+func New() *Index {
+	return &Index{
+		docs:     make(map[int]*document),
+		postings: make(map[string]map[int]struct{}),
+	}
+}
+
+// Default 全局索引
+func Default() *Index {
+	return defaultIndex
+}
+
+// Reset 用一组书签重建索引并标记为可用
+func (idx *Index) Reset(bookmarks []db.Bookmark) {
+	docs := make(map[int]*document, len(bookmarks))
+	postings := make(map[string]map[int]struct{})
+	for i := range bookmarks {
+		doc := newDocument(&bookmarks[i])
+		docs[doc.id] = doc
+		addPostings(postings, doc)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.docs = docs
+	idx.postings = postings
+	for _, c := range idx.changes {
+		idx.remove(c.id)
+		if c.doc != nil {
+			idx.docs[c.id] = c.doc
+			addPostings(idx.postings, c.doc)
+		}
+	}
+	idx.building = false
+	idx.changes = nil
+	idx.ready = true
+}
+
+// beginBuild 开始从数据库加载书签，之后的修改会被记录并在 Reset 时重放
+func (idx *Index) beginBuild() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.building = true
+	idx.changes = nil
+}
+
+// endBuild 加载失败时停止记录修改
+func (idx *Index) endBuild() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.building = false
+	idx.changes = nil
+}
+
+// Ready 索引是否已构建完成
+func (idx *Index) Ready() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.ready
+}
+
+// Size 索引中的书签数量
+func (idx *Index) Size() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Put 添加或更新书签
+func (idx *Index) Put(bookmark *db.Bookmark) {
+	doc := newDocument(bookmark)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(doc.id)
+	idx.docs[doc.id] = doc
+	addPostings(idx.postings, doc)
+	if idx.building {
+		idx.changes = append(idx.changes, change{id: doc.id, doc: doc})
+	}
+}
+
+// Remove 移除书签
+func (idx *Index) Remove(ids ...int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, id := range ids {
+		idx.remove(id)
+		if idx.building {
+			idx.changes = append(idx.changes, change{id: id})
+		}
+	}
+}
+
+// remove 移除书签（调用方需持有写锁）
+func (idx *Index) remove(id int) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.tf {
+		if ids := idx.postings[term]; ids != nil {
+			delete(ids, id)
+			if len(ids) == 0 {
+				delete(idx.postings, term)
+			}
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// idf 逆文档频率（调用方需持有读锁）
+func (idx *Index) idf(term string) float64 {
+	return math.Log(float64(len(idx.docs)+1)/float64(len(idx.postings[term])+1)) + 1
+}
+
+// norm TF-IDF 向量的模（调用方需持有读锁）
+func (idx *Index) norm(doc *document) float64 {
+	var sum float64
+	for term, tf := range doc.tf {
+		w := tf * idx.idf(term)
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// newDocument 根据书签生成索引文档，文本为标题与正文
+func newDocument(bookmark *db.Bookmark) *document {
+	tags := make(map[int]struct{}, len(bookmark.Tags))
+	for _, tag := range bookmark.Tags {
+		tags[tag.ID] = struct{}{}
+	}
+
+	return &document{
+		id:     bookmark.ID,
+		domain: utils.URLDomain(bookmark.URL),
+		tags:   tags,
+		tf:     TermFrequency(bookmark.Title + "\n" + bookmark.Content),
+	}
+}
+
+// addPostings 将文档加入倒排索引
+func addPostings(postings map[string]map[int]struct{}, doc *document) {
+	for term := range doc.tf {
+		ids := postings[term]
+		if ids == nil {
+			ids = make(map[int]struct{})
+			postings[term] = ids
+		}
+		ids[doc.id] = struct{}{}
+	}
+}
+
+// TermFrequency 计算文本的词频（去掉停用词，只保留词频最高的 maxTerms 个词）
+func TermFrequency(text string) map[string]float64 {
+	counts := make(map[string]int)
+	total := 0
+	for _, token := range utils.Tokenize(text) {
+		if isStopword(token) {
+			continue
+		}
+		counts[token]++
+		total++
+	}
+	if total == 0 {
+		return map[string]float64{}
+	}
+
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+	if len(terms) > maxTerms {
+		sort.Slice(terms, func(i, j int) bool {
+			if counts[terms[i]] != counts[terms[j]] {
+				return counts[terms[i]] > counts[terms[j]]
+			}
+			return terms[i] < terms[j]
+		})
+		terms = terms[:maxTerms]
+	}
+
+	tf := make(map[string]float64, len(terms))
+	for _, term := range terms {
+		tf[term] = float64(counts[term]) / float64(total)
+	}
+	return tf
+}
+
+// stopwords 常见英文停用词
+var stopwords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "but": {}, "by": {},
+	"can": {}, "do": {}, "for": {}, "from": {}, "has": {}, "have": {}, "how": {}, "if": {}, "in": {},
+	"into": {}, "is": {}, "it": {}, "its": {}, "not": {}, "of": {}, "on": {}, "or": {}, "our": {},
+	"so": {}, "than": {}, "that": {}, "the": {}, "their": {}, "then": {}, "there": {}, "these": {},
+	"they": {}, "this": {}, "to": {}, "was": {}, "we": {}, "were": {}, "what": {}, "when": {},
+	"which": {}, "will": {}, "with": {}, "you": {}, "your": {},
+}
+
+// isStopword 判断是否为停用词（包括单个拉丁字母与纯数字）
+func isStopword(token string) bool {
+	if _, ok := stopwords[token]; ok {
+		return true
+	}
+	if len(token) == 1 && token[0] < 0x80 {
+		return true
+	}
+	for _, r := range token {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
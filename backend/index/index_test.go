@@ -0,0 +1,103 @@
+package index
+
+import (
+	"testing"
+
+	"bk_kms/model/db"
+)
+
+func TestResetReplaysChangesDuringBuild(t *testing.T) {
+	// 构建开始时数据库中的书签
+	loaded := []db.Bookmark{
+		{ID: 1, URL: "https://example.com/1", Title: "golang 并发"},
+		{ID: 2, URL: "https://example.com/2", Title: "rust 所有权"},
+		{ID: 3, URL: "https://example.com/3", Title: "python 装饰器"},
+	}
+
+	tests := []struct {
+		name    string
+		changes func(idx *Index)
+		want    []int
+		missing []int
+	}{
+		{
+			name:    "没有修改",
+			changes: func(idx *Index) {},
+			want:    []int{1, 2, 3},
+		},
+		{
+			name: "构建期间新增的书签",
+			changes: func(idx *Index) {
+				idx.Put(&db.Bookmark{ID: 4, URL: "https://example.com/4", Title: "kubernetes 调度"})
+			},
+			want: []int{1, 2, 3, 4},
+		},
+		{
+			name: "构建期间删除的书签",
+			changes: func(idx *Index) {
+				idx.Remove(2)
+			},
+			want:    []int{1, 3},
+			missing: []int{2},
+		},
+		{
+			name: "先新增后删除",
+			changes: func(idx *Index) {
+				idx.Put(&db.Bookmark{ID: 5, URL: "https://example.com/5", Title: "mysql 索引"})
+				idx.Remove(5)
+			},
+			want:    []int{1, 2, 3},
+			missing: []int{5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := New()
+			idx.beginBuild()
+			tt.changes(idx)
+			idx.Reset(loaded)
+
+			if !idx.Ready() {
+				t.Fatal("Ready() = false after Reset")
+			}
+			if idx.Size() != len(tt.want) {
+				t.Errorf("Size() = %d, want %d", idx.Size(), len(tt.want))
+			}
+			for _, id := range tt.want {
+				if _, ok := idx.docs[id]; !ok {
+					t.Errorf("bookmark %d not in index", id)
+				}
+			}
+			for _, id := range tt.missing {
+				if _, ok := idx.docs[id]; ok {
+					t.Errorf("bookmark %d should not be in index", id)
+				}
+				for term, ids := range idx.postings {
+					if _, ok := ids[id]; ok {
+						t.Errorf("bookmark %d still in postings of %q", id, term)
+					}
+				}
+			}
+			if idx.building || len(idx.changes) != 0 {
+				t.Errorf("building = %v, changes = %d after Reset", idx.building, len(idx.changes))
+			}
+		})
+	}
+}
+
+func TestChangesNotRecordedOutsideBuild(t *testing.T) {
+	idx := New()
+	idx.Put(&db.Bookmark{ID: 1, URL: "https://example.com/1", Title: "golang"})
+	idx.Remove(1)
+	if len(idx.changes) != 0 {
+		t.Errorf("changes = %d, want 0", len(idx.changes))
+	}
+
+	idx.beginBuild()
+	idx.Put(&db.Bookmark{ID: 2, URL: "https://example.com/2", Title: "rust"})
+	idx.endBuild()
+	idx.Reset(nil)
+	if idx.Size() != 0 {
+		t.Errorf("Size() = %d after aborted build, want 0", idx.Size())
+	}
+}
@@ -0,0 +1,99 @@
+package index
+
+import (
+	"sort"
+)
+
+// 相关度各部分的权重
+const (
+	tagWeight    = 0.4 // 共同标签
+	domainWeight = 0.2 // 相同域名
+	textWeight   = 0.4 // 正文 TF-IDF 相似度
+)
+
+// Related 相关书签及相关度明细
+type Related struct {
+	ID          int
+	Score       float64 // 综合相关度（0~1）
+	TagScore    float64 // 共同标签（Jaccard 系数）
+	DomainScore float64 // 相同域名为 1
+	TextScore   float64 // 正文 TF-IDF 余弦相似度
+	SharedTags  []int   // 共同标签ID
+}
+
+// Related 查找与指定书签相关的书签，按综合相关度降序
+func (idx *Index) Related(id int, limit int) ([]Related, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if !idx.ready {
+		return nil, ErrNotReady
+	}
+	target, ok := idx.docs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	// 目标书签的 TF-IDF 向量
+	weights := make(map[string]float64, len(target.tf))
+	for term, tf := range target.tf {
+		weights[term] = tf * idx.idf(term)
+	}
+	targetNorm := idx.norm(target)
+
+	// 书签数量在万级以内，直接逐个计算
+	results := make([]Related, 0)
+	for _, doc := range idx.docs {
+		if doc.id == id {
+			continue
+		}
+
+		related := Related{ID: doc.id}
+
+		// 共同标签
+		for tagID := range doc.tags {
+			if _, ok := target.tags[tagID]; ok {
+				related.SharedTags = append(related.SharedTags, tagID)
+			}
+		}
+		if len(related.SharedTags) > 0 {
+			union := len(target.tags) + len(doc.tags) - len(related.SharedTags)
+			related.TagScore = float64(len(related.SharedTags)) / float64(union)
+			sort.Ints(related.SharedTags)
+		}
+
+		// 相同域名
+		if target.domain != "" && doc.domain == target.domain {
+			related.DomainScore = 1
+		}
+
+		// 正文相似度
+		if targetNorm > 0 {
+			var dot float64
+			for term, tf := range doc.tf {
+				if w, ok := weights[term]; ok {
+					dot += w * tf * idx.idf(term)
+				}
+			}
+			if dot > 0 {
+				related.TextScore = dot / (targetNorm * idx.norm(doc))
+			}
+		}
+
+		related.Score = tagWeight*related.TagScore + domainWeight*related.DomainScore + textWeight*related.TextScore
+		if related.Score > 0 {
+			results = append(results, related)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID > results[j].ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
@@ -0,0 +1,273 @@
+package index
+
+import (
+	"math"
+	"sort"
+
+	"bk_kms/model/db"
+	"bk_kms/utils"
+)
+
+// 标签推荐各部分的权重
+const (
+	keywordWeight = 0.3 // 标签名出现在正文关键词中
+	similarWeight = 0.3 // 正文相似的书签使用了该标签
+	cooccurWeight = 0.2 // 与已选标签共同出现
+	historyWeight = 0.2 // 同域名书签使用了该标签
+
+	suggestKeywords = 50 // 参与标签名匹配的关键词数
+	suggestNeighbor = 10 // 参与投票的相似书签数
+)
+
+// SuggestInput 标签推荐的输入
+type SuggestInput struct {
+	URL     string
+	Text    string // 标题与正文
+	TagIDs  []int  // 已选择的标签
+	Exclude int    // 不参与统计的书签（为已有书签推荐标签时排除自身）
+}
+
+// TagSuggestion 推荐的标签及得分明细
+type TagSuggestion struct {
+	Tag          db.Tag
+	Score        float64 // 综合置信度（0~1）
+	KeywordScore float64 // 标签名与正文关键词的匹配程度
+	SimilarScore float64 // 正文相似书签中使用该标签的比例（按相似度加权）
+	CooccurScore float64 // 与已选标签共同出现的条件概率
+	DomainScore  float64 // 同域名书签中使用该标签的比例
+}
+
+// Keyword 关键词
+type Keyword struct {
+	Term   string
+	Weight float64 // TF-IDF 权重，最高为 1
+}
+
+// Keywords 使用 TF-IDF 提取文本关键词（中文按二元组切分），按权重降序
+func (idx *Index) Keywords(text string, limit int) []Keyword {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.keywords(TermFrequency(text), limit)
+}
+
+// keywords 提取关键词（调用方需持有读锁）
+func (idx *Index) keywords(tf map[string]float64, limit int) []Keyword {
+	keywords := make([]Keyword, 0, len(tf))
+	for term, freq := range tf {
+		keywords = append(keywords, Keyword{Term: term, Weight: freq * idx.idf(term)})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Weight != keywords[j].Weight {
+			return keywords[i].Weight > keywords[j].Weight
+		}
+		return keywords[i].Term < keywords[j].Term
+	})
+	if limit > 0 && len(keywords) > limit {
+		keywords = keywords[:limit]
+	}
+	if len(keywords) > 0 && keywords[0].Weight > 0 {
+		top := keywords[0].Weight
+		for i := range keywords {
+			keywords[i].Weight /= top
+		}
+	}
+	return keywords
+}
+
+// SuggestTags 从已有标签中为书签推荐标签，按综合置信度降序
+func (idx *Index) SuggestTags(input SuggestInput, tags []db.Tag, limit int) ([]TagSuggestion, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if !idx.ready {
+		return nil, ErrNotReady
+	}
+
+	chosen := make(map[int]struct{}, len(input.TagIDs))
+	for _, id := range input.TagIDs {
+		chosen[id] = struct{}{}
+	}
+
+	tf := TermFrequency(input.Text)
+	keywordScores := idx.keywordScores(tf, tags)
+	similarScores := idx.similarScores(tf, input.Exclude)
+	cooccurScores := idx.cooccurScores(chosen, input.Exclude)
+	domainScores := idx.domainScores(utils.URLDomain(input.URL), input.Exclude)
+
+	suggestions := make([]TagSuggestion, 0)
+	for _, tag := range tags {
+		if _, ok := chosen[tag.ID]; ok {
+			continue
+		}
+		suggestion := TagSuggestion{
+			Tag:          tag,
+			KeywordScore: keywordScores[tag.ID],
+			SimilarScore: similarScores[tag.ID],
+			CooccurScore: cooccurScores[tag.ID],
+			DomainScore:  domainScores[tag.ID],
+		}
+		suggestion.Score = keywordWeight*suggestion.KeywordScore +
+			similarWeight*suggestion.SimilarScore +
+			cooccurWeight*suggestion.CooccurScore +
+			historyWeight*suggestion.DomainScore
+		if suggestion.Score > 0 {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Tag.ID < suggestions[j].Tag.ID
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// keywordScores 标签名与正文关键词的匹配程度：标签名切分后各词的关键词权重的平均值
+func (idx *Index) keywordScores(tf map[string]float64, tags []db.Tag) map[int]float64 {
+	scores := make(map[int]float64)
+	if len(tf) == 0 {
+		return scores
+	}
+
+	weights := make(map[string]float64, suggestKeywords)
+	for _, keyword := range idx.keywords(tf, suggestKeywords) {
+		weights[keyword.Term] = keyword.Weight
+	}
+
+	for _, tag := range tags {
+		var sum float64
+		n := 0
+		for _, token := range utils.Tokenize(tag.Name) {
+			if isStopword(token) {
+				continue
+			}
+			sum += weights[token]
+			n++
+		}
+		if n > 0 && sum > 0 {
+			scores[tag.ID] = sum / float64(n)
+		}
+	}
+	return scores
+}
+
+// similarScores 正文最相似的若干书签对其标签按相似度加权投票
+func (idx *Index) similarScores(tf map[string]float64, exclude int) map[int]float64 {
+	scores := make(map[int]float64)
+	if len(tf) == 0 {
+		return scores
+	}
+
+	weights := make(map[string]float64, len(tf))
+	var sum float64
+	for term, freq := range tf {
+		w := freq * idx.idf(term)
+		weights[term] = w
+		sum += w * w
+	}
+	if sum == 0 {
+		return scores
+	}
+	norm := math.Sqrt(sum)
+
+	type neighbor struct {
+		doc        *document
+		similarity float64
+	}
+	neighbors := make([]neighbor, 0)
+	for _, doc := range idx.docs {
+		if doc.id == exclude || len(doc.tags) == 0 {
+			continue
+		}
+		var dot float64
+		for term, freq := range doc.tf {
+			if w, ok := weights[term]; ok {
+				dot += w * freq * idx.idf(term)
+			}
+		}
+		if dot > 0 {
+			neighbors = append(neighbors, neighbor{doc: doc, similarity: dot / (norm * idx.norm(doc))})
+		}
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		return neighbors[i].similarity > neighbors[j].similarity
+	})
+	if len(neighbors) > suggestNeighbor {
+		neighbors = neighbors[:suggestNeighbor]
+	}
+
+	var total float64
+	for _, n := range neighbors {
+		total += n.similarity
+		for tagID := range n.doc.tags {
+			scores[tagID] += n.similarity
+		}
+	}
+	for tagID := range scores {
+		scores[tagID] /= total
+	}
+	return scores
+}
+
+// cooccurScores 与已选标签共同出现的条件概率，取各已选标签中的最大值
+func (idx *Index) cooccurScores(chosen map[int]struct{}, exclude int) map[int]float64 {
+	scores := make(map[int]float64)
+	if len(chosen) == 0 {
+		return scores
+	}
+
+	chosenCount := make(map[int]int, len(chosen))
+	pairCount := make(map[[2]int]int)
+	for _, doc := range idx.docs {
+		if doc.id == exclude {
+			continue
+		}
+		for c := range doc.tags {
+			if _, ok := chosen[c]; !ok {
+				continue
+			}
+			chosenCount[c]++
+			for t := range doc.tags {
+				if t != c {
+					pairCount[[2]int{c, t}]++
+				}
+			}
+		}
+	}
+
+	for pair, count := range pairCount {
+		score := float64(count) / float64(chosenCount[pair[0]])
+		if score > scores[pair[1]] {
+			scores[pair[1]] = score
+		}
+	}
+	return scores
+}
+
+// domainScores 同域名书签中使用各标签的比例（分母加 1 平滑，避免单个样本得满分）
+func (idx *Index) domainScores(domain string, exclude int) map[int]float64 {
+	scores := make(map[int]float64)
+	if domain == "" {
+		return scores
+	}
+
+	total := 0
+	for _, doc := range idx.docs {
+		if doc.id == exclude || doc.domain != domain {
+			continue
+		}
+		total++
+		for tagID := range doc.tags {
+			scores[tagID]++
+		}
+	}
+	for tagID := range scores {
+		scores[tagID] /= float64(total + 1)
+	}
+	return scores
+}
@@ -9,10 +9,18 @@ import (
 
 // Config 配置结构体
 type Config struct {
-	Server ServerConfig `yaml:"server"`
-	Log    LogConfig    `yaml:"log"`
-	MySQL  MySQLConfig  `yaml:"mysql"`
-	JWT    JWTConfig    `yaml:"jwt"`
+	Server       ServerConfig       `yaml:"server"`
+	Log          LogConfig          `yaml:"log"`
+	MySQL        MySQLConfig        `yaml:"mysql"`
+	JWT          JWTConfig          `yaml:"jwt"`
+	Captcha      CaptchaConfig      `yaml:"captcha"`
+	TOTP         TOTPConfig         `yaml:"totp"`
+	OIDC         OIDCConfig         `yaml:"oidc"`
+	Trash        TrashConfig        `yaml:"trash"`
+	URL          URLConfig          `yaml:"url"`
+	Similar      SimilarConfig      `yaml:"similar"`
+	TagAuto      TagAutoConfig      `yaml:"tag_auto"`
+	Subscription SubscriptionConfig `yaml:"subscription"`
 }
 
 // ServerConfig 服务器配置
@@ -28,33 +36,157 @@ type LogConfig struct {
 
 // MySQLConfig MySQL配置
 type MySQLConfig struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
-	Database string `yaml:"database"`
+	Host        string `yaml:"host"`
+	Port        int    `yaml:"port"`
+	User        string `yaml:"user"`
+	Password    string `yaml:"password" secret:"true"`
+	Database    string `yaml:"database"`
+	AutoMigrate bool   `yaml:"auto_migrate"` // 启动服务时自动执行未执行的数据库迁移
 }
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret string `yaml:"secret"`
+	Secret string `yaml:"secret" secret:"true"`
 	Exp    string `yaml:"exp"`
 }
 
+// CaptchaConfig 图形验证码配置
+type CaptchaConfig struct {
+	Store  string `yaml:"store"`  // 验证码存储: memory(单实例), db(多实例共享)
+	Length int    `yaml:"length"` // 验证码位数
+	Width  int    `yaml:"width"`  // 图片宽度
+	Height int    `yaml:"height"` // 图片高度
+	Exp    string `yaml:"exp"`    // 有效期, 如 5m
+	Audio  bool   `yaml:"audio"`  // 是否允许获取语音验证码
+	Lang   string `yaml:"lang"`   // 语音验证码语言: en, ja, ru, zh, pt
+}
+
+// TOTPConfig 两步验证配置
+type TOTPConfig struct {
+	Issuer     string `yaml:"issuer"`       // 认证器 App 中显示的发行方名称
+	PreAuthExp string `yaml:"pre_auth_exp"` // 预认证 token 有效期, 如 5m
+}
+
+// OIDCConfig OpenID Connect 单点登录配置
+type OIDCConfig struct {
+	Enabled          bool              `yaml:"enabled"`
+	Issuer           string            `yaml:"issuer"` // 身份提供方地址，用于 discovery
+	ClientID         string            `yaml:"client_id"`
+	ClientSecret     string            `yaml:"client_secret" secret:"true"` // 公共客户端（仅 PKCE）可留空
+	RedirectURL      string            `yaml:"redirect_url"`                // 回调地址，指向 /api/v1/auth/oidc/callback
+	Scopes           []string          `yaml:"scopes"`                      // 默认 openid profile email
+	UsernameClaim    string            `yaml:"username_claim"`              // 用作本地用户名的 claim，默认 preferred_username
+	RoleClaim        string            `yaml:"role_claim"`                  // 用于角色映射的 claim，如 groups，为空则不映射
+	RoleMapping      map[string]string `yaml:"role_mapping"`                // claim 值 -> 本地角色(admin, user)
+	DefaultRole      string            `yaml:"default_role"`                // 未匹配到映射时的角色，默认 user
+	AutoCreate       bool              `yaml:"auto_create"`                 // 首次登录自动创建本地用户
+	FrontendRedirect string            `yaml:"frontend_redirect"`           // 登录成功后跳转的前端地址，token 放在 #token= 中；为空则返回 JSON
+}
+
+// TrashConfig 回收站配置
+type TrashConfig struct {
+	Retention     string `yaml:"retention"`      // 保留时长，超过后自动彻底删除, 如 720h；为空或 0 表示不自动清理
+	PurgeInterval string `yaml:"purge_interval"` // 自动清理检查间隔, 如 1h
+}
+
+// URLConfig URL 规范化配置（用于重复书签检测）
+type URLConfig struct {
+	TrackingParams []string          `yaml:"tracking_params"` // 额外移除的全局追踪参数，支持通配符
+	DomainRules    []URLDomainConfig `yaml:"domain_rules"`    // 按域名移除的追踪参数
+}
+
+// URLDomainConfig 按域名配置的追踪参数
+type URLDomainConfig struct {
+	Domain string   `yaml:"domain"` // 域名，支持通配符，如 *.taobao.com
+	Params []string `yaml:"params"` // 需要移除的参数，支持通配符，如 share_*
+}
+
+// SimilarConfig 相似内容检测配置
+type SimilarConfig struct {
+	Threshold float64 `yaml:"threshold"` // 相似度阈值（0~1），达到阈值的书签视为内容相似，默认 0.9
+}
+
+// TagAutoConfig 自动标签配置
+type TagAutoConfig struct {
+	Threshold float64 `yaml:"threshold"` // 自动添加标签的置信度阈值（0~1）
+	MaxTags   int     `yaml:"max_tags"`  // 每个书签最多自动添加的标签数
+}
+
+// SubscriptionConfig 订阅源拉取配置
+type SubscriptionConfig struct {
+	Interval string `yaml:"interval"`  // 拉取间隔, 如 30m；为空或 0 表示不自动拉取
+	MaxItems int    `yaml:"max_items"` // 每个订阅源每次最多创建的书签数（取最新的条目），默认 20
+}
+
 var GlobalConfig *Config
 
-// LoadConfig 加载配置文件
-func LoadConfig(configPath string) (*Config, error) {
+// DefaultConfigPath 默认配置文件路径，可通过 -config 参数或环境变量 BKKMS_CONFIG 修改
+const DefaultConfigPath = "config/config.yaml"
+
+// DefaultConfig 默认配置，配置文件、环境变量与命令行参数在此基础上覆盖
+func DefaultConfig() *Config {
+	return &Config{
+		Server: ServerConfig{Port: 8081, GinMode: "debug"},
+		Log:    LogConfig{Level: "info"},
+		MySQL: MySQLConfig{
+			Host:        "127.0.0.1",
+			Port:        3306,
+			User:        "root",
+			Database:    "bk_kms",
+			AutoMigrate: true,
+		},
+		JWT: JWTConfig{Exp: "24h"},
+		Captcha: CaptchaConfig{
+			Store:  "memory",
+			Length: 4,
+			Width:  120,
+			Height: 40,
+			Exp:    "5m",
+			Lang:   "zh",
+		},
+		TOTP: TOTPConfig{Issuer: "bk_kms", PreAuthExp: "5m"},
+		OIDC: OIDCConfig{
+			Scopes:        []string{"openid", "profile", "email"},
+			UsernameClaim: "preferred_username",
+			DefaultRole:   "user",
+		},
+		Trash:        TrashConfig{PurgeInterval: "1h"},
+		Similar:      SimilarConfig{Threshold: 0.9},
+		TagAuto:      TagAutoConfig{Threshold: 0.5, MaxTags: 3},
+		Subscription: SubscriptionConfig{MaxItems: 20},
+	}
+}
+
+// LoadConfig 加载配置，优先级从低到高：默认配置、配置文件、环境变量（BKKMS_ 开头）、命令行参数 sets（key=value）
+// configPath 为空时使用环境变量 BKKMS_CONFIG 或默认路径，默认路径的配置文件不存在时只使用其余配置
+func LoadConfig(configPath string, sets []string) (*Config, error) {
+	config := DefaultConfig()
+
+	if configPath == "" {
+		configPath = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	optional := configPath == ""
+	if optional {
+		configPath = DefaultConfigPath
+	}
 	data, err := os.ReadFile(configPath)
-	if err != nil {
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("解析配置文件失败: %w", err)
+		}
+	case optional && os.IsNotExist(err):
+	default:
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	if err := config.applyEnv(os.Environ()); err != nil {
+		return nil, err
+	}
+	if err := config.applySets(sets); err != nil {
+		return nil, err
 	}
 
-	GlobalConfig = &config
-	return &config, nil
+	GlobalConfig = config
+	return config, nil
 }
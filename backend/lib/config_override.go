@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix 配置环境变量前缀，配置项的 yaml 路径转为大写并以下划线连接，如 mysql.password -> BKKMS_MYSQL_PASSWORD
+const EnvPrefix = "BKKMS_"
+
+// configField 可覆盖的配置项
+type configField struct {
+	path   string // yaml 路径，如 mysql.password
+	value  reflect.Value
+	secret bool // 是否为密钥，输出配置时隐藏
+}
+
+// fields 按 yaml 路径展开全部配置项（嵌套结构体展开为其字段）
+func (c *Config) fields() []configField {
+	var fields []configField
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			path := prefix + name
+			if t.Field(i).Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			fields = append(fields, configField{
+				path:   path,
+				value:  v.Field(i),
+				secret: t.Field(i).Tag.Get("secret") == "true",
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return fields
+}
+
+// envName 配置项对应的环境变量名
+func envName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// applyEnv 使用环境变量（KEY=VALUE 列表）覆盖配置
+func (c *Config) applyEnv(environ []string) error {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if key, value, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(key, EnvPrefix) {
+			env[key] = value
+		}
+	}
+	for _, field := range c.fields() {
+		value, ok := env[envName(field.path)]
+		if !ok {
+			continue
+		}
+		if err := setField(field.value, value); err != nil {
+			return fmt.Errorf("环境变量 %s 错误: %w", envName(field.path), err)
+		}
+	}
+	return nil
+}
+
+// applySets 使用命令行参数（key=value，key 为 yaml 路径，如 mysql.password=xxx）覆盖配置
+func (c *Config) applySets(sets []string) error {
+	if len(sets) == 0 {
+		return nil
+	}
+	byPath := make(map[string]configField)
+	for _, field := range c.fields() {
+		byPath[strings.ToLower(field.path)] = field
+	}
+	for _, set := range sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return fmt.Errorf("参数 -set %s 错误: 格式应为 key=value", set)
+		}
+		field, ok := byPath[strings.ToLower(strings.TrimSpace(key))]
+		if !ok {
+			return fmt.Errorf("参数 -set %s 错误: 未知的配置项 %s", set, key)
+		}
+		if err := setField(field.value, value); err != nil {
+			return fmt.Errorf("参数 -set %s 错误: %w", set, err)
+		}
+	}
+	return nil
+}
+
+// setField 按字段类型解析并设置配置项，列表使用英文逗号分隔
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("应为整数: %s", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("应为数字: %s", value)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("应为 true 或 false: %s", value)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("该配置项只能在配置文件中设置")
+		}
+		items := make([]string, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("该配置项只能在配置文件中设置")
+	}
+	return nil
+}
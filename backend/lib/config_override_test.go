@@ -0,0 +1,255 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfigFile 在临时目录中写入配置文件，返回文件路径
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  port: 9000
+mysql:
+  host: file-host
+  port: 3307
+  password: file-password
+jwt:
+  secret: file-secret
+`)
+	t.Setenv("BKKMS_MYSQL_HOST", "env-host")
+	t.Setenv("BKKMS_MYSQL_PORT", "3308")
+	t.Setenv("BKKMS_JWT_SECRET", "env-secret")
+
+	config, err := LoadConfig(path, []string{"mysql.port=3309", "MySQL.Password=set-password"})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"默认配置", config.Log.Level, "info"},
+		{"默认开启自动迁移", config.MySQL.AutoMigrate, true},
+		{"配置文件覆盖默认配置", config.Server.Port, 9000},
+		{"环境变量覆盖配置文件", config.MySQL.Host, "env-host"},
+		{"环境变量覆盖配置文件（密钥）", config.JWT.Secret, "env-secret"},
+		{"-set 覆盖环境变量", config.MySQL.Port, 3309},
+		{"-set 的配置项不区分大小写", config.MySQL.Password, "set-password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Run("指定的配置文件不存在", func(t *testing.T) {
+		if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"), nil); err == nil {
+			t.Error("LoadConfig() error = nil, want error")
+		}
+	})
+	t.Run("通过 BKKMS_CONFIG 指定配置文件", func(t *testing.T) {
+		t.Setenv("BKKMS_CONFIG", writeConfigFile(t, "log:\n  level: debug\n"))
+		config, err := LoadConfig("", nil)
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if config.Log.Level != "debug" {
+			t.Errorf("log.level = %q, want debug", config.Log.Level)
+		}
+	})
+	t.Run("配置文件格式错误", func(t *testing.T) {
+		if _, err := LoadConfig(writeConfigFile(t, "server: [1, 2"), nil); err == nil {
+			t.Error("LoadConfig() error = nil, want error")
+		}
+	})
+}
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		environ []string
+		check   func(c *Config) bool
+		wantErr string
+	}{
+		{
+			name:    "整数",
+			environ: []string{"BKKMS_SERVER_PORT=9090"},
+			check:   func(c *Config) bool { return c.Server.Port == 9090 },
+		},
+		{
+			name:    "小数",
+			environ: []string{"BKKMS_SIMILAR_THRESHOLD=0.75"},
+			check:   func(c *Config) bool { return c.Similar.Threshold == 0.75 },
+		},
+		{
+			name:    "布尔值",
+			environ: []string{"BKKMS_OIDC_ENABLED=true"},
+			check:   func(c *Config) bool { return c.OIDC.Enabled },
+		},
+		{
+			name:    "列表使用英文逗号分隔",
+			environ: []string{"BKKMS_OIDC_SCOPES=openid, email,,"},
+			check:   func(c *Config) bool { return reflect.DeepEqual(c.OIDC.Scopes, []string{"openid", "email"}) },
+		},
+		{
+			name:    "值可以包含等号",
+			environ: []string{"BKKMS_MYSQL_PASSWORD=a=b"},
+			check:   func(c *Config) bool { return c.MySQL.Password == "a=b" },
+		},
+		{
+			name:    "忽略没有前缀与未知的环境变量",
+			environ: []string{"SERVER_PORT=1", "BKKMS_UNKNOWN=1"},
+			check:   func(c *Config) bool { return c.Server.Port == DefaultConfig().Server.Port },
+		},
+		{
+			name:    "整数格式错误",
+			environ: []string{"BKKMS_SERVER_PORT=abc"},
+			wantErr: "环境变量 BKKMS_SERVER_PORT 错误: 应为整数",
+		},
+		{
+			name:    "布尔值格式错误",
+			environ: []string{"BKKMS_MYSQL_AUTO_MIGRATE=yes"},
+			wantErr: "环境变量 BKKMS_MYSQL_AUTO_MIGRATE 错误: 应为 true 或 false",
+		},
+		{
+			name:    "小数格式错误",
+			environ: []string{"BKKMS_TAG_AUTO_THRESHOLD=high"},
+			wantErr: "环境变量 BKKMS_TAG_AUTO_THRESHOLD 错误: 应为数字",
+		},
+		{
+			name:    "结构体列表只能在配置文件中设置",
+			environ: []string{"BKKMS_URL_DOMAIN_RULES=example.com"},
+			wantErr: "只能在配置文件中设置",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			err := config.applyEnv(tt.environ)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyEnv() error = %v", err)
+			}
+			if !tt.check(config) {
+				t.Errorf("applyEnv(%v) did not apply", tt.environ)
+			}
+		})
+	}
+}
+
+func TestApplySets(t *testing.T) {
+	tests := []struct {
+		name    string
+		sets    []string
+		check   func(c *Config) bool
+		wantErr string
+	}{
+		{
+			name:  "多个参数按顺序覆盖",
+			sets:  []string{"log.level=warn", "log.level=error", "captcha.length=6"},
+			check: func(c *Config) bool { return c.Log.Level == "error" && c.Captcha.Length == 6 },
+		},
+		{
+			name:  "值可以为空",
+			sets:  []string{"jwt.exp="},
+			check: func(c *Config) bool { return c.JWT.Exp == "" },
+		},
+		{
+			name:    "未知的配置项",
+			sets:    []string{"mysql.pasword=x"},
+			wantErr: "未知的配置项 mysql.pasword",
+		},
+		{
+			name:    "结构体不能作为配置项",
+			sets:    []string{"mysql=x"},
+			wantErr: "未知的配置项 mysql",
+		},
+		{
+			name:    "缺少等号",
+			sets:    []string{"mysql.password"},
+			wantErr: "格式应为 key=value",
+		},
+		{
+			name:    "类型错误",
+			sets:    []string{"captcha.length=four"},
+			wantErr: "参数 -set captcha.length=four 错误: 应为整数",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			err := config.applySets(tt.sets)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applySets() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applySets() error = %v", err)
+			}
+			if !tt.check(config) {
+				t.Errorf("applySets(%v) did not apply", tt.sets)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	config := DefaultConfig()
+	config.MySQL.Password = "mysql-password"
+	config.JWT.Secret = "jwt-secret"
+	config.OIDC.ClientSecret = ""
+
+	redacted := config.Redacted()
+	for _, secret := range []string{"mysql-password", "jwt-secret"} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("Redacted() contains secret %q:\n%s", secret, redacted)
+		}
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"隐藏 mysql.password", "password: '******'"},
+		{"隐藏 jwt.secret", "secret: '******'"},
+		{"未设置的密钥保持为空", `client_secret: ""`},
+		{"其余配置项原样输出", "host: 127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(redacted, tt.want) {
+				t.Errorf("Redacted() does not contain %q:\n%s", tt.want, redacted)
+			}
+		})
+	}
+
+	// 输出配置不能修改原配置
+	if config.MySQL.Password != "mysql-password" || config.JWT.Secret != "jwt-secret" {
+		t.Errorf("Redacted() modified the config: password=%q secret=%q", config.MySQL.Password, config.JWT.Secret)
+	}
+}
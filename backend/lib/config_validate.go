@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// minReleaseSecretLength release 模式下 jwt.secret 的最小长度
+const minReleaseSecretLength = 32
+
+// Validate 校验配置，返回全部错误（每行一个配置项）
+func (c *Config) Validate() error {
+	var errs []string
+	add := func(path, format string, args ...interface{}) {
+		errs = append(errs, path+": "+fmt.Sprintf(format, args...))
+	}
+	oneOf := func(path, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			add(path, "不支持 %q，可选值: %s", value, strings.Join(allowed, ", "))
+		}
+	}
+	duration := func(path, value string) {
+		if value == "" {
+			return
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			add(path, "时长格式错误 %q，如 30m、24h", value)
+		}
+	}
+	ratio := func(path string, value float64) {
+		if value < 0 || value > 1 {
+			add(path, "应在 0~1 之间: %v", value)
+		}
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		add("server.port", "端口应在 1~65535 之间: %d", c.Server.Port)
+	}
+	oneOf("server.GIN_MODE", c.Server.GinMode, "debug", "release", "test")
+	oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+
+	if c.MySQL.Host == "" {
+		add("mysql.host", "不能为空")
+	}
+	if c.MySQL.Port < 1 || c.MySQL.Port > 65535 {
+		add("mysql.port", "端口应在 1~65535 之间: %d", c.MySQL.Port)
+	}
+	if c.MySQL.User == "" {
+		add("mysql.user", "不能为空")
+	}
+	if c.MySQL.Database == "" {
+		add("mysql.database", "不能为空")
+	}
+
+	if c.JWT.Secret == "" {
+		add("jwt.secret", "不能为空，可通过环境变量 %s 设置", envName("jwt.secret"))
+	} else if c.Server.GinMode == "release" {
+		if len(c.JWT.Secret) < minReleaseSecretLength {
+			add("jwt.secret", "release 模式下长度不能少于 %d 个字符", minReleaseSecretLength)
+		} else if strings.Trim(c.JWT.Secret, c.JWT.Secret[:1]) == "" {
+			add("jwt.secret", "release 模式下不能使用由同一个字符重复组成的弱密钥")
+		}
+	}
+	duration("jwt.exp", c.JWT.Exp)
+
+	oneOf("captcha.store", c.Captcha.Store, "memory", "db")
+	oneOf("captcha.lang", c.Captcha.Lang, "en", "ja", "ru", "zh", "pt")
+	if c.Captcha.Length < 1 {
+		add("captcha.length", "应大于 0: %d", c.Captcha.Length)
+	}
+	if c.Captcha.Width < 1 || c.Captcha.Height < 1 {
+		add("captcha.width, captcha.height", "应大于 0: %dx%d", c.Captcha.Width, c.Captcha.Height)
+	}
+	duration("captcha.exp", c.Captcha.Exp)
+	duration("totp.pre_auth_exp", c.TOTP.PreAuthExp)
+
+	if c.OIDC.Enabled {
+		if c.OIDC.Issuer == "" {
+			add("oidc.issuer", "开启单点登录时不能为空")
+		}
+		if c.OIDC.ClientID == "" {
+			add("oidc.client_id", "开启单点登录时不能为空")
+		}
+		if c.OIDC.RedirectURL == "" {
+			add("oidc.redirect_url", "开启单点登录时不能为空")
+		}
+		oneOf("oidc.default_role", c.OIDC.DefaultRole, "admin", "user")
+	}
+
+	duration("trash.retention", c.Trash.Retention)
+	duration("trash.purge_interval", c.Trash.PurgeInterval)
+	ratio("similar.threshold", c.Similar.Threshold)
+	ratio("tag_auto.threshold", c.TagAuto.Threshold)
+	if c.TagAuto.MaxTags < 0 {
+		add("tag_auto.max_tags", "不能小于 0: %d", c.TagAuto.MaxTags)
+	}
+	duration("subscription.interval", c.Subscription.Interval)
+	if c.Subscription.MaxItems < 0 {
+		add("subscription.max_items", "不能小于 0: %d", c.Subscription.MaxItems)
+	}
+
+	if len(errs) > 0 {
+		return errors.New("配置错误:\n  " + strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+// Redacted 以 yaml 格式输出生效的配置，密钥替换为 ******
+func (c *Config) Redacted() string {
+	redacted := *c
+	for _, field := range redacted.fields() {
+		if field.secret && field.value.String() != "" {
+			field.value.SetString("******")
+		}
+	}
+	data, err := yaml.Marshal(&redacted)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
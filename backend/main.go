@@ -1,20 +1,70 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/dchest/captcha"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/logger"
 
+	"bk_kms/index"
 	"bk_kms/lib"
+	"bk_kms/migrate"
+	"bk_kms/repo"
 	"bk_kms/route"
+	"bk_kms/task"
+	"bk_kms/utils"
 )
 
+// setFlags 可重复的 -set key=value 参数
+type setFlags []string
+
+func (s *setFlags) String() string { return strings.Join(*s, ",") }
+
+func (s *setFlags) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 func main() {
-	// 1. 加载配置文件
-	config, err := lib.LoadConfig("config/config.yaml")
+	fs := flag.NewFlagSet("bk_kms", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	configPath := fs.String("config", "", "配置文件路径")
+	printConfig := fs.Bool("print-config", false, "输出生效的配置（隐藏密钥）后退出")
+	var sets setFlags
+	fs.Var(&sets, "set", "覆盖配置项，如 -set mysql.password=xxx，可重复")
+	fs.Parse(os.Args[1:])
+
+	// 不指定命令时启动 HTTP 服务
+	name, args := "serve", []string(nil)
+	if fs.NArg() > 0 {
+		name, args = fs.Arg(0), fs.Args()[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "bk_kms: 未知命令 %s\n\n", name)
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// 1. 加载并校验配置：默认配置 < 配置文件 < 环境变量 < -set 参数
+	config, err := lib.LoadConfig(*configPath, sets)
 	if err != nil {
-		log.Fatalf("加载配置文件失败: %v", err)
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	if *printConfig {
+		fmt.Print(config.Redacted())
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if *printConfig {
+		return
 	}
 
 	// 2. 初始化日志
@@ -23,9 +73,33 @@ func main() {
 	}
 	defer lib.Logger.Sync()
 
+	// 3. 初始化数据库连接
+	if err := lib.InitDatabase(config); err != nil {
+		lib.Logger.Fatal(fmt.Sprintf("初始化数据库失败: %v", err))
+	}
+
+	// 4. 初始化 URL 规范化规则（书签判重）
+	initURLRules(config)
+
+	if name != "serve" {
+		// 命令行只输出慢查询与错误
+		lib.DB.Logger = lib.DB.Logger.LogMode(logger.Warn)
+	}
+
+	if err := cmd(config, args); err != nil {
+		fmt.Fprintln(os.Stderr, "bk_kms: "+err.Error())
+		lib.Logger.Sync()
+		os.Exit(1)
+	}
+}
+
+// serveCommand 启动 HTTP 服务
+// 用法: bk_kms [serve]
+func serveCommand(config *lib.Config, args []string) error {
 	lib.Logger.Info("项目启动中...")
+	lib.Logger.Info("生效的配置:\n" + config.Redacted())
 
-	// 3. 设置 Gin 运行模式
+	// 1. 设置 Gin 运行模式
 	ginMode := config.Server.GinMode
 	if ginMode == "" {
 		ginMode = gin.DebugMode // 默认为 debug 模式
@@ -33,19 +107,116 @@ func main() {
 	gin.SetMode(ginMode)
 	lib.Logger.Info(fmt.Sprintf("Gin 运行模式: %s", ginMode))
 
-	// 4. 初始化数据库连接
-	if err := lib.InitDatabase(config); err != nil {
-		lib.Logger.Fatal(fmt.Sprintf("初始化数据库失败: %v", err))
+	// 2. 检查数据库版本
+	if err := migrateDatabase(config); err != nil {
+		return err
 	}
 
-	// 5. 初始化路由
+	// 3. 初始化验证码
+	initCaptcha(config)
+
+	// 4. 构建书签索引（后台进行，不阻塞启动，构建期间的书签修改在构建完成后重放）
+	go buildIndex()
+
+	// 5. 启动后台任务
+	startTasks(config)
+
+	// 6. 初始化路由
 	router := route.InitRouter()
 
-	// 6. 启动 HTTP 服务器
+	// 7. 启动 HTTP 服务器
 	addr := fmt.Sprintf(":%d", config.Server.Port)
 	lib.Logger.Info(fmt.Sprintf("HTTP 服务器启动在端口: %d", config.Server.Port))
 
 	if err := router.Run(addr); err != nil {
-		lib.Logger.Fatal(fmt.Sprintf("启动 HTTP 服务器失败: %v", err))
+		return fmt.Errorf("启动 HTTP 服务器失败: %w", err)
+	}
+	return nil
+}
+
+// migrateDatabase 配置 mysql.auto_migrate 时执行未执行的数据库迁移，
+// 否则数据库版本与程序不一致时拒绝启动，避免在缺少表或字段的数据库上运行
+func migrateDatabase(config *lib.Config) error {
+	if !config.MySQL.AutoMigrate {
+		current, err := migrate.Current()
+		if err != nil {
+			return fmt.Errorf("检查数据库版本失败: %w", err)
+		}
+		if current > migrate.Latest() {
+			return fmt.Errorf("数据库版本 %d 高于程序支持的版本 %d，请升级程序", current, migrate.Latest())
+		}
+		pending, err := migrate.Pending()
+		if err != nil {
+			return fmt.Errorf("检查数据库版本失败: %w", err)
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("数据库有 %d 个未执行的迁移，请先运行 bk_kms migrate，或开启 mysql.auto_migrate", len(pending))
+		}
+		return nil
+	}
+
+	ran, err := migrate.Up()
+	for _, m := range ran {
+		lib.Logger.Info(fmt.Sprintf("已执行数据库迁移: %d_%s", m.Version, m.Name))
+	}
+	if err != nil {
+		return fmt.Errorf("数据库迁移失败: %w", err)
+	}
+	return nil
+}
+
+// initCaptcha 根据配置初始化验证码存储
+func initCaptcha(config *lib.Config) {
+	expiration, _ := time.ParseDuration(config.Captcha.Exp)
+	if expiration <= 0 {
+		expiration = 5 * time.Minute
 	}
+
+	var store captcha.Store
+	switch config.Captcha.Store {
+	case "db":
+		store = &repo.CaptchaRepo{Expiration: expiration}
+	case "", "memory":
+		store = utils.NewCaptchaMemoryStore(expiration)
+	default:
+		lib.Logger.Fatal("不支持的验证码存储类型: " + config.Captcha.Store)
+	}
+
+	utils.InitCaptcha(store, utils.CaptchaOptions{
+		Length:     config.Captcha.Length,
+		Width:      config.Captcha.Width,
+		Height:     config.Captcha.Height,
+		Expiration: expiration,
+		Lang:       config.Captcha.Lang,
+	})
+	lib.Logger.Info(fmt.Sprintf("验证码存储: %s, 有效期: %s", config.Captcha.Store, expiration))
+}
+
+// initURLRules 根据配置初始化 URL 规范化规则
+func initURLRules(config *lib.Config) {
+	rules := make([]utils.TrackingRule, 0, len(config.URL.DomainRules))
+	for _, rule := range config.URL.DomainRules {
+		rules = append(rules, utils.TrackingRule{
+			Domain: rule.Domain,
+			Params: rule.Params,
+		})
+	}
+	utils.InitURLRules(config.URL.TrackingParams, rules)
+}
+
+// buildIndex 构建相关书签推荐使用的进程内索引
+func buildIndex() {
+	if err := index.Build(); err != nil {
+		lib.Logger.Error("书签索引构建失败: " + err.Error())
+	}
+}
+
+// startTasks 启动后台定时任务
+func startTasks(config *lib.Config) {
+	retention, _ := time.ParseDuration(config.Trash.Retention)
+	purgeInterval, _ := time.ParseDuration(config.Trash.PurgeInterval)
+	task.StartTrashPurge(retention, purgeInterval)
+
+	subscriptionInterval, _ := time.ParseDuration(config.Subscription.Interval)
+	task.StartSubscriptionPoll(subscriptionInterval)
 }
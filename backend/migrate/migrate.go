@@ -0,0 +1,297 @@
+package migrate
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+)
+
+// sqlFiles 迁移文件，命名为 <版本号>_<名称>.up.sql 与 <版本号>_<名称>.down.sql
+// 全部表与索引只在迁移文件中定义，新增迁移使用更大的版本号，已发布的迁移不能修改
+//
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// createVersionTable 版本表在执行任何迁移之前创建
+const createVersionTable = "CREATE TABLE IF NOT EXISTS `schema_version` (" +
+	"`version` int NOT NULL COMMENT '迁移版本号', " +
+	"`name` varchar(100) NOT NULL COMMENT '迁移名称', " +
+	"`applied_at` datetime(3) NOT NULL COMMENT '执行时间', " +
+	"PRIMARY KEY (`version`) USING BTREE" +
+	") ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '数据库版本表'"
+
+// Migration 数据库迁移，按版本号从小到大执行，已执行的版本记录在 schema_version 表
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string // 升级语句
+	Down    []string // 回滚语句
+}
+
+// dropsDataPattern 会删除数据的回滚语句
+var dropsDataPattern = regexp.MustCompile(`(?i)\bDROP\s+(TABLE|COLUMN)\b`)
+
+// DropsData 回滚语句是否会删除数据（删除表或字段）
+func (m Migration) DropsData() bool {
+	for _, statement := range m.Down {
+		if dropsDataPattern.MatchString(statement) {
+			return true
+		}
+	}
+	return false
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Migration
+	AppliedAt *time.Time // 执行时间，为空表示未执行
+}
+
+// migrations 全部迁移，按版本号排序
+var migrations = mustLoad()
+
+// mustLoad 读取嵌入的迁移文件，文件不完整属于程序错误
+func mustLoad() []Migration {
+	migrations, err := load(sqlFiles)
+	if err != nil {
+		panic("读取迁移文件失败: " + err.Error())
+	}
+	return migrations
+}
+
+func load(files fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(files, "sql/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, name := range names {
+		base := path.Base(name)
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("迁移文件名应以 .up.sql 或 .down.sql 结尾: %s", base)
+		}
+		versionStr, migrationName, ok := strings.Cut(strings.TrimSuffix(base, "."+direction+".sql"), "_")
+		version, err := strconv.Atoi(versionStr)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("迁移文件名应为 <版本号>_<名称>: %s", base)
+		}
+
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: migrationName}
+			byVersion[version] = m
+		}
+		if m.Name != migrationName {
+			return nil, fmt.Errorf("迁移 %d 的名称不一致: %s, %s", version, m.Name, migrationName)
+		}
+		if direction == "up" {
+			m.Up = splitStatements(string(data))
+		} else {
+			m.Down = splitStatements(string(data))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if len(m.Up) == 0 || len(m.Down) == 0 {
+			return nil, fmt.Errorf("迁移 %d_%s 缺少 up 或 down 语句", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("没有迁移文件")
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// splitStatements 按行尾的分号拆分 SQL 语句，忽略空行与 -- 注释行
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// Latest 程序包含的最新迁移版本号
+func Latest() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// applied 查询已执行的迁移，版本号 -> 执行记录
+func applied() (map[int]db.SchemaVersion, error) {
+	if err := lib.DB.Exec(createVersionTable).Error; err != nil {
+		return nil, fmt.Errorf("创建版本表失败: %w", err)
+	}
+	var versions []db.SchemaVersion
+	if err := lib.DB.Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int]db.SchemaVersion, len(versions))
+	for _, version := range versions {
+		result[version.Version] = version
+	}
+	return result, nil
+}
+
+// Current 数据库当前的版本号（已执行的最大版本），未执行任何迁移时为 0
+func Current() (int, error) {
+	done, err := applied()
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for version := range done {
+		current = max(current, version)
+	}
+	return current, nil
+}
+
+// Pending 未执行的迁移
+func Pending() ([]Migration, error) {
+	done, err := applied()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if _, ok := done[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up 依次执行未执行的迁移，返回本次执行的迁移
+// MySQL 的 DDL 不能回滚，某个迁移失败时停止，之前的迁移保持已执行，修复后重新执行即可
+func Up() ([]Migration, error) {
+	current, err := Current()
+	if err != nil {
+		return nil, err
+	}
+	if current > Latest() {
+		return nil, fmt.Errorf("数据库版本 %d 高于程序支持的版本 %d，请升级程序", current, Latest())
+	}
+	pending, err := Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, m := range pending {
+		if err := execStatements(m.Up); err != nil {
+			return ran, fmt.Errorf("执行迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+		}
+		if err := lib.DB.Create(&db.SchemaVersion{
+			Version:   m.Version,
+			Name:      m.Name,
+			AppliedAt: time.Now(),
+		}).Error; err != nil {
+			return ran, fmt.Errorf("记录迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+// Down 按版本号从大到小回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+// 回滚会删除数据（删除表或字段）时需要 force，否则不回滚任何迁移
+func Down(steps int, force bool) ([]Migration, error) {
+	done, err := applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []Migration
+	for i := len(migrations) - 1; i >= 0 && len(targets) < steps; i-- {
+		if _, ok := done[migrations[i].Version]; ok {
+			targets = append(targets, migrations[i])
+		}
+	}
+	if !force {
+		for _, m := range targets {
+			if m.DropsData() {
+				return nil, fmt.Errorf("回滚迁移 %d_%s 会删除数据，确认后使用 -force 执行", m.Version, m.Name)
+			}
+		}
+	}
+
+	var rolledBack []Migration
+	for _, m := range targets {
+		if err := execStatements(m.Down); err != nil {
+			return rolledBack, fmt.Errorf("回滚迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+		}
+		if err := lib.DB.Delete(&db.SchemaVersion{}, m.Version).Error; err != nil {
+			return rolledBack, fmt.Errorf("删除迁移记录 %d_%s 失败: %w", m.Version, m.Name, err)
+		}
+		rolledBack = append(rolledBack, m)
+	}
+	return rolledBack, nil
+}
+
+// execStatements 在同一个数据库连接上依次执行语句（语句之间可能使用会话变量）
+func execStatements(statements []string) error {
+	return lib.DB.Connection(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// List 查询全部迁移的执行状态
+func List() ([]Status, error) {
+	done, err := applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		status := Status{Migration: m}
+		if version, ok := done[m.Version]; ok {
+			status.AppliedAt = &version.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
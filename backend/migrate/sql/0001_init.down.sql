@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS `audit_log`;
+DROP TABLE IF EXISTS `bookmark`;
+DROP TABLE IF EXISTS `bookmark_note`;
+DROP TABLE IF EXISTS `bookmark_state`;
+DROP TABLE IF EXISTS `bookmark_tag`;
+DROP TABLE IF EXISTS `captcha`;
+DROP TABLE IF EXISTS `collection`;
+DROP TABLE IF EXISTS `collection_bookmark`;
+DROP TABLE IF EXISTS `highlight`;
+DROP TABLE IF EXISTS `personal_token`;
+DROP TABLE IF EXISTS `saved_search`;
+DROP TABLE IF EXISTS `share`;
+DROP TABLE IF EXISTS `subscription`;
+DROP TABLE IF EXISTS `tag`;
+DROP TABLE IF EXISTS `tag_rule`;
+DROP TABLE IF EXISTS `tag_rule_tag`;
+DROP TABLE IF EXISTS `user`;
+DROP TABLE IF EXISTS `user_identity`;
+DROP TABLE IF EXISTS `user_recovery_code`;
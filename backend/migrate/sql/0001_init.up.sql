@@ -0,0 +1,401 @@
+-- 初始表结构，表已存在（旧版本通过 docs/db.sql 或 AutoMigrate 创建）时跳过，旧版本的表在文件末尾补充缺少的字段与索引
+
+-- audit_log
+CREATE TABLE IF NOT EXISTS `audit_log` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL DEFAULT 0 COMMENT '操作用户ID,0:未登录',
+  `username` varchar(250) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '操作用户名',
+  `action` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '操作类型',
+  `entity_type` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '实体类型',
+  `entity_ids` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '实体ID列表,英文逗号分隔',
+  `diff` mediumtext CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '变更内容(JSON),字段 -> {before, after}',
+  `ip` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '客户端IP',
+  `user_agent` varchar(512) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '客户端User-Agent',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_user_id`(`user_id` ASC) USING BTREE,
+  INDEX `idx_action`(`action` ASC) USING BTREE,
+  INDEX `idx_entity_type`(`entity_type` ASC) USING BTREE,
+  INDEX `idx_created_at`(`created_at` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '审计日志表' ROW_FORMAT = Dynamic;
+
+-- bookmark
+CREATE TABLE IF NOT EXISTS `bookmark` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `url` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网址地址',
+  `canonical_url` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '规范化网址(用于判重)',
+  `title` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网址标题',
+  `excerpt` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网站内容节选',
+  `author` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '作者',
+  `content` mediumtext CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网站文本内容(去掉html标签)',
+  `html` mediumtext CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网站原始内容',
+  `folder` varchar(1000) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '导入时所在的书签文件夹路径',
+  `simhash` bigint UNSIGNED NOT NULL DEFAULT 0 COMMENT '正文SimHash指纹(用于相似内容检测),0:无',
+  `is_archive` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否已存档,0:否，1:是',
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '移入回收站时间,为空表示未删除',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `bookmark_url_UNIQUE`(`url`(255) ASC) USING BTREE,
+  INDEX `idx_canonical_url`(`canonical_url`(255) ASC) USING BTREE,
+  INDEX `idx_created_at`(`created_at` ASC) USING BTREE,
+  INDEX `idx_modified_at`(`updated_at` ASC) USING BTREE,
+  INDEX `idx_deleted_at`(`deleted_at` ASC) USING BTREE,
+  FULLTEXT INDEX `ft_all_zh`(`url`, `title`, `excerpt`, `content`) WITH PARSER `ngram`
+) ENGINE = InnoDB AUTO_INCREMENT = 1341 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '书签表' ROW_FORMAT = Dynamic;
+
+-- bookmark_note
+CREATE TABLE IF NOT EXISTS `bookmark_note` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL COMMENT '创建者',
+  `bookmark_id` int NOT NULL,
+  `content` mediumtext CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '笔记内容(Markdown)',
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `bookmark_note_user_id_FK`(`user_id` ASC) USING BTREE,
+  INDEX `bookmark_note_bookmark_id_FK`(`bookmark_id` ASC) USING BTREE,
+  FULLTEXT INDEX `ft_note_zh`(`content`) WITH PARSER `ngram`
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '书签笔记表' ROW_FORMAT = Dynamic;
+
+-- bookmark_state
+CREATE TABLE IF NOT EXISTS `bookmark_state` (
+  `user_id` int NOT NULL,
+  `bookmark_id` int NOT NULL,
+  `state` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT 'unread' COMMENT '阅读状态,unread/reading/read/archived',
+  `favorite` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否收藏(星标),0:否，1:是',
+  `progress` int NOT NULL DEFAULT 0 COMMENT '阅读进度百分比,0-100',
+  `last_opened_at` datetime(3) NULL DEFAULT NULL COMMENT '最近打开时间',
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`user_id`, `bookmark_id`) USING BTREE,
+  INDEX `bookmark_state_bookmark_id_FK`(`bookmark_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '用户的书签阅读状态表' ROW_FORMAT = Dynamic;
+
+-- bookmark_tag
+CREATE TABLE IF NOT EXISTS `bookmark_tag` (
+  `bookmark_id` int NOT NULL,
+  `tag_id` int NOT NULL,
+  PRIMARY KEY (`bookmark_id`, `tag_id`) USING BTREE,
+  INDEX `bookmark_tag_bookmark_id_FK`(`bookmark_id` ASC) USING BTREE,
+  INDEX `bookmark_tag_tag_id_FK`(`tag_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = 'bookmark与tag关联中间表' ROW_FORMAT = Dynamic;
+
+-- captcha
+CREATE TABLE IF NOT EXISTS `captcha` (
+  `id` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '验证码ID',
+  `digits` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '验证码数字',
+  `expires_at` datetime(3) NOT NULL COMMENT '过期时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_expires_at`(`expires_at` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '验证码表' ROW_FORMAT = Dynamic;
+
+-- collection
+CREATE TABLE IF NOT EXISTS `collection` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `parent_id` int NOT NULL DEFAULT 0 COMMENT '上级收藏夹ID,0:顶级',
+  `name` varchar(250) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '名称',
+  `position` int NOT NULL DEFAULT 0 COMMENT '同级收藏夹中的排序,从小到大',
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `collection_parent_name_UNIQUE`(`parent_id` ASC, `name` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '收藏夹表' ROW_FORMAT = Dynamic;
+
+-- collection_bookmark
+CREATE TABLE IF NOT EXISTS `collection_bookmark` (
+  `collection_id` int NOT NULL,
+  `bookmark_id` int NOT NULL,
+  `position` int NOT NULL DEFAULT 0 COMMENT '收藏夹中的排序,从小到大',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`collection_id`, `bookmark_id`) USING BTREE,
+  INDEX `collection_bookmark_collection_id_FK`(`collection_id` ASC) USING BTREE,
+  INDEX `collection_bookmark_bookmark_id_FK`(`bookmark_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '收藏夹与书签关联中间表' ROW_FORMAT = Dynamic;
+
+-- highlight
+CREATE TABLE IF NOT EXISTS `highlight` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL COMMENT '创建者',
+  `bookmark_id` int NOT NULL,
+  `quote` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '选中的文本',
+  `prefix` varchar(200) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '选中文本之前的文本',
+  `suffix` varchar(200) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '选中文本之后的文本',
+  `start_offset` int NOT NULL DEFAULT 0 COMMENT '在归档正文中的起始位置(字符)',
+  `end_offset` int NOT NULL DEFAULT 0 COMMENT '在归档正文中的结束位置(字符,不含)',
+  `orphaned` tinyint(1) NOT NULL DEFAULT 0 COMMENT '重新归档后是否找不到原文,0:否，1:是',
+  `note` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '批注',
+  `color` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT 'yellow' COMMENT '高亮颜色',
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `highlight_user_id_FK`(`user_id` ASC) USING BTREE,
+  INDEX `highlight_bookmark_id_FK`(`bookmark_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '高亮与批注表' ROW_FORMAT = Dynamic;
+
+-- personal_token
+CREATE TABLE IF NOT EXISTS `personal_token` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL,
+  `name` varchar(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '名称',
+  `token_hash` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '令牌sha256哈希',
+  `prefix` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '令牌前几位,用于识别',
+  `expires_at` datetime(3) NULL DEFAULT NULL COMMENT '过期时间,为空表示永不过期',
+  `last_used_at` datetime(3) NULL DEFAULT NULL COMMENT '最近使用时间',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `personal_token_hash_UNIQUE`(`token_hash` ASC) USING BTREE,
+  INDEX `personal_token_user_id_FK`(`user_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '个人访问令牌表' ROW_FORMAT = Dynamic;
+
+-- saved_search
+CREATE TABLE IF NOT EXISTS `saved_search` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL COMMENT '创建者',
+  `name` varchar(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '名称',
+  `keyword` varchar(500) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '全文搜索关键字',
+  `tags` varchar(1000) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '标签列表,英文逗号分隔',
+  `shared` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否共享给其他用户,0:否，1:是',
+  `feed_token` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '订阅源访问令牌',
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `saved_search_feed_token_UNIQUE`(`feed_token` ASC) USING BTREE,
+  INDEX `saved_search_user_id_FK`(`user_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '保存的搜索表' ROW_FORMAT = Dynamic;
+
+-- share
+CREATE TABLE IF NOT EXISTS `share` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL COMMENT '创建者',
+  `token` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '访问令牌',
+  `target_type` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '分享对象类型,bookmark/tag/saved_search/collection',
+  `target_id` int NOT NULL COMMENT '分享对象ID',
+  `password` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '访问密码,为空表示无需密码',
+  `salt` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '密码盐值',
+  `expires_at` datetime(3) NULL DEFAULT NULL COMMENT '过期时间,为空表示永不过期',
+  `view_count` int NOT NULL DEFAULT 0 COMMENT '访问次数',
+  `last_viewed_at` datetime(3) NULL DEFAULT NULL COMMENT '最近访问时间',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `share_token_UNIQUE`(`token` ASC) USING BTREE,
+  INDEX `share_user_id_FK`(`user_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '公开分享链接表' ROW_FORMAT = Dynamic;
+
+-- subscription
+CREATE TABLE IF NOT EXISTS `subscription` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '名称',
+  `url` varchar(2000) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '订阅源地址',
+  `tags` varchar(1000) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '新书签的默认标签,英文逗号分隔',
+  `archive` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否自动归档网页内容,0:否，1:是',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否启用,0:否，1:是',
+  `etag` varchar(500) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '上次响应的ETag',
+  `last_modified` varchar(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '上次响应的Last-Modified',
+  `last_checked_at` datetime(3) NULL DEFAULT NULL COMMENT '最近一次拉取时间',
+  `last_success_at` datetime(3) NULL DEFAULT NULL COMMENT '最近一次拉取成功时间',
+  `last_error` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '最近一次拉取的错误信息,成功时为空',
+  `error_count` int NOT NULL DEFAULT 0 COMMENT '连续失败次数',
+  `item_count` int NOT NULL DEFAULT 0 COMMENT '累计创建的书签数量',
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '订阅源表' ROW_FORMAT = Dynamic;
+
+-- tag
+CREATE TABLE IF NOT EXISTS `tag` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(250) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `tag_name_UNIQUE`(`name` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 258 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = 'tag表' ROW_FORMAT = Dynamic;
+
+-- tag_rule
+CREATE TABLE IF NOT EXISTS `tag_rule` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '规则名称',
+  `field` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '匹配字段,domain/url/title/content/folder',
+  `match_type` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '匹配方式,glob/regex/contains/keywords',
+  `pattern` varchar(1000) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '匹配规则',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否启用,0:否，1:是',
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '自动标签规则表' ROW_FORMAT = Dynamic;
+
+-- tag_rule_tag
+CREATE TABLE IF NOT EXISTS `tag_rule_tag` (
+  `rule_id` int NOT NULL,
+  `tag_id` int NOT NULL,
+  PRIMARY KEY (`rule_id`, `tag_id`) USING BTREE,
+  INDEX `tag_rule_tag_rule_id_FK`(`rule_id` ASC) USING BTREE,
+  INDEX `tag_rule_tag_tag_id_FK`(`tag_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '自动标签规则与tag关联中间表' ROW_FORMAT = Dynamic;
+
+-- user
+CREATE TABLE IF NOT EXISTS `user` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `username` varchar(250) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '用户名',
+  `password` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL,
+  `salt` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '0' COMMENT '密码盐值',
+  `role` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT 'user' COMMENT '角色: admin, user',
+  `totp_secret` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '' COMMENT 'TOTP密钥(base32)',
+  `totp_enabled` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否开启两步验证,0:否，1:是',
+  `totp_last_step` bigint NOT NULL DEFAULT 0 COMMENT '最近一次使用的TOTP时间步,防止重放',
+  `feed_token` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '' COMMENT '书签订阅源访问令牌,为空表示未生成',
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  `updated_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `account_username_UNIQUE`(`username` ASC) USING BTREE,
+  INDEX `idx_feed_token`(`feed_token` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 4 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '用户表' ROW_FORMAT = Dynamic;
+
+-- user_identity
+CREATE TABLE IF NOT EXISTS `user_identity` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL,
+  `issuer` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '身份提供方',
+  `subject` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL COMMENT '身份提供方用户唯一标识(sub)',
+  `email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '邮箱',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `user_identity_issuer_subject_UNIQUE`(`issuer` ASC, `subject` ASC) USING BTREE,
+  INDEX `user_identity_user_id_FK`(`user_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '第三方身份绑定表' ROW_FORMAT = Dynamic;
+
+-- user_recovery_code
+CREATE TABLE IF NOT EXISTS `user_recovery_code` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL,
+  `code_hash` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '恢复码sha256哈希',
+  `used_at` datetime(3) NULL DEFAULT NULL COMMENT '使用时间,为空表示未使用',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `user_recovery_code_user_id_FK`(`user_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '两步验证恢复码表' ROW_FORMAT = Dynamic;
+
+-- 升级旧版本（docs/db.sql 或 scripts/init_db.go 的 AutoMigrate）创建的 bookmark 与 user 表：上面的建表语句会跳过已存在的表，
+-- 在这里补充缺少的字段与索引，已存在时跳过；tag 与 bookmark_tag 的结构没有变化
+
+-- bookmark：已有书签的规范化网址先使用原网址，升级后执行 bk_kms reindex 重新计算规范化网址与正文指纹
+
+SET @add_canonical_url = (SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'bookmark' AND column_name = 'canonical_url') = 0;
+SET @stmt = IF(
+  @add_canonical_url,
+  'ALTER TABLE `bookmark` ADD COLUMN `canonical_url` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT ''规范化网址(用于判重)'' AFTER `url`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(@add_canonical_url, 'UPDATE `bookmark` SET `canonical_url` = `url`', 'DO 0');
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'bookmark' AND column_name = 'folder') = 0,
+  'ALTER TABLE `bookmark` ADD COLUMN `folder` varchar(1000) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '''' COMMENT ''导入时所在的书签文件夹路径'' AFTER `html`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'bookmark' AND column_name = 'simhash') = 0,
+  'ALTER TABLE `bookmark` ADD COLUMN `simhash` bigint UNSIGNED NOT NULL DEFAULT 0 COMMENT ''正文SimHash指纹(用于相似内容检测),0:无'' AFTER `folder`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'bookmark' AND column_name = 'deleted_at') = 0,
+  'ALTER TABLE `bookmark` ADD COLUMN `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT ''移入回收站时间,为空表示未删除'' AFTER `updated_at`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'bookmark' AND index_name = 'idx_canonical_url') = 0,
+  'ALTER TABLE `bookmark` ADD INDEX `idx_canonical_url`(`canonical_url`(255) ASC) USING BTREE',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'bookmark' AND index_name = 'idx_deleted_at') = 0,
+  'ALTER TABLE `bookmark` ADD INDEX `idx_deleted_at`(`deleted_at` ASC) USING BTREE',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- user：旧版本没有角色，全部用户都可以执行全部操作，升级后保持为管理员
+
+SET @add_role = (SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'user' AND column_name = 'role') = 0;
+SET @stmt = IF(
+  @add_role,
+  'ALTER TABLE `user` ADD COLUMN `role` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT ''user'' COMMENT ''角色: admin, user'' AFTER `salt`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(@add_role, 'UPDATE `user` SET `role` = ''admin''', 'DO 0');
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'user' AND column_name = 'totp_secret') = 0,
+  'ALTER TABLE `user` ADD COLUMN `totp_secret` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '''' COMMENT ''TOTP密钥(base32)'' AFTER `role`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'user' AND column_name = 'totp_enabled') = 0,
+  'ALTER TABLE `user` ADD COLUMN `totp_enabled` tinyint(1) NOT NULL DEFAULT 0 COMMENT ''是否开启两步验证,0:否，1:是'' AFTER `totp_secret`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'user' AND column_name = 'totp_last_step') = 0,
+  'ALTER TABLE `user` ADD COLUMN `totp_last_step` bigint NOT NULL DEFAULT 0 COMMENT ''最近一次使用的TOTP时间步,防止重放'' AFTER `totp_enabled`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'user' AND column_name = 'feed_token') = 0,
+  'ALTER TABLE `user` ADD COLUMN `feed_token` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '''' COMMENT ''书签订阅源访问令牌,为空表示未生成'' AFTER `totp_last_step`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'user' AND index_name = 'idx_feed_token') = 0,
+  'ALTER TABLE `user` ADD INDEX `idx_feed_token`(`feed_token` ASC) USING BTREE',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
@@ -0,0 +1,2 @@
+-- 全文索引属于初始表结构，回滚时保留
+DO 0;
@@ -0,0 +1,21 @@
+-- 补充全文索引：旧版本通过 AutoMigrate 创建的表缺少书签与笔记的全文索引，已存在时跳过
+
+-- bookmark
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'bookmark' AND index_name = 'ft_all_zh') = 0,
+  'ALTER TABLE `bookmark` ADD FULLTEXT INDEX `ft_all_zh`(`url`, `title`, `excerpt`, `content`) WITH PARSER `ngram`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- bookmark_note
+SET @stmt = IF(
+  (SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'bookmark_note' AND index_name = 'ft_note_zh') = 0,
+  'ALTER TABLE `bookmark_note` ADD FULLTEXT INDEX `ft_note_zh`(`content`) WITH PARSER `ngram`',
+  'DO 0'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
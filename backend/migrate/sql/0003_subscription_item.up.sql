@@ -0,0 +1,12 @@
+-- 订阅源已处理的条目，书签删除后不再重新创建
+
+-- subscription_item
+CREATE TABLE IF NOT EXISTS `subscription_item` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `subscription_id` int NOT NULL,
+  `item_key` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '条目GUID或地址的sha256哈希',
+  `url` varchar(2000) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '条目地址',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `subscription_item_UNIQUE`(`subscription_id` ASC, `item_key` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '订阅源已处理条目表' ROW_FORMAT = Dynamic;
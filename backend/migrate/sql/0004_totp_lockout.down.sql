@@ -0,0 +1,3 @@
+ALTER TABLE `user`
+  DROP COLUMN `totp_locked_at`,
+  DROP COLUMN `totp_failures`;
@@ -0,0 +1,5 @@
+-- 两步验证连续失败次数与锁定时间，防止暴力尝试动态码
+
+ALTER TABLE `user`
+  ADD COLUMN `totp_failures` int NOT NULL DEFAULT 0 COMMENT '连续两步验证失败次数' AFTER `totp_last_step`,
+  ADD COLUMN `totp_locked_at` datetime(3) NULL DEFAULT NULL COMMENT '两步验证因连续失败被锁定的时间' AFTER `totp_failures`;
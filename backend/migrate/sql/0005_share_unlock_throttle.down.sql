@@ -0,0 +1,3 @@
+ALTER TABLE `share`
+  DROP COLUMN `unlock_locked_until`,
+  DROP COLUMN `unlock_failures`;
@@ -0,0 +1,5 @@
+-- 分享链接访问密码的连续错误次数与锁定时间，防止暴力尝试密码
+
+ALTER TABLE `share`
+  ADD COLUMN `unlock_failures` int NOT NULL DEFAULT 0 COMMENT '访问密码连续错误次数' AFTER `salt`,
+  ADD COLUMN `unlock_locked_until` datetime(3) NULL DEFAULT NULL COMMENT '访问密码错误次数过多时锁定到该时间' AFTER `unlock_failures`;
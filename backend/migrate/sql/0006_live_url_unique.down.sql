@@ -0,0 +1,6 @@
+-- 回收站中与未删除书签网址相同的书签存在时无法恢复 url 唯一索引，需要先彻底删除这些书签
+
+ALTER TABLE `bookmark`
+  DROP INDEX `uk_live_url`,
+  DROP COLUMN `live_url`,
+  ADD UNIQUE INDEX `bookmark_url_UNIQUE`(`url`(255) ASC) USING BTREE;
@@ -0,0 +1,7 @@
+-- 网址唯一索引只约束未删除的书签：回收站中的书签不再阻止保存相同网址的书签
+-- live_url 为生成列，未删除时等于 url，移入回收站后为 NULL（唯一索引允许多个 NULL）
+
+ALTER TABLE `bookmark`
+  ADD COLUMN `live_url` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci GENERATED ALWAYS AS (IF(`deleted_at` IS NULL, `url`, NULL)) STORED COMMENT '未删除书签的网址(用于唯一索引),回收站中为空' AFTER `canonical_url`,
+  ADD UNIQUE INDEX `uk_live_url`(`live_url`(255) ASC) USING BTREE,
+  DROP INDEX `bookmark_url_UNIQUE`;
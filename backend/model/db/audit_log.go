@@ -0,0 +1,53 @@
+package db
+
+import "time"
+
+// 审计操作类型
+const (
+	AuditActionCreate      = "create"
+	AuditActionUpdate      = "update"
+	AuditActionDelete      = "delete" // 移入回收站
+	AuditActionRestore     = "restore"
+	AuditActionPurge       = "purge" // 彻底删除
+	AuditActionMerge       = "merge" // 合并重复书签
+	AuditActionApply       = "apply" // 执行自动标签规则
+	AuditActionImport      = "import"
+	AuditActionTagRename   = "tag_rename"
+	AuditActionTagMerge    = "tag_merge"
+	AuditActionLogin       = "login"
+	AuditActionLoginFailed = "login_failed"
+	AuditActionBackup      = "backup" // 下载全量备份
+)
+
+// 审计实体类型
+const (
+	AuditEntityBackup        = "backup" // 全量备份与恢复
+	AuditEntityBookmark      = "bookmark"
+	AuditEntityCollection    = "collection"
+	AuditEntityPersonalToken = "personal_token"
+	AuditEntitySavedSearch   = "saved_search"
+	AuditEntityShare         = "share"
+	AuditEntitySubscription  = "subscription"
+	AuditEntityTag           = "tag"
+	AuditEntityTagRule       = "tag_rule"
+	AuditEntityUser          = "user"
+)
+
+// AuditLog 审计日志表
+type AuditLog struct {
+	ID         int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID     int       `gorm:"column:user_id;not null;default:0;index:idx_user_id;comment:操作用户ID,0:未登录" json:"user_id"`
+	Username   string    `gorm:"column:username;type:varchar(250);not null;default:'';comment:操作用户名" json:"username"`
+	Action     string    `gorm:"column:action;type:varchar(50);not null;index:idx_action;comment:操作类型" json:"action"`
+	EntityType string    `gorm:"column:entity_type;type:varchar(50);not null;default:'';index:idx_entity_type;comment:实体类型" json:"entity_type"`
+	EntityIDs  string    `gorm:"column:entity_ids;type:text;not null;comment:实体ID列表,英文逗号分隔" json:"entity_ids"`
+	Diff       string    `gorm:"column:diff;type:mediumtext;not null;comment:变更内容(JSON),字段 -> {before, after}" json:"diff"`
+	IP         string    `gorm:"column:ip;type:varchar(64);not null;default:'';comment:客户端IP" json:"ip"`
+	UserAgent  string    `gorm:"column:user_agent;type:varchar(512);not null;default:'';comment:客户端User-Agent" json:"user_agent"`
+	CreatedAt  time.Time `gorm:"column:created_at;not null;autoCreateTime;index:idx_created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
@@ -1,19 +1,27 @@
 package db
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Bookmark 书签表
 type Bookmark struct {
-	ID        int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
-	URL       string    `gorm:"column:url;type:text;not null;uniqueIndex:bookmark_url_UNIQUE,length:255;comment:网址地址" json:"url"`
-	Title     string    `gorm:"column:title;type:text;not null;comment:网址标题" json:"title"`
-	Excerpt   string    `gorm:"column:excerpt;type:text;not null;comment:网站内容节选" json:"excerpt"`
-	Author    string    `gorm:"column:author;type:text;not null;comment:作者" json:"author"`
-	Content   string    `gorm:"column:content;type:mediumtext;not null;comment:网站文本内容(去掉html标签)" json:"content"`
-	HTML      string    `gorm:"column:html;type:mediumtext;not null;comment:网站原始内容" json:"html"`
-	IsArchive bool      `gorm:"column:is_archive;type:tinyint(1);not null;default:0;comment:是否已存档,0:否，1:是" json:"is_archive"`
-	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime;index:idx_created_at" json:"created_at"`
-	UpdatedAt time.Time `gorm:"column:updated_at;not null;autoUpdateTime;index:idx_modified_at" json:"updated_at"`
+	ID           int            `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	URL          string         `gorm:"column:url;type:text;not null;comment:网址地址" json:"url"`
+	CanonicalURL string         `gorm:"column:canonical_url;type:text;not null;index:idx_canonical_url,length:255;comment:规范化网址(用于判重)" json:"canonical_url"`
+	Title        string         `gorm:"column:title;type:text;not null;comment:网址标题" json:"title"`
+	Excerpt      string         `gorm:"column:excerpt;type:text;not null;comment:网站内容节选" json:"excerpt"`
+	Author       string         `gorm:"column:author;type:text;not null;comment:作者" json:"author"`
+	Content      string         `gorm:"column:content;type:mediumtext;not null;comment:网站文本内容(去掉html标签)" json:"content"`
+	HTML         string         `gorm:"column:html;type:mediumtext;not null;comment:网站原始内容" json:"html"`
+	Folder       string         `gorm:"column:folder;type:varchar(1000);not null;default:'';comment:导入时所在的书签文件夹路径" json:"folder"`
+	SimHash      uint64         `gorm:"column:simhash;type:bigint unsigned;not null;default:0;comment:正文SimHash指纹(用于相似内容检测),0:无" json:"simhash"`
+	IsArchive    bool           `gorm:"column:is_archive;type:tinyint(1);not null;default:0;comment:是否已存档,0:否，1:是" json:"is_archive"`
+	CreatedAt    time.Time      `gorm:"column:created_at;not null;autoCreateTime;index:idx_created_at" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at;not null;autoUpdateTime;index:idx_modified_at" json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at;index:idx_deleted_at;comment:移入回收站时间,为空表示未删除" json:"deleted_at"`
 
 	// 关联关系
 	Tags []Tag `gorm:"many2many:bookmark_tag;foreignKey:ID;joinForeignKey:bookmark_id;References:ID;joinReferences:tag_id" json:"tags,omitempty"`
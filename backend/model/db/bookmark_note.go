@@ -0,0 +1,18 @@
+package db
+
+import "time"
+
+// BookmarkNote 书签笔记表（Markdown）：属于创建的用户，一个书签可以有多条笔记，获取网页内容时不会修改
+type BookmarkNote struct {
+	ID         int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID     int       `gorm:"column:user_id;not null;index:bookmark_note_user_id_FK;comment:创建者" json:"user_id"`
+	BookmarkID int       `gorm:"column:bookmark_id;not null;index:bookmark_note_bookmark_id_FK" json:"bookmark_id"`
+	Content    string    `gorm:"column:content;type:mediumtext;not null;comment:笔记内容(Markdown)" json:"content"`
+	CreatedAt  time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (BookmarkNote) TableName() string {
+	return "bookmark_note"
+}
@@ -0,0 +1,35 @@
+package db
+
+import "time"
+
+// 阅读状态
+const (
+	BookmarkStateUnread   = "unread"   // 未读
+	BookmarkStateReading  = "reading"  // 阅读中
+	BookmarkStateRead     = "read"     // 已读
+	BookmarkStateArchived = "archived" // 已处理，移出待读列表
+)
+
+// BookmarkStates 全部阅读状态
+var BookmarkStates = []string{BookmarkStateUnread, BookmarkStateReading, BookmarkStateRead, BookmarkStateArchived}
+
+// BookmarkState 用户的书签阅读状态表，没有记录表示未读、未收藏
+type BookmarkState struct {
+	UserID       int        `gorm:"column:user_id;primaryKey;not null" json:"user_id"`
+	BookmarkID   int        `gorm:"column:bookmark_id;primaryKey;not null;index:bookmark_state_bookmark_id_FK" json:"bookmark_id"`
+	State        string     `gorm:"column:state;type:varchar(20);not null;default:'unread';comment:阅读状态,unread/reading/read/archived" json:"state"`
+	Favorite     bool       `gorm:"column:favorite;type:tinyint(1);not null;default:0;comment:是否收藏(星标),0:否，1:是" json:"favorite"`
+	Progress     int        `gorm:"column:progress;not null;default:0;comment:阅读进度百分比,0-100" json:"progress"`
+	LastOpenedAt *time.Time `gorm:"column:last_opened_at;comment:最近打开时间" json:"last_opened_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (BookmarkState) TableName() string {
+	return "bookmark_state"
+}
+
+// DefaultBookmarkState 没有记录时的阅读状态
+func DefaultBookmarkState(userID, bookmarkID int) BookmarkState {
+	return BookmarkState{UserID: userID, BookmarkID: bookmarkID, State: BookmarkStateUnread}
+}
@@ -0,0 +1,15 @@
+package db
+
+import "time"
+
+// Captcha 验证码表（多实例部署时共享验证码）
+type Captcha struct {
+	ID        string    `gorm:"column:id;type:varchar(32);primaryKey;comment:验证码ID" json:"id"`
+	Digits    string    `gorm:"column:digits;type:varchar(32);not null;comment:验证码数字" json:"digits"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null;index:idx_expires_at;comment:过期时间" json:"expires_at"`
+}
+
+// TableName 指定表名
+func (Captcha) TableName() string {
+	return "captcha"
+}
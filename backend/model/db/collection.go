@@ -0,0 +1,31 @@
+package db
+
+import "time"
+
+// Collection 收藏夹表：可以嵌套，书签可以属于多个收藏夹
+type Collection struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ParentID  int       `gorm:"column:parent_id;not null;default:0;uniqueIndex:collection_parent_name_UNIQUE,priority:1;comment:上级收藏夹ID,0:顶级" json:"parent_id"`
+	Name      string    `gorm:"column:name;type:varchar(250);not null;uniqueIndex:collection_parent_name_UNIQUE,priority:2;comment:名称" json:"name"`
+	Position  int       `gorm:"column:position;not null;default:0;comment:同级收藏夹中的排序,从小到大" json:"position"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Collection) TableName() string {
+	return "collection"
+}
+
+// CollectionBookmark 收藏夹与书签关联中间表，记录手动排序
+type CollectionBookmark struct {
+	CollectionID int       `gorm:"column:collection_id;primaryKey;not null;index:collection_bookmark_collection_id_FK" json:"collection_id"`
+	BookmarkID   int       `gorm:"column:bookmark_id;primaryKey;not null;index:collection_bookmark_bookmark_id_FK" json:"bookmark_id"`
+	Position     int       `gorm:"column:position;not null;default:0;comment:收藏夹中的排序,从小到大" json:"position"`
+	CreatedAt    time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (CollectionBookmark) TableName() string {
+	return "collection_bookmark"
+}
@@ -0,0 +1,29 @@
+package db
+
+import "time"
+
+// Highlight 归档内容中的高亮与批注：以选中文本及其前后文定位，位置仅用于区分重复出现的文本，
+// 重新归档后按原文重新定位
+type Highlight struct {
+	ID          int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID      int       `gorm:"column:user_id;not null;index:highlight_user_id_FK;comment:创建者" json:"user_id"`
+	BookmarkID  int       `gorm:"column:bookmark_id;not null;index:highlight_bookmark_id_FK" json:"bookmark_id"`
+	Quote       string    `gorm:"column:quote;type:text;not null;comment:选中的文本" json:"quote"`
+	Prefix      string    `gorm:"column:prefix;type:varchar(200);not null;default:'';comment:选中文本之前的文本" json:"prefix"`
+	Suffix      string    `gorm:"column:suffix;type:varchar(200);not null;default:'';comment:选中文本之后的文本" json:"suffix"`
+	StartOffset int       `gorm:"column:start_offset;not null;default:0;comment:在归档正文中的起始位置(字符)" json:"start_offset"`
+	EndOffset   int       `gorm:"column:end_offset;not null;default:0;comment:在归档正文中的结束位置(字符,不含)" json:"end_offset"`
+	Orphaned    bool      `gorm:"column:orphaned;type:tinyint(1);not null;default:0;comment:重新归档后是否找不到原文,0:否，1:是" json:"orphaned"`
+	Note        string    `gorm:"column:note;type:text;not null;comment:批注" json:"note"`
+	Color       string    `gorm:"column:color;type:varchar(20);not null;default:'yellow';comment:高亮颜色" json:"color"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Bookmark *Bookmark `gorm:"foreignKey:BookmarkID" json:"bookmark,omitempty"`
+}
+
+// TableName 指定表名
+func (Highlight) TableName() string {
+	return "highlight"
+}
@@ -0,0 +1,28 @@
+package db
+
+import "time"
+
+// PersonalToken 个人访问令牌表：用于命令行等脚本访问接口，可替代登录获取的 JWT
+type PersonalToken struct {
+	ID         int        `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID     int        `gorm:"column:user_id;not null;index:personal_token_user_id_FK" json:"user_id"`
+	Name       string     `gorm:"column:name;type:varchar(100);not null;comment:名称" json:"name"`
+	TokenHash  string     `gorm:"column:token_hash;type:varchar(64);not null;uniqueIndex:personal_token_hash_UNIQUE;comment:令牌sha256哈希" json:"-"`
+	Prefix     string     `gorm:"column:prefix;type:varchar(20);not null;comment:令牌前几位,用于识别" json:"prefix"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at;comment:过期时间,为空表示永不过期" json:"expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at;comment:最近使用时间" json:"last_used_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+
+	// 关联关系
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName 指定表名
+func (PersonalToken) TableName() string {
+	return "personal_token"
+}
+
+// Expired 令牌是否已过期
+func (t *PersonalToken) Expired() bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.After(time.Now())
+}
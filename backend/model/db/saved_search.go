@@ -0,0 +1,24 @@
+package db
+
+import "time"
+
+// SavedSearch 保存的搜索（智能收藏夹）：保存查询条件，按条件实时查询书签
+type SavedSearch struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    int       `gorm:"column:user_id;not null;index:saved_search_user_id_FK;comment:创建者" json:"user_id"`
+	Name      string    `gorm:"column:name;type:varchar(100);not null;comment:名称" json:"name"`
+	Keyword   string    `gorm:"column:keyword;type:varchar(500);not null;default:'';comment:全文搜索关键字" json:"keyword"`
+	Tags      string    `gorm:"column:tags;type:varchar(1000);not null;default:'';comment:标签列表,英文逗号分隔" json:"tags"`
+	Shared    bool      `gorm:"column:shared;type:tinyint(1);not null;default:0;comment:是否共享给其他用户,0:否，1:是" json:"shared"`
+	FeedToken string    `gorm:"column:feed_token;type:varchar(64);not null;uniqueIndex:saved_search_feed_token_UNIQUE;comment:订阅源访问令牌" json:"-"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName 指定表名
+func (SavedSearch) TableName() string {
+	return "saved_search"
+}
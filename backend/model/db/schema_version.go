@@ -0,0 +1,15 @@
+package db
+
+import "time"
+
+// SchemaVersion 数据库版本表：记录已执行的迁移
+type SchemaVersion struct {
+	Version   int       `gorm:"column:version;primaryKey;autoIncrement:false;comment:迁移版本号" json:"version"`
+	Name      string    `gorm:"column:name;type:varchar(100);not null;comment:迁移名称" json:"name"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null;comment:执行时间" json:"applied_at"`
+}
+
+// TableName 指定表名
+func (SchemaVersion) TableName() string {
+	return "schema_version"
+}
@@ -0,0 +1,38 @@
+package db
+
+import "time"
+
+// 分享对象类型
+const (
+	ShareTargetBookmark    = "bookmark"
+	ShareTargetTag         = "tag"
+	ShareTargetSavedSearch = "saved_search"
+	ShareTargetCollection  = "collection"
+)
+
+// Share 公开分享链接表：无需登录即可通过令牌只读访问书签或书签列表
+type Share struct {
+	ID                int        `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID            int        `gorm:"column:user_id;not null;index:share_user_id_FK;comment:创建者" json:"user_id"`
+	Token             string     `gorm:"column:token;type:varchar(64);not null;uniqueIndex:share_token_UNIQUE;comment:访问令牌" json:"-"`
+	TargetType        string     `gorm:"column:target_type;type:varchar(20);not null;comment:分享对象类型,bookmark/tag/saved_search/collection" json:"target_type"`
+	TargetID          int        `gorm:"column:target_id;not null;comment:分享对象ID" json:"target_id"`
+	Password          string     `gorm:"column:password;type:varchar(50);not null;default:'';comment:访问密码,为空表示无需密码" json:"-"`
+	Salt              string     `gorm:"column:salt;type:varchar(50);not null;default:'';comment:密码盐值" json:"-"`
+	UnlockFailures    int        `gorm:"column:unlock_failures;not null;default:0;comment:访问密码连续错误次数" json:"-"`
+	UnlockLockedUntil *time.Time `gorm:"column:unlock_locked_until;comment:访问密码错误次数过多时锁定到该时间" json:"-"`
+	ExpiresAt         *time.Time `gorm:"column:expires_at;comment:过期时间,为空表示永不过期" json:"expires_at"`
+	ViewCount         int        `gorm:"column:view_count;not null;default:0;comment:访问次数" json:"view_count"`
+	LastViewedAt      *time.Time `gorm:"column:last_viewed_at;comment:最近访问时间" json:"last_viewed_at"`
+	CreatedAt         time.Time  `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (Share) TableName() string {
+	return "share"
+}
+
+// Expired 分享链接是否已过期
+func (s *Share) Expired() bool {
+	return s.ExpiresAt != nil && !s.ExpiresAt.After(time.Now())
+}
@@ -0,0 +1,27 @@
+package db
+
+import "time"
+
+// Subscription 订阅源表：定时拉取 RSS/Atom/JSON Feed，将新条目创建为书签
+type Subscription struct {
+	ID            int        `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name          string     `gorm:"column:name;type:varchar(100);not null;comment:名称" json:"name"`
+	URL           string     `gorm:"column:url;type:varchar(2000);not null;comment:订阅源地址" json:"url"`
+	Tags          string     `gorm:"column:tags;type:varchar(1000);not null;default:'';comment:新书签的默认标签,英文逗号分隔" json:"tags"`
+	Archive       bool       `gorm:"column:archive;type:tinyint(1);not null;default:0;comment:是否自动归档网页内容,0:否，1:是" json:"archive"`
+	Enabled       bool       `gorm:"column:enabled;type:tinyint(1);not null;default:1;comment:是否启用,0:否，1:是" json:"enabled"`
+	ETag          string     `gorm:"column:etag;type:varchar(500);not null;default:'';comment:上次响应的ETag" json:"etag"`
+	LastModified  string     `gorm:"column:last_modified;type:varchar(100);not null;default:'';comment:上次响应的Last-Modified" json:"last_modified"`
+	LastCheckedAt *time.Time `gorm:"column:last_checked_at;comment:最近一次拉取时间" json:"last_checked_at"`
+	LastSuccessAt *time.Time `gorm:"column:last_success_at;comment:最近一次拉取成功时间" json:"last_success_at"`
+	LastError     string     `gorm:"column:last_error;type:text;not null;comment:最近一次拉取的错误信息,成功时为空" json:"last_error"`
+	ErrorCount    int        `gorm:"column:error_count;not null;default:0;comment:连续失败次数" json:"error_count"`
+	ItemCount     int        `gorm:"column:item_count;not null;default:0;comment:累计创建的书签数量" json:"item_count"`
+	CreatedAt     time.Time  `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Subscription) TableName() string {
+	return "subscription"
+}
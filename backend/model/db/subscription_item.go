@@ -0,0 +1,17 @@
+package db
+
+import "time"
+
+// SubscriptionItem 订阅源已处理的条目：按条目的 GUID（没有时使用地址）记录，书签删除后不会被重新创建
+type SubscriptionItem struct {
+	ID             int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	SubscriptionID int       `gorm:"column:subscription_id;not null;uniqueIndex:subscription_item_UNIQUE,priority:1" json:"subscription_id"`
+	ItemKey        string    `gorm:"column:item_key;type:varchar(64);not null;uniqueIndex:subscription_item_UNIQUE,priority:2;comment:条目GUID或地址的sha256哈希" json:"item_key"`
+	URL            string    `gorm:"column:url;type:varchar(2000);not null;comment:条目地址" json:"url"`
+	CreatedAt      time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (SubscriptionItem) TableName() string {
+	return "subscription_item"
+}
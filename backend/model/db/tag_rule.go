@@ -0,0 +1,34 @@
+package db
+
+import "time"
+
+// TagRule 自动标签规则表：书签满足条件时自动添加标签
+type TagRule struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"column:name;type:varchar(100);not null;comment:规则名称" json:"name"`
+	Field     string    `gorm:"column:field;type:varchar(20);not null;comment:匹配字段,domain/url/title/content/folder" json:"field"`
+	MatchType string    `gorm:"column:match_type;type:varchar(20);not null;comment:匹配方式,glob/regex/contains/keywords" json:"match_type"`
+	Pattern   string    `gorm:"column:pattern;type:varchar(1000);not null;comment:匹配规则" json:"pattern"`
+	Enabled   bool      `gorm:"column:enabled;type:tinyint(1);not null;default:1;comment:是否启用,0:否，1:是" json:"enabled"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Tags []Tag `gorm:"many2many:tag_rule_tag;foreignKey:ID;joinForeignKey:rule_id;References:ID;joinReferences:tag_id" json:"tags,omitempty"`
+}
+
+// TableName 指定表名
+func (TagRule) TableName() string {
+	return "tag_rule"
+}
+
+// TagRuleTag 自动标签规则与tag关联中间表
+type TagRuleTag struct {
+	RuleID int `gorm:"column:rule_id;primaryKey;not null;index:tag_rule_tag_rule_id_FK" json:"rule_id"`
+	TagID  int `gorm:"column:tag_id;primaryKey;not null;index:tag_rule_tag_tag_id_FK" json:"tag_id"`
+}
+
+// TableName 指定表名
+func (TagRuleTag) TableName() string {
+	return "tag_rule_tag"
+}
@@ -2,14 +2,27 @@ package db
 
 import "time"
 
+// 用户角色
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
 // User 用户表
 type User struct {
-	ID        int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
-	Username  string    `gorm:"column:username;type:varchar(250);not null;uniqueIndex:account_username_UNIQUE;comment:用户名" json:"username"`
-	Password  string    `gorm:"column:password;type:varchar(50);not null" json:"password"`
-	Salt      string    `gorm:"column:salt;type:varchar(50);not null;default:0;comment:密码盐值" json:"salt"`
-	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
+	ID           int        `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Username     string     `gorm:"column:username;type:varchar(250);not null;uniqueIndex:account_username_UNIQUE;comment:用户名" json:"username"`
+	Password     string     `gorm:"column:password;type:varchar(50);not null" json:"password"`
+	Salt         string     `gorm:"column:salt;type:varchar(50);not null;default:0;comment:密码盐值" json:"salt"`
+	Role         string     `gorm:"column:role;type:varchar(20);not null;default:user;comment:角色: admin, user" json:"role"`
+	TOTPSecret   string     `gorm:"column:totp_secret;type:varchar(64);not null;default:'';comment:TOTP密钥(base32)" json:"-"`
+	TOTPEnabled  bool       `gorm:"column:totp_enabled;type:tinyint(1);not null;default:0;comment:是否开启两步验证,0:否，1:是" json:"totp_enabled"`
+	TOTPLastStep int64      `gorm:"column:totp_last_step;not null;default:0;comment:最近一次使用的TOTP时间步,防止重放" json:"totp_last_step"`
+	TOTPFailures int        `gorm:"column:totp_failures;not null;default:0;comment:连续两步验证失败次数" json:"-"`
+	TOTPLockedAt *time.Time `gorm:"column:totp_locked_at;comment:两步验证因连续失败被锁定的时间" json:"-"`
+	FeedToken    string     `gorm:"column:feed_token;type:varchar(64);not null;default:'';index:idx_feed_token;comment:书签订阅源访问令牌,为空表示未生成" json:"-"`
+	CreatedAt    time.Time  `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;not null;autoUpdateTime" json:"updated_at"`
 }
 
 // TableName 指定表名
@@ -0,0 +1,18 @@
+package db
+
+import "time"
+
+// UserIdentity 第三方身份（OIDC）与本地用户的绑定表
+type UserIdentity struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    int       `gorm:"column:user_id;not null;index:user_identity_user_id_FK" json:"user_id"`
+	Issuer    string    `gorm:"column:issuer;type:varchar(255);not null;uniqueIndex:user_identity_issuer_subject_UNIQUE,priority:1;comment:身份提供方" json:"issuer"`
+	Subject   string    `gorm:"column:subject;type:varchar(255);not null;uniqueIndex:user_identity_issuer_subject_UNIQUE,priority:2;comment:身份提供方用户唯一标识(sub)" json:"subject"`
+	Email     string    `gorm:"column:email;type:varchar(255);not null;default:'';comment:邮箱" json:"email"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identity"
+}
@@ -0,0 +1,17 @@
+package db
+
+import "time"
+
+// UserRecoveryCode 两步验证恢复码表
+type UserRecoveryCode struct {
+	ID        int        `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    int        `gorm:"column:user_id;not null;index:user_recovery_code_user_id_FK" json:"user_id"`
+	CodeHash  string     `gorm:"column:code_hash;type:varchar(64);not null;comment:恢复码sha256哈希" json:"code_hash"`
+	UsedAt    *time.Time `gorm:"column:used_at;comment:使用时间,为空表示未使用" json:"used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_code"
+}
@@ -0,0 +1,27 @@
+package dto
+
+// AuditListRequest 审计日志列表请求
+type AuditListRequest struct {
+	UserID     int    `form:"user_id" json:"user_id"`                    // 操作用户ID
+	Action     string `form:"action" json:"action"`                      // 操作类型: create, update, delete, import, tag_rename, login, login_failed
+	EntityType string `form:"entity_type" json:"entity_type"`            // 实体类型: bookmark, tag, user
+	EntityID   int    `form:"entity_id" json:"entity_id"`                // 实体ID
+	Start      int64  `form:"start" json:"start"`                        // 开始时间（时间戳，包含）
+	End        int64  `form:"end" json:"end"`                            // 结束时间（时间戳，不包含）
+	Page       int    `form:"page" json:"page" binding:"required,min=1"` // 页码
+	PageSize   int    `form:"page_size" json:"page_size"`                // 每页记录数，默认10
+}
+
+// AuditListItem 审计日志列表项
+type AuditListItem struct {
+	ID         int         `json:"id"`
+	UserID     int         `json:"user_id"`     // 操作用户ID
+	Username   string      `json:"username"`    // 操作用户名
+	Action     string      `json:"action"`      // 操作类型
+	EntityType string      `json:"entity_type"` // 实体类型
+	EntityIDs  []int       `json:"entity_ids"`  // 实体ID列表
+	Diff       interface{} `json:"diff"`        // 变更内容，字段 -> {before, after}
+	IP         string      `json:"ip"`          // 客户端IP
+	UserAgent  string      `json:"user_agent"`  // 客户端User-Agent
+	CreatedAt  int64       `json:"created_at"`  // 操作时间（时间戳）
+}
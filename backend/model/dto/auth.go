@@ -10,9 +10,11 @@ type LoginRequest struct {
 
 // LoginData 登录响应数据
 type LoginData struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Token    string `json:"token"`
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	Token        string `json:"token"`
+	NeedTOTP     bool   `json:"need_totp,omitempty"`      // 是否需要两步验证，为 true 时 token 为空
+	PreAuthToken string `json:"pre_auth_token,omitempty"` // 预认证 token，用于提交两步验证码
 }
 
 // LoginResponse 登录响应
@@ -24,8 +26,9 @@ type LoginResponse struct {
 
 // CaptchaData 验证码响应数据
 type CaptchaData struct {
-	Captcha    string `json:"captcha"`              // 图形验证码，base64编码
-	CaptchaID  string `json:"captcha_id"`           // 图形验证码ID
+	Captcha    string `json:"captcha"`               // 图形验证码，base64编码
+	CaptchaID  string `json:"captcha_id"`            // 图形验证码ID
+	Audio      string `json:"audio,omitempty"`       // 语音验证码，base64编码的wav（仅请求audio=true且配置开启时返回）
 	CaptchaStr string `json:"captcha_str,omitempty"` // 验证码实际值（仅非release环境）
 }
 
@@ -35,3 +38,46 @@ type CaptchaResponse struct {
 	Msg  string      `json:"msg"`
 	Data CaptchaData `json:"data"`
 }
+
+// LoginTOTPRequest 两步验证登录请求
+type LoginTOTPRequest struct {
+	PreAuthToken string `json:"pre_auth_token" binding:"required"` // 登录第一步返回的预认证 token
+	Code         string `json:"code" binding:"required"`           // 6 位动态码或恢复码
+}
+
+// TOTPCodeRequest 提交动态码请求
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"` // 6 位动态码或恢复码
+}
+
+// TOTPStatusData 两步验证状态
+type TOTPStatusData struct {
+	Enabled           bool  `json:"enabled"`             // 是否已开启
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"` // 剩余可用恢复码数量
+}
+
+// TOTPEnrollData 两步验证绑定数据
+type TOTPEnrollData struct {
+	Secret string `json:"secret"`  // base32 密钥，用于手动输入
+	URI    string `json:"uri"`     // otpauth:// URI
+	QRCode string `json:"qr_code"` // 二维码图片，base64编码的png
+}
+
+// TOTPRecoveryCodesData 恢复码数据（仅生成时返回一次明文）
+type TOTPRecoveryCodesData struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// OIDCLinkData 绑定第三方身份响应数据
+type OIDCLinkData struct {
+	AuthURL string `json:"auth_url"` // 身份提供方登录地址，前端需跳转到该地址
+}
+
+// UserIdentityItem 已绑定的第三方身份
+type UserIdentityItem struct {
+	ID        int    `json:"id"`
+	Issuer    string `json:"issuer"`     // 身份提供方
+	Subject   string `json:"subject"`    // 身份提供方用户唯一标识
+	Email     string `json:"email"`      // 邮箱
+	CreatedAt int64  `json:"created_at"` // 绑定时间（时间戳）
+}
@@ -4,10 +4,20 @@ package dto
 type BookmarkListRequest struct {
 	Keyword  string `form:"keyword" json:"keyword"`                    // 内容查询关键字, 查询范围：url、title、excerpt、content
 	Tags     string `form:"tags" json:"tags"`                          // tag列表，使用英文的逗号分隔多个tag，tag name全匹配
+	State    string `form:"state" json:"state"`                        // 阅读状态：unread, reading, read, archived，使用英文的逗号分隔多个状态
+	Favorite *bool  `form:"favorite" json:"favorite"`                  // 是否收藏，为空表示不限定
 	Page     int    `form:"page" json:"page" binding:"required,min=1"` // 页码
 	PageSize int    `form:"page_size" json:"page_size"`                // 每页记录数，默认10
 }
 
+// BookmarkExportRequest 书签导出请求，过滤条件与书签列表相同
+type BookmarkExportRequest struct {
+	Keyword  string `form:"keyword" json:"keyword"`   // 内容查询关键字
+	Tags     string `form:"tags" json:"tags"`         // tag列表，使用英文的逗号分隔多个tag
+	State    string `form:"state" json:"state"`       // 阅读状态，使用英文的逗号分隔多个状态
+	Favorite *bool  `form:"favorite" json:"favorite"` // 是否收藏，为空表示不限定
+}
+
 // BookmarkListItem 书签列表项
 type BookmarkListItem struct {
 	ID        int       `json:"id"`
@@ -19,6 +29,31 @@ type BookmarkListItem struct {
 	CreatedAt int64     `json:"created_at"`     // 创建时间（时间戳）
 	UpdatedAt int64     `json:"updated_at"`     // 最后更新时间（时间戳）
 	Tags      []TagItem `json:"tags,omitempty"` // 标签列表
+
+	Reading *BookmarkStateItem `json:"reading,omitempty"` // 当前用户的阅读状态
+}
+
+// BookmarkStateItem 用户的书签阅读状态
+type BookmarkStateItem struct {
+	State        string `json:"state"`          // 阅读状态：unread, reading, read, archived
+	Favorite     bool   `json:"favorite"`       // 是否收藏
+	Progress     int    `json:"progress"`       // 阅读进度百分比（0~100）
+	LastOpenedAt int64  `json:"last_opened_at"` // 最近打开时间（时间戳），0 表示未打开
+}
+
+// UpdateBookmarkStateRequest 修改书签阅读状态请求，为空的字段保持不变；
+// 只修改进度时，进度为 100 自动标记为已读，未读的书签进度大于 0 自动标记为阅读中
+type UpdateBookmarkStateRequest struct {
+	State    *string `json:"state" binding:"omitempty,oneof=unread reading read archived"` // 阅读状态
+	Favorite *bool   `json:"favorite"`                                                     // 是否收藏
+	Progress *int    `json:"progress" binding:"omitempty,min=0,max=100"`                   // 阅读进度百分比
+}
+
+// BulkBookmarkStateRequest 批量修改书签阅读状态请求
+type BulkBookmarkStateRequest struct {
+	IDs      []int   `json:"ids" binding:"required,min=1"`                                 // 书签ID列表
+	State    *string `json:"state" binding:"omitempty,oneof=unread reading read archived"` // 阅读状态
+	Favorite *bool   `json:"favorite"`                                                     // 是否收藏
 }
 
 // BookmarkListResponse 书签列表响应
@@ -35,6 +70,22 @@ type CreateBookmarkRequest struct {
 	Excerpt       string    `json:"excerpt"`                // 简述
 	Tags          []TagItem `json:"tags"`                   // 标签列表
 	CreateArchive bool      `json:"create_archive"`         // 是否创建归档
+	AutoTag       bool      `json:"auto_tag"`               // 是否自动添加推荐标签（置信度达到配置阈值）
+}
+
+// CreateBookmarkData 创建书签结果
+type CreateBookmarkData struct {
+	ID       int               `json:"id"`                  // 书签ID
+	AutoTags []TagItem         `json:"auto_tags,omitempty"` // 自动标签规则与推荐添加的标签
+	Similar  []SimilarBookmark `json:"similar,omitempty"`   // 正文相似的已有书签
+}
+
+// SimilarBookmark 正文相似的书签
+type SimilarBookmark struct {
+	ID         int     `json:"id"`
+	URL        string  `json:"url"`        // 书签原文地址
+	Title      string  `json:"title"`      // 标题
+	Similarity float64 `json:"similarity"` // 相似度（0~1）
 }
 
 // UpdateBookmarkRequest 编辑书签请求
@@ -53,12 +104,34 @@ type DeleteBookmarkRequest []int
 
 // BookmarkContentResponse 书签内容响应数据
 type BookmarkContentData struct {
-	ID        int    `json:"id"`
-	URL       string `json:"url"`        // 书签原文地址
-	Title     string `json:"title"`      // 标题
-	HTML      string `json:"html"`       // 内容
-	CreatedAt int64  `json:"created_at"` // 创建时间（时间戳）
-	UpdatedAt int64  `json:"update_at"`  // 最近更新时间（时间戳）
+	ID        int                `json:"id"`
+	URL       string             `json:"url"`               // 书签原文地址
+	Title     string             `json:"title"`             // 标题
+	HTML      string             `json:"html"`              // 内容
+	CreatedAt int64              `json:"created_at"`        // 创建时间（时间戳）
+	UpdatedAt int64              `json:"update_at"`         // 最近更新时间（时间戳）
+	Related   []RelatedBookmark  `json:"related,omitempty"` // 相关书签（前 5 个）
+	Reading   *BookmarkStateItem `json:"reading,omitempty"` // 当前用户的阅读状态
+}
+
+// RelatedBookmarkRequest 相关书签请求
+type RelatedBookmarkRequest struct {
+	Limit int `form:"limit" json:"limit" binding:"omitempty,max=50"` // 返回数量，默认10
+}
+
+// RelatedBookmark 相关书签
+type RelatedBookmark struct {
+	BookmarkListItem
+	Score      float64       `json:"score"`       // 综合相关度（0~1）
+	Scores     RelatedScores `json:"scores"`      // 相关度明细
+	SharedTags []TagItem     `json:"shared_tags"` // 共同标签
+}
+
+// RelatedScores 相关度明细，综合相关度 = 0.4*tag + 0.2*domain + 0.4*text
+type RelatedScores struct {
+	Tag    float64 `json:"tag"`    // 共同标签（Jaccard 系数）
+	Domain float64 `json:"domain"` // 相同域名为 1
+	Text   float64 `json:"text"`   // 正文 TF-IDF 余弦相似度
 }
 
 // BookmarkContentResponse 书签内容响应
@@ -70,9 +143,94 @@ type BookmarkContentResponse struct {
 
 // ImportProgressEvent SSE 导入进度事件
 type ImportProgressEvent struct {
-	Type    string `json:"type"`    // progress, success, error, complete
-	Message string `json:"message"` // 消息内容
-	Current int    `json:"current"` // 当前处理数量
-	Total   int    `json:"total"`   // 总数量
-	URL     string `json:"url"`     // 当前处理的 URL
+	Type    string            `json:"type"`              // progress, success, warning, error, complete
+	Message string            `json:"message"`           // 消息内容
+	Current int               `json:"current"`           // 当前处理数量
+	Total   int               `json:"total"`             // 总数量
+	URL     string            `json:"url"`               // 当前处理的 URL
+	Similar []SimilarBookmark `json:"similar,omitempty"` // 正文相似的已有书签（warning）
+}
+
+// BookmarkRecordImportResult 原生格式（JSON Lines / CSV）导入结果
+type BookmarkRecordImportResult struct {
+	Created int                 `json:"created"` // 新建的书签数
+	Updated int                 `json:"updated"` // 覆盖或合并标签的已有书签数
+	Skipped int                 `json:"skipped"` // 已存在而跳过的书签数
+	Failed  int                 `json:"failed"`  // 失败的记录数
+	Errors  []RecordImportError `json:"errors"`  // 失败记录的行号与原因
+}
+
+// RecordImportError 导入失败的记录
+type RecordImportError struct {
+	Line    int    `json:"line"` // 行号
+	URL     string `json:"url,omitempty"`
+	Message string `json:"message"`
+}
+
+// TrashListRequest 回收站列表请求
+type TrashListRequest struct {
+	Keyword  string `form:"keyword" json:"keyword"`                    // 内容查询关键字
+	Page     int    `form:"page" json:"page" binding:"required,min=1"` // 页码
+	PageSize int    `form:"page_size" json:"page_size"`                // 每页记录数，默认10
+}
+
+// TrashListItem 回收站列表项
+type TrashListItem struct {
+	BookmarkListItem
+	DeletedAt int64 `json:"deleted_at"` // 移入回收站时间（时间戳）
+}
+
+// TrashIDsRequest 回收站批量操作请求（恢复、彻底删除）
+type TrashIDsRequest []int
+
+// TrashOperationData 回收站批量操作结果
+type TrashOperationData struct {
+	IDs     []int `json:"ids"`     // 操作成功的书签ID
+	Skipped []int `json:"skipped"` // 跳过的书签ID（恢复时已存在相同 URL 的书签，或不在回收站中）
+}
+
+// DuplicateGroup 重复书签分组
+type DuplicateGroup struct {
+	CanonicalURL string             `json:"canonical_url"` // 规范化 URL
+	Bookmarks    []BookmarkListItem `json:"bookmarks"`     // 规范化 URL 相同的书签，按创建时间排序
+}
+
+// MergeBookmarkRequest 合并重复书签请求
+type MergeBookmarkRequest struct {
+	IDs    []int `json:"ids" binding:"required,min=2"` // 需要合并的书签ID
+	KeepID int   `json:"keep_id"`                      // 保留的书签ID，默认保留最早创建的书签
+	Force  bool  `json:"force"`                        // 规范化 URL 不同的书签（如相似内容报告中的书签）需要设置为 true 才能合并
+}
+
+// MergeBookmarkData 合并重复书签结果
+type MergeBookmarkData struct {
+	KeepID    int   `json:"keep_id"`    // 保留的书签ID
+	MergedIDs []int `json:"merged_ids"` // 已合并（移入回收站）的书签ID
+}
+
+// NearDuplicateRequest 相似内容报告请求
+type NearDuplicateRequest struct {
+	Threshold float64 `form:"threshold" json:"threshold" binding:"omitempty,gt=0,lte=1"` // 相似度阈值（0~1），默认使用配置 similar.threshold
+}
+
+// NearDuplicateGroup 相似内容书签分组
+type NearDuplicateGroup struct {
+	Bookmarks []NearDuplicateItem `json:"bookmarks"` // 按创建时间排序
+}
+
+// NearDuplicateItem 相似内容书签
+type NearDuplicateItem struct {
+	BookmarkListItem
+	Similarity float64 `json:"similarity"` // 与分组中第一个书签的相似度
+}
+
+// RefreshCanonicalURLData 重新计算规范化 URL 结果
+type RefreshCanonicalURLData struct {
+	Updated int `json:"updated"` // 更新的书签数量
+}
+
+// RefreshSimHashData 重新计算正文指纹结果
+type RefreshSimHashData struct {
+	Updated int `json:"updated"` // 指纹发生变化并已更新的书签数量
+	Empty   int `json:"empty"`   // 没有正文（未归档）、不参与相似内容检测的书签数量
 }
@@ -0,0 +1,26 @@
+package dto
+
+// BookmarkNoteItem 书签笔记
+type BookmarkNoteItem struct {
+	ID         int    `json:"id"`
+	BookmarkID int    `json:"bookmark_id"`
+	Content    string `json:"content"`        // 笔记内容（Markdown）
+	HTML       string `json:"html,omitempty"` // 渲染并过滤后的 HTML（请求 render=true 时返回）
+	CreatedAt  int64  `json:"created_at"`     // 创建时间（时间戳）
+	UpdatedAt  int64  `json:"updated_at"`     // 最后更新时间（时间戳）
+}
+
+// SaveBookmarkNoteRequest 创建、编辑笔记请求
+type SaveBookmarkNoteRequest struct {
+	Content string `json:"content" binding:"required,max=1000000"` // 笔记内容（Markdown）
+}
+
+// BookmarkNoteListRequest 笔记列表请求
+type BookmarkNoteListRequest struct {
+	Render bool `form:"render" json:"render"` // 是否同时返回渲染后的 HTML
+}
+
+// PreviewNoteRequest 预览笔记请求
+type PreviewNoteRequest struct {
+	Content string `json:"content" binding:"max=1000000"` // 笔记内容（Markdown）
+}
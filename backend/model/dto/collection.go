@@ -0,0 +1,36 @@
+package dto
+
+// CollectionItem 收藏夹（树形结构）
+type CollectionItem struct {
+	ID        int              `json:"id"`
+	ParentID  int              `json:"parent_id"`  // 上级收藏夹ID，0 为顶级
+	Name      string           `json:"name"`       // 名称
+	Position  int              `json:"position"`   // 同级收藏夹中的排序
+	Count     int              `json:"count"`      // 直接包含的书签数量（不含回收站与下级收藏夹）
+	CreatedAt int64            `json:"created_at"` // 创建时间（时间戳）
+	UpdatedAt int64            `json:"updated_at"` // 最后更新时间（时间戳）
+	Children  []CollectionItem `json:"children"`   // 下级收藏夹
+}
+
+// SaveCollectionRequest 创建、编辑收藏夹请求
+type SaveCollectionRequest struct {
+	Name     string `json:"name" binding:"required,max=250"` // 名称
+	ParentID int    `json:"parent_id" binding:"min=0"`       // 上级收藏夹ID，0 为顶级
+}
+
+// ReorderCollectionRequest 同级收藏夹排序请求
+type ReorderCollectionRequest struct {
+	ParentID int   `json:"parent_id" binding:"min=0"`    // 上级收藏夹ID，0 为顶级
+	IDs      []int `json:"ids" binding:"required,min=1"` // 按新顺序排列的收藏夹ID，未列出的收藏夹排在后面
+}
+
+// CollectionBookmarksRequest 收藏夹书签列表请求
+type CollectionBookmarksRequest struct {
+	Page     int `form:"page" json:"page" binding:"required,min=1"` // 页码
+	PageSize int `form:"page_size" json:"page_size"`                // 每页记录数，默认10
+}
+
+// CollectionBookmarkIDsRequest 添加、移除、排序收藏夹书签请求
+type CollectionBookmarkIDsRequest struct {
+	BookmarkIDs []int `json:"bookmark_ids" binding:"required,min=1"` // 书签ID，排序时按新顺序排列，未列出的书签排在后面
+}
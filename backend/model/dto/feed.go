@@ -0,0 +1,15 @@
+package dto
+
+// FeedTokenData 书签订阅源地址
+type FeedTokenData struct {
+	RSS  string `json:"rss"`  // RSS 2.0 订阅地址
+	Atom string `json:"atom"` // Atom 1.0 订阅地址
+	JSON string `json:"json"` // JSON Feed 1.1 订阅地址
+}
+
+// BookmarkFeedRequest 书签订阅源请求
+type BookmarkFeedRequest struct {
+	Format  string `form:"format"`  // 订阅源格式：rss（默认）, atom, json
+	Keyword string `form:"keyword"` // 内容查询关键字
+	Tags    string `form:"tags"`    // tag列表，使用英文的逗号分隔多个tag，tag name全匹配
+}
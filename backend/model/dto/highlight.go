@@ -0,0 +1,43 @@
+package dto
+
+// HighlightItem 高亮与批注
+type HighlightItem struct {
+	ID          int    `json:"id"`
+	BookmarkID  int    `json:"bookmark_id"`
+	Quote       string `json:"quote"`        // 选中的文本
+	Prefix      string `json:"prefix"`       // 选中文本之前的文本
+	Suffix      string `json:"suffix"`       // 选中文本之后的文本
+	StartOffset int    `json:"start_offset"` // 在归档正文中的起始位置（字符）
+	EndOffset   int    `json:"end_offset"`   // 在归档正文中的结束位置（字符，不含）
+	Orphaned    bool   `json:"orphaned"`     // 重新归档后找不到原文
+	Note        string `json:"note"`         // 批注
+	Color       string `json:"color"`        // 高亮颜色
+	CreatedAt   int64  `json:"created_at"`   // 创建时间（时间戳）
+	UpdatedAt   int64  `json:"updated_at"`   // 最后更新时间（时间戳）
+
+	BookmarkURL   string `json:"bookmark_url,omitempty"`   // 书签原文地址（搜索结果中返回）
+	BookmarkTitle string `json:"bookmark_title,omitempty"` // 书签标题（搜索结果中返回）
+}
+
+// CreateHighlightRequest 创建高亮请求，位置按归档正文（GetContent 返回的 HTML 的文本内容）的字符计算
+type CreateHighlightRequest struct {
+	Quote       string `json:"quote" binding:"required,max=10000"`                            // 选中的文本
+	Prefix      string `json:"prefix" binding:"max=200"`                                      // 选中文本之前的文本（建议 32 个字符）
+	Suffix      string `json:"suffix" binding:"max=200"`                                      // 选中文本之后的文本（建议 32 个字符）
+	StartOffset int    `json:"start_offset" binding:"min=0"`                                  // 起始位置，选中文本多次出现时用于区分
+	Note        string `json:"note" binding:"max=10000"`                                      // 批注
+	Color       string `json:"color" binding:"omitempty,oneof=yellow green blue pink purple"` // 高亮颜色，默认 yellow
+}
+
+// UpdateHighlightRequest 修改高亮请求
+type UpdateHighlightRequest struct {
+	Note  string `json:"note" binding:"max=10000"`                                      // 批注
+	Color string `json:"color" binding:"omitempty,oneof=yellow green blue pink purple"` // 高亮颜色，为空保持不变
+}
+
+// HighlightSearchRequest 高亮搜索请求
+type HighlightSearchRequest struct {
+	Keyword  string `form:"keyword" json:"keyword"`                    // 关键字，查询范围：选中的文本、批注
+	Page     int    `form:"page" json:"page" binding:"required,min=1"` // 页码
+	PageSize int    `form:"page_size" json:"page_size"`                // 每页记录数，默认10
+}
@@ -0,0 +1,24 @@
+package dto
+
+// PersonalTokenItem 个人访问令牌
+type PersonalTokenItem struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`         // 名称
+	Prefix     string `json:"prefix"`       // 令牌前几位，用于识别
+	ExpiresAt  int64  `json:"expires_at"`   // 过期时间（时间戳），0 表示永不过期
+	Expired    bool   `json:"expired"`      // 是否已过期
+	LastUsedAt int64  `json:"last_used_at"` // 最近使用时间（时间戳），0 表示未使用
+	CreatedAt  int64  `json:"created_at"`   // 创建时间（时间戳）
+}
+
+// CreatePersonalTokenRequest 创建个人访问令牌请求
+type CreatePersonalTokenRequest struct {
+	Name      string `json:"name" binding:"required,max=100"` // 名称
+	ExpiresAt int64  `json:"expires_at" binding:"min=0"`      // 过期时间（时间戳），0 表示永不过期
+}
+
+// CreatePersonalTokenData 创建个人访问令牌结果，令牌明文只在创建时返回一次
+type CreatePersonalTokenData struct {
+	PersonalTokenItem
+	Token string `json:"token"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+// SavedSearchItem 保存的搜索
+type SavedSearchItem struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`       // 名称
+	Keyword   string   `json:"keyword"`    // 全文搜索关键字
+	Tags      []string `json:"tags"`       // 标签列表，书签需包含全部标签
+	Shared    bool     `json:"shared"`     // 是否共享给其他用户
+	Owner     string   `json:"owner"`      // 创建者用户名
+	IsOwner   bool     `json:"is_owner"`   // 是否为当前用户创建
+	Count     int64    `json:"count"`      // 当前满足条件的书签数量
+	FeedURL   string   `json:"feed_url"`   // 订阅地址（RSS 2.0，追加 ?format=json 获取 JSON Feed）
+	CreatedAt int64    `json:"created_at"` // 创建时间（时间戳）
+	UpdatedAt int64    `json:"updated_at"` // 最后更新时间（时间戳）
+}
+
+// SaveSavedSearchRequest 创建、编辑保存的搜索请求，keyword 与 tags 至少提供一项
+type SaveSavedSearchRequest struct {
+	Name    string   `json:"name" binding:"required,max=100"` // 名称
+	Keyword string   `json:"keyword" binding:"max=500"`       // 全文搜索关键字
+	Tags    []string `json:"tags"`                            // 标签列表
+	Shared  bool     `json:"shared"`                          // 是否共享给其他用户
+}
+
+// SavedSearchBookmarksRequest 保存的搜索书签列表请求
+type SavedSearchBookmarksRequest struct {
+	Page     int `form:"page" json:"page" binding:"required,min=1"` // 页码
+	PageSize int `form:"page_size" json:"page_size"`                // 每页记录数，默认10
+}
@@ -0,0 +1,24 @@
+package dto
+
+// ShareItem 分享链接
+type ShareItem struct {
+	ID           int    `json:"id"`
+	TargetType   string `json:"target_type"`    // 分享对象类型：bookmark, tag, saved_search, collection
+	TargetID     int    `json:"target_id"`      // 分享对象ID
+	TargetName   string `json:"target_name"`    // 分享对象名称（书签标题、标签名等），对象已删除时为空
+	HasPassword  bool   `json:"has_password"`   // 是否需要密码
+	ExpiresAt    int64  `json:"expires_at"`     // 过期时间（时间戳），0 表示永不过期
+	Expired      bool   `json:"expired"`        // 是否已过期
+	ViewCount    int    `json:"view_count"`     // 访问次数
+	LastViewedAt int64  `json:"last_viewed_at"` // 最近访问时间（时间戳），0 表示未访问
+	URL          string `json:"url"`            // 公开访问地址
+	CreatedAt    int64  `json:"created_at"`     // 创建时间（时间戳）
+}
+
+// CreateShareRequest 创建分享链接请求
+type CreateShareRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=bookmark tag saved_search collection"` // 分享对象类型
+	TargetID   int    `json:"target_id" binding:"required,min=1"`                                        // 分享对象ID
+	Password   string `json:"password" binding:"max=50"`                                                 // 访问密码，为空表示无需密码
+	ExpiresAt  int64  `json:"expires_at" binding:"min=0"`                                                // 过期时间（时间戳），0 表示永不过期
+}
@@ -0,0 +1,27 @@
+package dto
+
+// SubscriptionItem 订阅源及其拉取状态
+type SubscriptionItem struct {
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`            // 名称
+	URL           string   `json:"url"`             // 订阅源地址（RSS、Atom 或 JSON Feed）
+	Tags          []string `json:"tags"`            // 新书签的默认标签
+	Archive       bool     `json:"archive"`         // 是否自动归档网页内容
+	Enabled       bool     `json:"enabled"`         // 是否启用定时拉取
+	LastCheckedAt int64    `json:"last_checked_at"` // 最近一次拉取时间（时间戳），0 表示尚未拉取
+	LastSuccessAt int64    `json:"last_success_at"` // 最近一次拉取成功时间（时间戳），0 表示从未成功
+	LastError     string   `json:"last_error"`      // 最近一次拉取的错误信息，成功时为空
+	ErrorCount    int      `json:"error_count"`     // 连续失败次数
+	ItemCount     int      `json:"item_count"`      // 累计创建的书签数量
+	CreatedAt     int64    `json:"created_at"`      // 创建时间（时间戳）
+	UpdatedAt     int64    `json:"updated_at"`      // 最后更新时间（时间戳）
+}
+
+// SaveSubscriptionRequest 创建、编辑订阅源请求
+type SaveSubscriptionRequest struct {
+	Name    string   `json:"name" binding:"required,max=100"`     // 名称
+	URL     string   `json:"url" binding:"required,url,max=2000"` // 订阅源地址
+	Tags    []string `json:"tags"`                                // 新书签的默认标签
+	Archive bool     `json:"archive"`                             // 是否自动归档网页内容
+	Enabled *bool    `json:"enabled"`                             // 是否启用定时拉取，默认启用
+}
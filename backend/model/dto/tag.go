@@ -23,3 +23,42 @@ type TagListResponse struct {
 type UpdateTagRequest struct {
 	Name string `json:"name" binding:"required"` // 新的tag名称
 }
+
+// MergeTagRequest tag合并请求
+type MergeTagRequest struct {
+	SourceIDs []int `json:"source_ids" binding:"required,min=1"` // 被合并的tag，合并后删除
+	TargetID  int   `json:"target_id" binding:"required,min=1"`  // 合并到的tag
+}
+
+// SuggestTagRequest 标签推荐请求，bookmark_id、url、content 至少提供一项
+type SuggestTagRequest struct {
+	BookmarkID   int      `json:"bookmark_id"`                            // 为已有书签推荐标签，使用书签的归档内容
+	URL          string   `json:"url"`                                    // 书签原文地址
+	Title        string   `json:"title"`                                  // 标题
+	Content      string   `json:"content"`                                // 正文
+	FetchContent bool     `json:"fetch_content"`                          // 未提供正文时是否抓取 URL 的网页内容
+	Tags         []string `json:"tags"`                                   // 已选择的标签名称
+	Limit        int      `json:"limit" binding:"omitempty,min=1,max=50"` // 返回数量，默认10
+}
+
+// SuggestTagData 标签推荐结果
+type SuggestTagData struct {
+	Keywords []string         `json:"keywords"` // 正文关键词
+	Tags     []SuggestTagItem `json:"tags"`     // 推荐的标签，按置信度降序
+}
+
+// SuggestTagItem 推荐的标签
+type SuggestTagItem struct {
+	ID     int              `json:"id"`
+	Name   string           `json:"name"`   // 标签名称
+	Score  float64          `json:"score"`  // 综合置信度（0~1）
+	Scores SuggestTagScores `json:"scores"` // 置信度明细
+}
+
+// SuggestTagScores 标签推荐置信度明细，综合置信度 = 0.3*keyword + 0.3*similar + 0.2*cooccur + 0.2*domain
+type SuggestTagScores struct {
+	Keyword float64 `json:"keyword"` // 标签名与正文关键词的匹配程度
+	Similar float64 `json:"similar"` // 正文相似书签中使用该标签的比例
+	Cooccur float64 `json:"cooccur"` // 与已选标签共同出现的条件概率
+	Domain  float64 `json:"domain"`  // 同域名书签中使用该标签的比例
+}
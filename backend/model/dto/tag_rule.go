@@ -0,0 +1,46 @@
+package dto
+
+// TagRuleItem 自动标签规则
+type TagRuleItem struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`       // 规则名称
+	Field     string    `json:"field"`      // 匹配字段：domain, url, title, content, folder
+	MatchType string    `json:"match_type"` // 匹配方式：glob, regex, contains, keywords
+	Pattern   string    `json:"pattern"`    // 匹配规则
+	Enabled   bool      `json:"enabled"`    // 是否启用
+	Tags      []TagItem `json:"tags"`       // 满足条件时添加的标签
+	CreatedAt int64     `json:"created_at"` // 创建时间（时间戳）
+	UpdatedAt int64     `json:"updated_at"` // 最后更新时间（时间戳）
+}
+
+// SaveTagRuleRequest 创建、编辑自动标签规则请求
+type SaveTagRuleRequest struct {
+	Name      string   `json:"name" binding:"required,max=100"`                                  // 规则名称
+	Field     string   `json:"field" binding:"required,oneof=domain url title content folder"`   // 匹配字段
+	MatchType string   `json:"match_type" binding:"required,oneof=glob regex contains keywords"` // 匹配方式
+	Pattern   string   `json:"pattern" binding:"required,max=1000"`                              // 匹配规则，keywords 使用英文逗号分隔多个关键词
+	Enabled   bool     `json:"enabled"`                                                          // 是否启用
+	Tags      []string `json:"tags" binding:"required,min=1"`                                    // 满足条件时添加的标签名称，不存在时自动创建
+}
+
+// ApplyTagRulesRequest 对已有书签执行自动标签规则请求
+type ApplyTagRulesRequest struct {
+	RuleIDs []int `json:"rule_ids"` // 执行的规则ID，为空时执行全部启用的规则
+	DryRun  bool  `json:"dry_run"`  // 只预览将要发生的变更，不修改数据
+}
+
+// ApplyTagRulesData 执行自动标签规则结果
+type ApplyTagRulesData struct {
+	DryRun  bool            `json:"dry_run"` // 是否为预览
+	Total   int             `json:"total"`   // 将要（或已经）添加标签的书签数量
+	Changes []TagRuleChange `json:"changes"` // 变更明细
+}
+
+// TagRuleChange 单个书签的标签变更
+type TagRuleChange struct {
+	BookmarkID int      `json:"bookmark_id"`
+	URL        string   `json:"url"`      // 书签原文地址
+	Title      string   `json:"title"`    // 标题
+	AddTags    []string `json:"add_tags"` // 添加的标签名称
+	RuleIDs    []int    `json:"rule_ids"` // 命中的规则ID
+}
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/utils"
+)
+
+type AuditRepo struct{}
+
+// AuditFilter 审计日志查询条件
+type AuditFilter struct {
+	UserID     int
+	Action     string
+	EntityType string
+	EntityID   int
+	Start      time.Time
+	End        time.Time
+}
+
+// Create 写入审计日志
+func (r *AuditRepo) Create(log *db.AuditLog) error {
+	return lib.DB.Create(log).Error
+}
+
+// Record 以指定身份（如命令行的 cli、后台任务的 system）写入没有请求上下文的审计日志，失败只记录日志
+func (r *AuditRepo) Record(username, action, entityType string, entityIDs []int, after interface{}) {
+	diff, err := utils.DiffJSON(nil, after)
+	if err != nil {
+		lib.Logger.Error("生成审计变更内容失败: " + err.Error())
+	}
+	ids := make([]string, 0, len(entityIDs))
+	for _, id := range entityIDs {
+		ids = append(ids, strconv.Itoa(id))
+	}
+	log := &db.AuditLog{
+		Username:   username,
+		Action:     action,
+		EntityType: entityType,
+		EntityIDs:  strings.Join(ids, ","),
+		Diff:       diff,
+	}
+	if err := r.Create(log); err != nil {
+		lib.Logger.Error("写入审计日志失败: " + err.Error())
+	}
+}
+
+// List 查询审计日志列表
+func (r *AuditRepo) List(filter AuditFilter, page, pageSize int) ([]db.AuditLog, int64, error) {
+	var logs []db.AuditLog
+	var total int64
+
+	query := lib.DB.Model(&db.AuditLog{})
+
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID > 0 {
+		query = query.Where("FIND_IN_SET(?, entity_ids)", strconv.Itoa(filter.EntityID))
+	}
+	if !filter.Start.IsZero() {
+		query = query.Where("created_at >= ?", filter.Start)
+	}
+	if !filter.End.IsZero() {
+		query = query.Where("created_at < ?", filter.End)
+	}
+
+	// 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&logs).Error
+
+	return logs, total, err
+}
@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"bk_kms/lib"
+	"bk_kms/model/db"
+)
+
+type BookmarkNoteRepo struct{}
+
+// ListByBookmark 查询用户在书签中的笔记，按创建时间排序
+func (r *BookmarkNoteRepo) ListByBookmark(userID, bookmarkID int) ([]db.BookmarkNote, error) {
+	var notes []db.BookmarkNote
+	err := lib.DB.Where("user_id = ? AND bookmark_id = ?", userID, bookmarkID).
+		Order("created_at ASC, id ASC").
+		Find(&notes).Error
+	return notes, err
+}
+
+// FindByID 根据ID查找笔记
+func (r *BookmarkNoteRepo) FindByID(id int) (*db.BookmarkNote, error) {
+	var note db.BookmarkNote
+	err := lib.DB.First(&note, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// Create 创建笔记
+func (r *BookmarkNoteRepo) Create(note *db.BookmarkNote) error {
+	return lib.DB.Create(note).Error
+}
+
+// Update 更新笔记内容
+func (r *BookmarkNoteRepo) Update(note *db.BookmarkNote) error {
+	return lib.DB.Model(note).Select("content").Updates(note).Error
+}
+
+// Delete 删除笔记
+func (r *BookmarkNoteRepo) Delete(id int) error {
+	return lib.DB.Delete(&db.BookmarkNote{}, id).Error
+}
+
+// ListByBookmarks 批量查询用户在书签中的笔记，返回 书签ID -> 笔记（按创建时间排序）
+func (r *BookmarkNoteRepo) ListByBookmarks(userID int, bookmarkIDs []int) (map[int][]db.BookmarkNote, error) {
+	result := make(map[int][]db.BookmarkNote)
+	if len(bookmarkIDs) == 0 {
+		return result, nil
+	}
+	var notes []db.BookmarkNote
+	if err := lib.DB.Where("user_id = ? AND bookmark_id IN ?", userID, bookmarkIDs).
+		Order("created_at ASC, id ASC").
+		Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	for _, note := range notes {
+		result[note.BookmarkID] = append(result[note.BookmarkID], note)
+	}
+	return result, nil
+}
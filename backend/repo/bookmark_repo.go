@@ -3,37 +3,89 @@ package repo
 import (
 	"bk_kms/lib"
 	"bk_kms/model/db"
+	"bk_kms/utils"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type BookmarkRepo struct{}
 
-// List 查询书签列表
-func (r *BookmarkRepo) List(keyword string, tags []string, page, pageSize int) ([]db.Bookmark, int64, error) {
-	var bookmarks []db.Bookmark
-	var total int64
+// BookmarkFilter 书签查询条件
+type BookmarkFilter struct {
+	Keyword string   // 全文搜索关键字，查询范围：url、title、excerpt、content
+	Tags    []string // 标签名称，书签需包含全部标签
+	IDs     []int    // 限定书签ID，为空表示不限定
+	Archive *bool    // 是否已存档，nil 表示不限定
 
+	// 阅读状态过滤与笔记搜索，按 UserID 对应用户的阅读状态与笔记
+	UserID   int
+	States   []string // 阅读状态，满足其一即可，为空表示不限定
+	Favorite *bool    // 是否收藏，nil 表示不限定
+}
+
+// filterQuery 根据查询条件构造查询
+func (r *BookmarkRepo) filterQuery(filter BookmarkFilter) *gorm.DB {
 	query := lib.DB.Model(&db.Bookmark{})
 
-	// 关键字搜索
-	if keyword != "" {
-		query = query.Where(
-			"MATCH (url, title, excerpt, content) AGAINST (? IN BOOLEAN MODE)",
-			keyword,
+	if len(filter.IDs) > 0 {
+		query = query.Where("bookmark.id IN ?", filter.IDs)
+	}
+	if filter.Archive != nil {
+		query = query.Where("bookmark.is_archive = ?", *filter.Archive)
+	}
+
+	// 关键字搜索，指定用户时同时搜索该用户的笔记
+	if filter.Keyword != "" {
+		if filter.UserID > 0 {
+			query = query.Where(
+				"(MATCH (url, title, excerpt, content) AGAINST (? IN BOOLEAN MODE) OR bookmark.id IN "+
+					"(SELECT bookmark_note.bookmark_id FROM bookmark_note WHERE bookmark_note.user_id = ? "+
+					"AND MATCH (bookmark_note.content) AGAINST (? IN BOOLEAN MODE)))",
+				filter.Keyword, filter.UserID, filter.Keyword,
+			)
+		} else {
+			query = query.Where(
+				"MATCH (url, title, excerpt, content) AGAINST (? IN BOOLEAN MODE)",
+				filter.Keyword,
+			)
+		}
+	}
+
+	// 阅读状态过滤，没有阅读状态记录的书签视为未读、未收藏
+	if filter.UserID > 0 && (len(filter.States) > 0 || filter.Favorite != nil) {
+		query = query.Joins(
+			"LEFT JOIN bookmark_state ON bookmark.id = bookmark_state.bookmark_id AND bookmark_state.user_id = ?",
+			filter.UserID,
 		)
+		if len(filter.States) > 0 {
+			query = query.Where("COALESCE(bookmark_state.state, ?) IN ?", db.BookmarkStateUnread, filter.States)
+		}
+		if filter.Favorite != nil {
+			query = query.Where("COALESCE(bookmark_state.favorite, 0) = ?", *filter.Favorite)
+		}
 	}
 
 	// 标签过滤
-	if len(tags) > 0 {
+	if len(filter.Tags) > 0 {
 		query = query.Joins("JOIN bookmark_tag ON bookmark.id = bookmark_tag.bookmark_id").
 			Joins("JOIN tag ON bookmark_tag.tag_id = tag.id").
-			Where("tag.name IN ?", tags).
+			Where("tag.name IN ?", filter.Tags).
 			Group("bookmark.id").
-			Having("COUNT(DISTINCT tag.id) = ?", len(tags))
+			Having("COUNT(DISTINCT tag.id) = ?", len(filter.Tags))
 	}
 
+	return query
+}
+
+// List 查询书签列表
+func (r *BookmarkRepo) List(filter BookmarkFilter, page, pageSize int) ([]db.Bookmark, int64, error) {
+	var bookmarks []db.Bookmark
+	var total int64
+
+	query := r.filterQuery(filter)
+
 	// 统计总数
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -50,6 +102,20 @@ func (r *BookmarkRepo) List(keyword string, tags []string, page, pageSize int) (
 	return bookmarks, total, err
 }
 
+// ListIDs 查询满足条件的全部书签ID，按创建时间倒序，用于导出等需要分批处理的场景
+func (r *BookmarkRepo) ListIDs(filter BookmarkFilter) ([]int, error) {
+	var ids []int
+	err := r.filterQuery(filter).Order("bookmark.created_at DESC").Pluck("bookmark.id", &ids).Error
+	return ids, err
+}
+
+// Count 统计满足条件的书签数量
+func (r *BookmarkRepo) Count(filter BookmarkFilter) (int64, error) {
+	var total int64
+	err := r.filterQuery(filter).Count(&total).Error
+	return total, err
+}
+
 // FindByID 根据ID查找书签
 func (r *BookmarkRepo) FindByID(id int) (*db.Bookmark, error) {
 	var bookmark db.Bookmark
@@ -60,38 +126,424 @@ func (r *BookmarkRepo) FindByID(id int) (*db.Bookmark, error) {
 	return &bookmark, nil
 }
 
-// FindByURL 根据URL查找书签
+// FindByURL 根据URL查找书签（按规范化 URL 判重，兼容尚未生成规范化 URL 的旧数据）
 func (r *BookmarkRepo) FindByURL(url string) (*db.Bookmark, error) {
 	var bookmark db.Bookmark
-	err := lib.DB.Where("url = ?", url).First(&bookmark).Error
+	err := lib.DB.Where("canonical_url = ? OR url = ?", canonicalURL(url), url).First(&bookmark).Error
 	if err != nil {
 		return nil, err
 	}
 	return &bookmark, nil
 }
 
-// Create 创建书签
+// ExistsByURL URL 相同（或规范化后相同）的书签是否存在，包括回收站中的书签
+func (r *BookmarkRepo) ExistsByURL(url string) (bool, error) {
+	var count int64
+	err := lib.DB.Unscoped().Model(&db.Bookmark{}).
+		Where("canonical_url = ? OR url = ?", canonicalURL(url), url).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create 创建书签（URL 唯一索引只约束未删除的书签，回收站中相同 URL 的书签不受影响）
 func (r *BookmarkRepo) Create(bookmark *db.Bookmark) error {
+	bookmark.CanonicalURL = canonicalURL(bookmark.URL)
+	bookmark.SimHash = utils.SimHash(bookmark.Content)
 	return lib.DB.Create(bookmark).Error
 }
 
 // Update 更新书签
 func (r *BookmarkRepo) Update(bookmark *db.Bookmark) error {
+	bookmark.CanonicalURL = canonicalURL(bookmark.URL)
+	bookmark.SimHash = utils.SimHash(bookmark.Content)
 	return lib.DB.Session(&gorm.Session{FullSaveAssociations: true}).Updates(bookmark).Error
 }
 
-// Delete 删除书签
+// UpdateArchive 只更新书签的归档内容（作者、正文、HTML 与正文指纹），withTitle 为 true 时同时更新标题与摘要
+func (r *BookmarkRepo) UpdateArchive(bookmark *db.Bookmark, withTitle bool) error {
+	bookmark.SimHash = utils.SimHash(bookmark.Content)
+	columns := []string{"author", "content", "html", "simhash", "is_archive"}
+	if withTitle {
+		columns = append(columns, "title", "excerpt")
+	}
+	return lib.DB.Model(bookmark).Select(columns).Updates(bookmark).Error
+}
+
+// Replace 覆盖书签的全部字段（包括零值）并替换标签，用于原生格式导入
+func (r *BookmarkRepo) Replace(bookmark *db.Bookmark) error {
+	return lib.DB.Transaction(func(tx *gorm.DB) error {
+		bookmark.CanonicalURL = canonicalURL(bookmark.URL)
+		bookmark.SimHash = utils.SimHash(bookmark.Content)
+		if err := tx.Omit("Tags").Save(bookmark).Error; err != nil {
+			return err
+		}
+		return tx.Model(bookmark).Association("Tags").Replace(bookmark.Tags)
+	})
+}
+
+// Delete 删除书签（移入回收站，保留标签关联以便恢复）
 func (r *BookmarkRepo) Delete(ids []int) error {
+	return lib.DB.Delete(&db.Bookmark{}, ids).Error
+}
+
+// ListTrash 查询回收站中的书签
+func (r *BookmarkRepo) ListTrash(keyword string, page, pageSize int) ([]db.Bookmark, int64, error) {
+	var bookmarks []db.Bookmark
+	var total int64
+
+	query := lib.DB.Unscoped().Model(&db.Bookmark{}).Where("deleted_at IS NOT NULL")
+
+	// 关键字搜索
+	if keyword != "" {
+		query = query.Where(
+			"MATCH (url, title, excerpt, content) AGAINST (? IN BOOLEAN MODE)",
+			keyword,
+		)
+	}
+
+	// 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	err := query.Preload("Tags").
+		Order("deleted_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&bookmarks).Error
+
+	return bookmarks, total, err
+}
+
+// FindTrashByIDs 根据ID列表查找回收站中的书签
+func (r *BookmarkRepo) FindTrashByIDs(ids []int) ([]db.Bookmark, error) {
+	var bookmarks []db.Bookmark
+	err := lib.DB.Unscoped().Preload("Tags").
+		Where("id IN ? AND deleted_at IS NOT NULL", ids).
+		Find(&bookmarks).Error
+	return bookmarks, err
+}
+
+// Restore 从回收站恢复书签，已存在相同 URL 的书签时跳过，返回成功恢复的书签ID
+func (r *BookmarkRepo) Restore(ids []int) ([]int, error) {
+	var restored []int
+	err := lib.DB.Transaction(func(tx *gorm.DB) error {
+		var bookmarks []db.Bookmark
+		if err := tx.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", ids).Find(&bookmarks).Error; err != nil {
+			return err
+		}
+
+		for _, bookmark := range bookmarks {
+			var count int64
+			if err := tx.Model(&db.Bookmark{}).
+				Where("url = ? OR canonical_url = ?", bookmark.URL, canonicalURL(bookmark.URL)).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			if err := tx.Unscoped().Model(&db.Bookmark{}).
+				Where("id = ?", bookmark.ID).
+				Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+			restored = append(restored, bookmark.ID)
+		}
+		return nil
+	})
+	return restored, err
+}
+
+// Purge 彻底删除回收站中的书签及其标签关联，返回实际删除的书签ID
+func (r *BookmarkRepo) Purge(ids []int) ([]int, error) {
+	var purged []int
+	err := lib.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&db.Bookmark{}).
+			Where("id IN ? AND deleted_at IS NOT NULL", ids).
+			Pluck("id", &purged).Error; err != nil {
+			return err
+		}
+		return purgeBookmarks(tx, purged)
+	})
+	return purged, err
+}
+
+// PurgeDeletedBefore 彻底删除在指定时间之前移入回收站的书签，返回删除的书签ID
+func (r *BookmarkRepo) PurgeDeletedBefore(before time.Time) ([]int, error) {
+	var ids []int
+	if err := lib.DB.Unscoped().Model(&db.Bookmark{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.Purge(ids)
+}
+
+// RefreshCanonicalURLs 重新计算全部书签（包括回收站）的规范化 URL，返回更新的数量
+// 用于旧数据迁移或修改追踪参数规则之后
+func (r *BookmarkRepo) RefreshCanonicalURLs() (int, error) {
+	updated := 0
+	var bookmarks []db.Bookmark
+	err := lib.DB.Unscoped().Select("id", "url", "canonical_url").
+		FindInBatches(&bookmarks, 500, func(tx *gorm.DB, batch int) error {
+			for _, bookmark := range bookmarks {
+				canonical := canonicalURL(bookmark.URL)
+				if canonical == bookmark.CanonicalURL {
+					continue
+				}
+				if err := lib.DB.Unscoped().Model(&db.Bookmark{}).
+					Where("id = ?", bookmark.ID).
+					UpdateColumn("canonical_url", canonical).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		}).Error
+	return updated, err
+}
+
+// FindDuplicates 查找规范化 URL 相同的书签，按规范化 URL 分组
+func (r *BookmarkRepo) FindDuplicates() ([][]db.Bookmark, error) {
+	var canonicalURLs []string
+	if err := lib.DB.Model(&db.Bookmark{}).
+		Where("canonical_url <> ''").
+		Group("canonical_url").
+		Having("COUNT(*) > 1").
+		Order("canonical_url").
+		Pluck("canonical_url", &canonicalURLs).Error; err != nil {
+		return nil, err
+	}
+	if len(canonicalURLs) == 0 {
+		return nil, nil
+	}
+
+	var bookmarks []db.Bookmark
+	if err := lib.DB.Preload("Tags").
+		Where("canonical_url IN ?", canonicalURLs).
+		Order("created_at ASC").
+		Find(&bookmarks).Error; err != nil {
+		return nil, err
+	}
+
+	groupIndex := make(map[string]int, len(canonicalURLs))
+	groups := make([][]db.Bookmark, len(canonicalURLs))
+	for i, canonical := range canonicalURLs {
+		groupIndex[canonical] = i
+	}
+	for _, bookmark := range bookmarks {
+		i := groupIndex[bookmark.CanonicalURL]
+		groups[i] = append(groups[i], bookmark)
+	}
+	return groups, nil
+}
+
+// Merge 合并重复书签：保存保留的书签（含合并后的标签与归档），保留的书签加入其余书签所在的收藏夹、
+// 沿用其余书签的阅读状态、接收其余书签的高亮与笔记，其余书签移入回收站
+func (r *BookmarkRepo) Merge(keep *db.Bookmark, removeIDs []int) error {
 	return lib.DB.Transaction(func(tx *gorm.DB) error {
-		// 删除关联的标签
-		if err := tx.Where("bookmark_id IN ?", ids).Delete(&db.BookmarkTag{}).Error; err != nil {
+		keep.SimHash = utils.SimHash(keep.Content)
+		if err := tx.Model(keep).Select("title", "excerpt", "author", "content", "html", "simhash", "is_archive").
+			Updates(keep).Error; err != nil {
 			return err
 		}
-		// 删除书签
-		return tx.Delete(&db.Bookmark{}, ids).Error
+		if err := tx.Model(keep).Association("Tags").Replace(keep.Tags); err != nil {
+			return err
+		}
+		if len(removeIDs) == 0 {
+			return nil
+		}
+		if err := tx.Exec(
+			"INSERT IGNORE INTO collection_bookmark (collection_id, bookmark_id, position, created_at) "+
+				"SELECT collection_id, ?, position, created_at FROM collection_bookmark WHERE bookmark_id IN ?",
+			keep.ID, removeIDs,
+		).Error; err != nil {
+			return err
+		}
+		// 保留的书签没有阅读状态的用户，沿用被合并书签的阅读状态
+		if err := tx.Exec(
+			"INSERT IGNORE INTO bookmark_state (user_id, bookmark_id, state, favorite, progress, last_opened_at, updated_at) "+
+				"SELECT user_id, ?, state, favorite, progress, last_opened_at, updated_at FROM bookmark_state WHERE bookmark_id IN ?",
+			keep.ID, removeIDs,
+		).Error; err != nil {
+			return err
+		}
+		// 高亮移到保留的书签，由调用方按保留的书签正文重新定位
+		if err := tx.Model(&db.Highlight{}).Where("bookmark_id IN ?", removeIDs).
+			Update("bookmark_id", keep.ID).Error; err != nil {
+			return err
+		}
+		// 笔记移到保留的书签
+		if err := tx.Model(&db.BookmarkNote{}).Where("bookmark_id IN ?", removeIDs).
+			Update("bookmark_id", keep.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&db.Bookmark{}, removeIDs).Error
 	})
 }
 
+// RefreshSimHashes 重新计算全部书签（包括回收站）的正文指纹，返回更新的数量与没有正文（无法计算指纹）的数量
+func (r *BookmarkRepo) RefreshSimHashes() (updated int, empty int, err error) {
+	var bookmarks []db.Bookmark
+	err = lib.DB.Unscoped().Select("id", "content", "simhash").
+		FindInBatches(&bookmarks, 100, func(tx *gorm.DB, batch int) error {
+			for _, bookmark := range bookmarks {
+				simHash := utils.SimHash(bookmark.Content)
+				if simHash == 0 {
+					empty++
+				}
+				if simHash == bookmark.SimHash {
+					continue
+				}
+				if err := lib.DB.Unscoped().Model(&db.Bookmark{}).
+					Where("id = ?", bookmark.ID).
+					UpdateColumn("simhash", simHash).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		}).Error
+	return updated, empty, err
+}
+
+// FindSimilar 查找正文指纹海明距离不超过 maxDistance 的书签（excludeID 除外），按距离排序
+func (r *BookmarkRepo) FindSimilar(simHash uint64, maxDistance int, excludeID int, limit int) ([]db.Bookmark, error) {
+	var bookmarks []db.Bookmark
+	if simHash == 0 {
+		return bookmarks, nil
+	}
+	err := lib.DB.Select("id", "url", "title", "simhash").
+		Where("simhash <> 0 AND id <> ? AND BIT_COUNT(simhash ^ ?) <= ?", excludeID, simHash, maxDistance).
+		Order(gorm.Expr("BIT_COUNT(simhash ^ ?)", simHash)).
+		Limit(limit).
+		Find(&bookmarks).Error
+	return bookmarks, err
+}
+
+// FindNearDuplicates 查找正文指纹相近的书签，按相似关系聚类分组（每组至少两个书签）
+func (r *BookmarkRepo) FindNearDuplicates(maxDistance int) ([][]db.Bookmark, error) {
+	var fingerprints []db.Bookmark
+	if err := lib.DB.Select("id", "simhash").
+		Where("simhash <> 0").
+		Order("id").
+		Find(&fingerprints).Error; err != nil {
+		return nil, err
+	}
+
+	// 并查集聚类：两两比较指纹，距离不超过阈值的书签归入同一组
+	parent := make([]int, len(fingerprints))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := 0; i < len(fingerprints); i++ {
+		for j := i + 1; j < len(fingerprints); j++ {
+			if utils.SimHashDistance(fingerprints[i].SimHash, fingerprints[j].SimHash) <= maxDistance {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	clusters := make(map[int][]int)
+	var roots []int
+	for i := range fingerprints {
+		root := find(i)
+		if _, ok := clusters[root]; !ok {
+			roots = append(roots, root)
+		}
+		clusters[root] = append(clusters[root], fingerprints[i].ID)
+	}
+
+	var ids []int
+	for _, root := range roots {
+		if len(clusters[root]) > 1 {
+			ids = append(ids, clusters[root]...)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var bookmarks []db.Bookmark
+	if err := lib.DB.Preload("Tags").
+		Omit("content", "html").
+		Where("id IN ?", ids).
+		Order("created_at ASC").
+		Find(&bookmarks).Error; err != nil {
+		return nil, err
+	}
+
+	idRoot := make(map[int]int, len(fingerprints))
+	for i := range fingerprints {
+		idRoot[fingerprints[i].ID] = find(i)
+	}
+	groupIndex := make(map[int]int)
+	var groups [][]db.Bookmark
+	for _, bookmark := range bookmarks {
+		root := idRoot[bookmark.ID]
+		i, ok := groupIndex[root]
+		if !ok {
+			i = len(groups)
+			groupIndex[root] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], bookmark)
+	}
+	return groups, nil
+}
+
+// canonicalURL 生成规范化 URL，解析失败时使用原始 URL
+func canonicalURL(url string) string {
+	canonical, err := utils.CanonicalizeURL(url)
+	if err != nil {
+		return url
+	}
+	return canonical
+}
+
+// purgeBookmarks 彻底删除书签及其关联数据
+func purgeBookmarks(tx *gorm.DB, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	// 删除关联的标签
+	if err := tx.Where("bookmark_id IN ?", ids).Delete(&db.BookmarkTag{}).Error; err != nil {
+		return err
+	}
+	// 从收藏夹中移除
+	if err := tx.Where("bookmark_id IN ?", ids).Delete(&db.CollectionBookmark{}).Error; err != nil {
+		return err
+	}
+	// 删除阅读状态
+	if err := tx.Where("bookmark_id IN ?", ids).Delete(&db.BookmarkState{}).Error; err != nil {
+		return err
+	}
+	// 删除高亮
+	if err := tx.Where("bookmark_id IN ?", ids).Delete(&db.Highlight{}).Error; err != nil {
+		return err
+	}
+	// 删除笔记
+	if err := tx.Where("bookmark_id IN ?", ids).Delete(&db.BookmarkNote{}).Error; err != nil {
+		return err
+	}
+	// 删除书签
+	return tx.Unscoped().Delete(&db.Bookmark{}, ids).Error
+}
+
 // FindOrCreateTags 查找或创建标签
 func (r *BookmarkRepo) FindOrCreateTags(tagNames []string) ([]db.Tag, error) {
 	var tags []db.Tag
@@ -119,3 +571,24 @@ func (r *BookmarkRepo) FindOrCreateTags(tagNames []string) ([]db.Tag, error) {
 
 	return tags, nil
 }
+
+// AddTags 为书签追加标签
+func (r *BookmarkRepo) AddTags(bookmark *db.Bookmark, tags []db.Tag) error {
+	return lib.DB.Model(bookmark).Association("Tags").Append(tags)
+}
+
+// EachBatch 分批遍历全部书签（不含回收站与原始 HTML），用于构建索引等全量处理
+func (r *BookmarkRepo) EachBatch(batchSize int, fn func(bookmarks []db.Bookmark) error) error {
+	var bookmarks []db.Bookmark
+	return lib.DB.Preload("Tags").Omit("html").
+		FindInBatches(&bookmarks, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(bookmarks)
+		}).Error
+}
+
+// FindByIDs 根据ID列表查找书签
+func (r *BookmarkRepo) FindByIDs(ids []int) ([]db.Bookmark, error) {
+	var bookmarks []db.Bookmark
+	err := lib.DB.Preload("Tags").Where("id IN ?", ids).Find(&bookmarks).Error
+	return bookmarks, err
+}
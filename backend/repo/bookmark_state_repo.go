@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+)
+
+type BookmarkStateRepo struct{}
+
+// BookmarkStateUpdate 阅读状态的修改内容，为 nil 的字段保持不变
+type BookmarkStateUpdate struct {
+	State    *string
+	Favorite *bool
+	Progress *int
+}
+
+// Find 查询用户对书签的阅读状态，没有记录时返回默认状态
+func (r *BookmarkStateRepo) Find(userID, bookmarkID int) (*db.BookmarkState, error) {
+	var state db.BookmarkState
+	err := lib.DB.Where("user_id = ? AND bookmark_id = ?", userID, bookmarkID).First(&state).Error
+	if err == gorm.ErrRecordNotFound {
+		state = db.DefaultBookmarkState(userID, bookmarkID)
+		return &state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// FindByBookmarkIDs 批量查询用户对书签的阅读状态，返回 书签ID -> 状态，没有记录的书签使用默认状态
+func (r *BookmarkStateRepo) FindByBookmarkIDs(userID int, bookmarkIDs []int) (map[int]db.BookmarkState, error) {
+	states := make(map[int]db.BookmarkState, len(bookmarkIDs))
+	for _, id := range bookmarkIDs {
+		states[id] = db.DefaultBookmarkState(userID, id)
+	}
+	if len(bookmarkIDs) == 0 {
+		return states, nil
+	}
+
+	var rows []db.BookmarkState
+	if err := lib.DB.Where("user_id = ? AND bookmark_id IN ?", userID, bookmarkIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		states[row.BookmarkID] = row
+	}
+	return states, nil
+}
+
+// Update 批量修改用户对书签的阅读状态，没有记录时按默认状态创建
+func (r *BookmarkStateRepo) Update(userID int, bookmarkIDs []int, update BookmarkStateUpdate) error {
+	columns := make([]string, 0, 4)
+	if update.State != nil {
+		columns = append(columns, "state")
+	}
+	if update.Favorite != nil {
+		columns = append(columns, "favorite")
+	}
+	if update.Progress != nil {
+		columns = append(columns, "progress")
+	}
+	if len(columns) == 0 || len(bookmarkIDs) == 0 {
+		return nil
+	}
+	columns = append(columns, "updated_at")
+
+	states := make([]db.BookmarkState, 0, len(bookmarkIDs))
+	for _, id := range bookmarkIDs {
+		state := db.DefaultBookmarkState(userID, id)
+		if update.State != nil {
+			state.State = *update.State
+		}
+		if update.Favorite != nil {
+			state.Favorite = *update.Favorite
+		}
+		if update.Progress != nil {
+			state.Progress = *update.Progress
+		}
+		states = append(states, state)
+	}
+	return lib.DB.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns(columns)}).Create(&states).Error
+}
+
+// MarkOpened 记录用户打开书签的时间
+func (r *BookmarkStateRepo) MarkOpened(userID, bookmarkID int) error {
+	state := db.DefaultBookmarkState(userID, bookmarkID)
+	now := time.Now()
+	state.LastOpenedAt = &now
+	return lib.DB.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"last_opened_at"})}).
+		Create(&state).Error
+}
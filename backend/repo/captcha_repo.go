@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"time"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+)
+
+// CaptchaRepo 基于数据库的验证码存储，实现 captcha.Store 接口
+type CaptchaRepo struct {
+	Expiration time.Duration
+}
+
+// Set 保存验证码，同时清理已过期的验证码
+func (r *CaptchaRepo) Set(id string, digits []byte) {
+	now := time.Now()
+	if err := lib.DB.Where("expires_at < ?", now).Delete(&db.Captcha{}).Error; err != nil {
+		lib.Logger.Error("清理过期验证码失败: " + err.Error())
+	}
+
+	value := make([]byte, len(digits))
+	for i, d := range digits {
+		value[i] = '0' + d
+	}
+
+	record := &db.Captcha{
+		ID:        id,
+		Digits:    string(value),
+		ExpiresAt: now.Add(r.Expiration),
+	}
+	if err := lib.DB.Save(record).Error; err != nil {
+		lib.Logger.Error("保存验证码失败: " + err.Error())
+	}
+}
+
+// Get 获取未过期的验证码，clear 为 true 时获取后删除
+func (r *CaptchaRepo) Get(id string, clear bool) []byte {
+	var record db.Captcha
+	err := lib.DB.Where("id = ? AND expires_at >= ?", id, time.Now()).First(&record).Error
+	if err != nil {
+		return nil
+	}
+
+	// 并发校验同一验证码时，只有成功删除记录的请求可以拿到验证码
+	if clear {
+		result := lib.DB.Delete(&db.Captcha{}, "id = ?", id)
+		if result.Error != nil {
+			lib.Logger.Error("删除验证码失败: " + result.Error.Error())
+			return nil
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+	}
+
+	digits := make([]byte, len(record.Digits))
+	for i := range record.Digits {
+		digits[i] = record.Digits[i] - '0'
+	}
+	return digits
+}
@@ -0,0 +1,212 @@
+package repo
+
+import (
+	"bk_kms/lib"
+	"bk_kms/model/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CollectionRepo struct{}
+
+// List 查询全部收藏夹，按上级收藏夹与手动排序
+func (r *CollectionRepo) List() ([]db.Collection, error) {
+	var collections []db.Collection
+	err := lib.DB.Order("parent_id ASC, position ASC, id ASC").Find(&collections).Error
+	return collections, err
+}
+
+// CountBookmarks 统计各收藏夹中的书签数量（不含回收站），返回 收藏夹ID -> 数量
+func (r *CollectionRepo) CountBookmarks() (map[int]int, error) {
+	var rows []struct {
+		CollectionID int
+		Count        int
+	}
+	err := lib.DB.Table("collection_bookmark").
+		Select("collection_bookmark.collection_id, COUNT(*) AS count").
+		Joins("JOIN bookmark ON collection_bookmark.bookmark_id = bookmark.id AND bookmark.deleted_at IS NULL").
+		Group("collection_bookmark.collection_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.CollectionID] = row.Count
+	}
+	return counts, nil
+}
+
+// FindByID 根据ID查找收藏夹
+func (r *CollectionRepo) FindByID(id int) (*db.Collection, error) {
+	var collection db.Collection
+	err := lib.DB.First(&collection, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// FindByName 在指定上级收藏夹中按名称查找
+func (r *CollectionRepo) FindByName(parentID int, name string) (*db.Collection, error) {
+	var collection db.Collection
+	err := lib.DB.Where("parent_id = ? AND name = ?", parentID, name).First(&collection).Error
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// NextPosition 同级收藏夹的下一个排序值（排在最后）
+func (r *CollectionRepo) NextPosition(parentID int) (int, error) {
+	var position int
+	err := lib.DB.Model(&db.Collection{}).
+		Where("parent_id = ?", parentID).
+		Select("COALESCE(MAX(position), -1) + 1").
+		Scan(&position).Error
+	return position, err
+}
+
+// Create 创建收藏夹，排在同级收藏夹的最后
+func (r *CollectionRepo) Create(collection *db.Collection) error {
+	position, err := r.NextPosition(collection.ParentID)
+	if err != nil {
+		return err
+	}
+	collection.Position = position
+	return lib.DB.Create(collection).Error
+}
+
+// Update 更新收藏夹名称、上级收藏夹与排序
+func (r *CollectionRepo) Update(collection *db.Collection) error {
+	return lib.DB.Model(collection).Select("name", "parent_id", "position").Updates(collection).Error
+}
+
+// Delete 删除收藏夹及其书签关联（书签本身不删除）
+func (r *CollectionRepo) Delete(ids []int) error {
+	return lib.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id IN ?", ids).Delete(&db.CollectionBookmark{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&db.Collection{}, ids).Error
+	})
+}
+
+// Reorder 按给定顺序重新设置同级收藏夹的排序
+func (r *CollectionRepo) Reorder(parentID int, ids []int) error {
+	return lib.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&db.Collection{}).
+				Where("id = ? AND parent_id = ?", id, parentID).
+				UpdateColumn("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindOrCreatePath 按文件夹路径（从最外层到最内层）逐级查找或创建收藏夹，返回最内层的收藏夹
+func (r *CollectionRepo) FindOrCreatePath(names []string) (*db.Collection, error) {
+	var collection *db.Collection
+	parentID := 0
+	for _, name := range names {
+		found, err := r.FindByName(parentID, name)
+		if err == gorm.ErrRecordNotFound {
+			found = &db.Collection{ParentID: parentID, Name: name}
+			err = r.Create(found)
+		}
+		if err != nil {
+			return nil, err
+		}
+		collection = found
+		parentID = found.ID
+	}
+	return collection, nil
+}
+
+// ListBookmarks 按手动排序分页查询收藏夹中的书签（不含回收站）
+func (r *CollectionRepo) ListBookmarks(collectionID int, page, pageSize int) ([]db.Bookmark, int64, error) {
+	var bookmarks []db.Bookmark
+	var total int64
+
+	query := lib.DB.Model(&db.Bookmark{}).
+		Joins("JOIN collection_bookmark ON bookmark.id = collection_bookmark.bookmark_id").
+		Where("collection_bookmark.collection_id = ?", collectionID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("Tags").
+		Order("collection_bookmark.position ASC, collection_bookmark.created_at ASC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&bookmarks).Error
+
+	return bookmarks, total, err
+}
+
+// BookmarkIDs 收藏夹中全部书签ID（包括回收站中的书签），按手动排序
+func (r *CollectionRepo) BookmarkIDs(collectionID int) ([]int, error) {
+	var ids []int
+	err := lib.DB.Model(&db.CollectionBookmark{}).
+		Where("collection_id = ?", collectionID).
+		Order("position ASC, created_at ASC").
+		Pluck("bookmark_id", &ids).Error
+	return ids, err
+}
+
+// AddBookmarks 将书签添加到收藏夹末尾，已在收藏夹中的书签保持原位置，返回新添加的数量
+func (r *CollectionRepo) AddBookmarks(collectionID int, bookmarkIDs []int) (int, error) {
+	added := 0
+	err := lib.DB.Transaction(func(tx *gorm.DB) error {
+		var position int
+		if err := tx.Model(&db.CollectionBookmark{}).
+			Where("collection_id = ?", collectionID).
+			Select("COALESCE(MAX(position), -1) + 1").
+			Scan(&position).Error; err != nil {
+			return err
+		}
+
+		for _, bookmarkID := range bookmarkIDs {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&db.CollectionBookmark{
+				CollectionID: collectionID,
+				BookmarkID:   bookmarkID,
+				Position:     position,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				added++
+				position++
+			}
+		}
+		return nil
+	})
+	return added, err
+}
+
+// RemoveBookmarks 从收藏夹中移除书签（书签本身不删除）
+func (r *CollectionRepo) RemoveBookmarks(collectionID int, bookmarkIDs []int) error {
+	return lib.DB.Where("collection_id = ? AND bookmark_id IN ?", collectionID, bookmarkIDs).
+		Delete(&db.CollectionBookmark{}).Error
+}
+
+// ReorderBookmarks 按给定顺序重新设置收藏夹中书签的排序
+func (r *CollectionRepo) ReorderBookmarks(collectionID int, bookmarkIDs []int) error {
+	return lib.DB.Transaction(func(tx *gorm.DB) error {
+		for i, bookmarkID := range bookmarkIDs {
+			if err := tx.Model(&db.CollectionBookmark{}).
+				Where("collection_id = ? AND bookmark_id = ?", collectionID, bookmarkID).
+				UpdateColumn("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/utils"
+)
+
+type HighlightRepo struct{}
+
+// preloadHighlightBookmark 预加载高亮所在的书签（不含正文）
+func preloadHighlightBookmark(tx *gorm.DB) *gorm.DB {
+	return tx.Select("id", "url", "title")
+}
+
+// ListByBookmark 查询用户在书签中的高亮，按在正文中的位置排序
+func (r *HighlightRepo) ListByBookmark(userID, bookmarkID int) ([]db.Highlight, error) {
+	var highlights []db.Highlight
+	err := lib.DB.Where("user_id = ? AND bookmark_id = ?", userID, bookmarkID).
+		Order("orphaned ASC, start_offset ASC, id ASC").
+		Find(&highlights).Error
+	return highlights, err
+}
+
+// Search 搜索用户的高亮（选中文本与批注），不含回收站中书签的高亮，按创建时间倒序分页
+func (r *HighlightRepo) Search(userID int, keyword string, page, pageSize int) ([]db.Highlight, int64, error) {
+	var highlights []db.Highlight
+	var total int64
+
+	query := lib.DB.Model(&db.Highlight{}).
+		Joins("JOIN bookmark ON highlight.bookmark_id = bookmark.id AND bookmark.deleted_at IS NULL").
+		Where("highlight.user_id = ?", userID)
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where("highlight.quote LIKE ? OR highlight.note LIKE ?", like, like)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("Bookmark", preloadHighlightBookmark).
+		Order("highlight.created_at DESC, highlight.id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&highlights).Error
+	return highlights, total, err
+}
+
+// ListAll 查询用户的全部高亮（不含回收站中书签的高亮），按书签与位置排序，用于导出
+func (r *HighlightRepo) ListAll(userID int) ([]db.Highlight, error) {
+	var highlights []db.Highlight
+	err := lib.DB.Joins("JOIN bookmark ON highlight.bookmark_id = bookmark.id AND bookmark.deleted_at IS NULL").
+		Preload("Bookmark", preloadHighlightBookmark).
+		Where("highlight.user_id = ?", userID).
+		Order("highlight.bookmark_id DESC, highlight.orphaned ASC, highlight.start_offset ASC, highlight.id ASC").
+		Find(&highlights).Error
+	return highlights, err
+}
+
+// FindByID 根据ID查找高亮
+func (r *HighlightRepo) FindByID(id int) (*db.Highlight, error) {
+	var highlight db.Highlight
+	err := lib.DB.First(&highlight, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &highlight, nil
+}
+
+// Create 创建高亮
+func (r *HighlightRepo) Create(highlight *db.Highlight) error {
+	return lib.DB.Create(highlight).Error
+}
+
+// Update 更新高亮的批注与颜色
+func (r *HighlightRepo) Update(highlight *db.Highlight) error {
+	return lib.DB.Model(highlight).Select("note", "color").Updates(highlight).Error
+}
+
+// Delete 删除高亮
+func (r *HighlightRepo) Delete(id int) error {
+	return lib.DB.Delete(&db.Highlight{}, id).Error
+}
+
+// Reanchor 书签正文变化（重新归档、合并）后，按选中文本与前后文重新定位书签中的全部高亮，
+// 找不到原文的高亮标记为 orphaned，返回找不到原文的数量
+func (r *HighlightRepo) Reanchor(bookmarkID int, content string) (int, error) {
+	var highlights []db.Highlight
+	if err := lib.DB.Where("bookmark_id = ?", bookmarkID).Find(&highlights).Error; err != nil {
+		return 0, err
+	}
+
+	orphaned := 0
+	err := lib.DB.Transaction(func(tx *gorm.DB) error {
+		for _, highlight := range highlights {
+			updates := map[string]interface{}{"orphaned": true}
+			if anchor, ok := utils.AnchorText(content, highlight.Quote, highlight.Prefix, highlight.Suffix, highlight.StartOffset); ok {
+				updates = map[string]interface{}{
+					"start_offset": anchor.StartOffset,
+					"end_offset":   anchor.EndOffset,
+					"orphaned":     false,
+				}
+			} else {
+				orphaned++
+			}
+			if err := tx.Model(&db.Highlight{}).Where("id = ?", highlight.ID).UpdateColumns(updates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return orphaned, err
+}
+
+// ListByBookmarks 批量查询用户在书签中的高亮，返回 书签ID -> 高亮（按位置排序）
+func (r *HighlightRepo) ListByBookmarks(userID int, bookmarkIDs []int) (map[int][]db.Highlight, error) {
+	result := make(map[int][]db.Highlight)
+	if len(bookmarkIDs) == 0 {
+		return result, nil
+	}
+	var highlights []db.Highlight
+	if err := lib.DB.Where("user_id = ? AND bookmark_id IN ?", userID, bookmarkIDs).
+		Order("orphaned ASC, start_offset ASC, id ASC").
+		Find(&highlights).Error; err != nil {
+		return nil, err
+	}
+	for _, highlight := range highlights {
+		result[highlight.BookmarkID] = append(result[highlight.BookmarkID], highlight)
+	}
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"time"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+)
+
+type PersonalTokenRepo struct{}
+
+// ListByUser 查询用户的个人访问令牌，按创建时间倒序
+func (r *PersonalTokenRepo) ListByUser(userID int) ([]db.PersonalToken, error) {
+	var tokens []db.PersonalToken
+	err := lib.DB.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// FindByID 根据ID查找用户的个人访问令牌
+func (r *PersonalTokenRepo) FindByID(userID, id int) (*db.PersonalToken, error) {
+	var token db.PersonalToken
+	err := lib.DB.Where("id = ? AND user_id = ?", id, userID).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindByHash 根据令牌哈希查找个人访问令牌（包含所属用户）
+func (r *PersonalTokenRepo) FindByHash(hash string) (*db.PersonalToken, error) {
+	var token db.PersonalToken
+	err := lib.DB.Preload("User").Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Create 创建个人访问令牌
+func (r *PersonalTokenRepo) Create(token *db.PersonalToken) error {
+	return lib.DB.Create(token).Error
+}
+
+// Delete 删除个人访问令牌
+func (r *PersonalTokenRepo) Delete(id int) error {
+	return lib.DB.Delete(&db.PersonalToken{}, id).Error
+}
+
+// Touch 记录令牌的最近使用时间
+func (r *PersonalTokenRepo) Touch(id int, usedAt time.Time) error {
+	return lib.DB.Model(&db.PersonalToken{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
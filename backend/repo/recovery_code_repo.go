@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+)
+
+type RecoveryCodeRepo struct{}
+
+// Replace 重新生成用户的恢复码（旧恢复码全部作废）
+func (r *RecoveryCodeRepo) Replace(userID int, codeHashes []string) error {
+	return lib.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&db.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codeHashes) == 0 {
+			return nil
+		}
+		codes := make([]db.UserRecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, db.UserRecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Use 使用恢复码，成功返回 true，恢复码不存在或已使用返回 false
+func (r *RecoveryCodeRepo) Use(userID int, codeHash string) (bool, error) {
+	result := lib.DB.Model(&db.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountUnused 统计未使用的恢复码数量
+func (r *RecoveryCodeRepo) CountUnused(userID int) (int64, error) {
+	var count int64
+	err := lib.DB.Model(&db.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+)
+
+type SavedSearchRepo struct{}
+
+// preloadOwner 预加载创建者（只查询用户名）
+func preloadOwner(tx *gorm.DB) *gorm.DB {
+	return tx.Select("id", "username")
+}
+
+// ListVisible 查询用户可见的保存的搜索：自己创建的以及其他用户共享的
+func (r *SavedSearchRepo) ListVisible(userID int) ([]db.SavedSearch, error) {
+	var searches []db.SavedSearch
+	err := lib.DB.Preload("User", preloadOwner).
+		Where("user_id = ? OR shared = ?", userID, true).
+		Order("name ASC").
+		Find(&searches).Error
+	return searches, err
+}
+
+// FindByID 根据ID查找保存的搜索
+func (r *SavedSearchRepo) FindByID(id int) (*db.SavedSearch, error) {
+	var search db.SavedSearch
+	err := lib.DB.Preload("User", preloadOwner).First(&search, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+// FindByFeedToken 根据订阅源访问令牌查找保存的搜索
+func (r *SavedSearchRepo) FindByFeedToken(token string) (*db.SavedSearch, error) {
+	var search db.SavedSearch
+	err := lib.DB.Where("feed_token = ?", token).First(&search).Error
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+// Create 创建保存的搜索
+func (r *SavedSearchRepo) Create(search *db.SavedSearch) error {
+	return lib.DB.Create(search).Error
+}
+
+// Update 更新保存的搜索
+func (r *SavedSearchRepo) Update(search *db.SavedSearch) error {
+	return lib.DB.Model(search).Select("name", "keyword", "tags", "shared").Updates(search).Error
+}
+
+// UpdateFeedToken 更新订阅源访问令牌（旧的订阅地址失效）
+func (r *SavedSearchRepo) UpdateFeedToken(id int, token string) error {
+	return lib.DB.Model(&db.SavedSearch{}).Where("id = ?", id).Update("feed_token", token).Error
+}
+
+// Delete 删除保存的搜索
+func (r *SavedSearchRepo) Delete(id int) error {
+	return lib.DB.Delete(&db.SavedSearch{}, id).Error
+}
@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/utils"
+)
+
+type ShareRepo struct{}
+
+// ListByUser 查询用户创建的分享链接
+func (r *ShareRepo) ListByUser(userID int) ([]db.Share, error) {
+	var shares []db.Share
+	err := lib.DB.Where("user_id = ?", userID).Order("id DESC").Find(&shares).Error
+	return shares, err
+}
+
+// FindByID 根据ID查找分享链接
+func (r *ShareRepo) FindByID(id int) (*db.Share, error) {
+	var share db.Share
+	err := lib.DB.First(&share, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// FindByToken 根据访问令牌查找分享链接
+func (r *ShareRepo) FindByToken(token string) (*db.Share, error) {
+	var share db.Share
+	err := lib.DB.Where("token = ?", token).First(&share).Error
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// Create 创建分享链接
+func (r *ShareRepo) Create(share *db.Share) error {
+	return lib.DB.Create(share).Error
+}
+
+// Delete 删除（撤销）分享链接
+func (r *ShareRepo) Delete(id int) error {
+	return lib.DB.Delete(&db.Share{}, id).Error
+}
+
+// RecordView 记录一次访问
+func (r *ShareRepo) RecordView(id int) error {
+	return lib.DB.Model(&db.Share{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"view_count":     gorm.Expr("view_count + 1"),
+		"last_viewed_at": time.Now(),
+	}).Error
+}
+
+// RecordUnlockFailure 记录一次访问密码错误，连续错误超过 free 次后按指数退避锁定，返回锁定结束时间（nil 表示未锁定）
+// 上次锁定结束超过 maxDelay 后重新计数
+func (r *ShareRepo) RecordUnlockFailure(id, free int, base, maxDelay time.Duration) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := lib.DB.Transaction(func(tx *gorm.DB) error {
+		var share db.Share
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "unlock_failures", "unlock_locked_until").
+			First(&share, id).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		failures := share.UnlockFailures + 1
+		if share.UnlockLockedUntil != nil && now.Sub(*share.UnlockLockedUntil) > maxDelay {
+			failures = 1
+		}
+		updates := map[string]interface{}{"unlock_failures": failures}
+		if delay := utils.AttemptBackoff(failures, free, base, maxDelay); delay > 0 {
+			until := now.Add(delay)
+			lockedUntil = &until
+			updates["unlock_locked_until"] = until
+		}
+		return tx.Model(&db.Share{}).Where("id = ?", id).UpdateColumns(updates).Error
+	})
+	return lockedUntil, err
+}
+
+// ResetUnlockFailures 访问密码正确后清零错误次数
+func (r *ShareRepo) ResetUnlockFailures(id int) error {
+	return lib.DB.Model(&db.Share{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"unlock_failures":     0,
+		"unlock_locked_until": nil,
+	}).Error
+}
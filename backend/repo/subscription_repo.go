@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+)
+
+type SubscriptionRepo struct{}
+
+// List 查询全部订阅源
+func (r *SubscriptionRepo) List() ([]db.Subscription, error) {
+	var subscriptions []db.Subscription
+	err := lib.DB.Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// ListEnabled 查询启用的订阅源
+func (r *SubscriptionRepo) ListEnabled() ([]db.Subscription, error) {
+	var subscriptions []db.Subscription
+	err := lib.DB.Where("enabled = ?", true).Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// FindByID 根据ID查找订阅源
+func (r *SubscriptionRepo) FindByID(id int) (*db.Subscription, error) {
+	var subscription db.Subscription
+	err := lib.DB.First(&subscription, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Create 创建订阅源
+func (r *SubscriptionRepo) Create(subscription *db.Subscription) error {
+	return lib.DB.Create(subscription).Error
+}
+
+// Update 更新订阅源设置与条件请求缓存
+func (r *SubscriptionRepo) Update(subscription *db.Subscription) error {
+	return lib.DB.Model(subscription).
+		Select("name", "url", "tags", "archive", "enabled", "etag", "last_modified").
+		Updates(subscription).Error
+}
+
+// Delete 删除订阅源及其已处理条目的记录（已创建的书签不删除）
+func (r *SubscriptionRepo) Delete(id int) error {
+	return lib.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&db.SubscriptionItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&db.Subscription{}, id).Error
+	})
+}
+
+// SeenItems 查询订阅源已处理条目的标识（GUID 或地址的哈希）
+func (r *SubscriptionRepo) SeenItems(id int) (map[string]bool, error) {
+	var keys []string
+	if err := lib.DB.Model(&db.SubscriptionItem{}).
+		Where("subscription_id = ?", id).
+		Pluck("item_key", &keys).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		seen[key] = true
+	}
+	return seen, nil
+}
+
+// MarkItemSeen 记录订阅源已处理的条目，已记录时忽略
+func (r *SubscriptionRepo) MarkItemSeen(id int, key, url string) error {
+	return lib.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&db.SubscriptionItem{
+		SubscriptionID: id,
+		ItemKey:        key,
+		URL:            url,
+	}).Error
+}
+
+// RecordSuccess 记录一次成功的拉取
+func (r *SubscriptionRepo) RecordSuccess(id int, etag, lastModified string, created int) error {
+	now := time.Now()
+	return lib.DB.Model(&db.Subscription{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"etag":            etag,
+		"last_modified":   lastModified,
+		"last_checked_at": now,
+		"last_success_at": now,
+		"last_error":      "",
+		"error_count":     0,
+		"item_count":      gorm.Expr("item_count + ?", created),
+	}).Error
+}
+
+// RecordError 记录一次失败的拉取
+func (r *SubscriptionRepo) RecordError(id int, message string) error {
+	return lib.DB.Model(&db.Subscription{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"last_checked_at": time.Now(),
+		"last_error":      message,
+		"error_count":     gorm.Expr("error_count + 1"),
+	}).Error
+}
@@ -3,6 +3,10 @@ package repo
 import (
 	"bk_kms/lib"
 	"bk_kms/model/db"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
 )
 
 type TagRepo struct{}
@@ -19,8 +23,9 @@ func (r *TagRepo) List(name string) ([]TagWithCount, error) {
 	var tags []TagWithCount
 
 	query := lib.DB.Table("tag").
-		Select("tag.id, tag.name, COUNT(bookmark_tag.bookmark_id) as count").
+		Select("tag.id, tag.name, COUNT(bookmark.id) as count").
 		Joins("LEFT JOIN bookmark_tag ON tag.id = bookmark_tag.tag_id").
+		Joins("LEFT JOIN bookmark ON bookmark_tag.bookmark_id = bookmark.id AND bookmark.deleted_at IS NULL").
 		Group("tag.id, tag.name")
 
 	if name != "" {
@@ -31,6 +36,13 @@ func (r *TagRepo) List(name string) ([]TagWithCount, error) {
 	return tags, err
 }
 
+// FindAll 查询全部标签
+func (r *TagRepo) FindAll() ([]db.Tag, error) {
+	var tags []db.Tag
+	err := lib.DB.Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
 // FindByID 根据ID查找标签
 func (r *TagRepo) FindByID(id int) (*db.Tag, error) {
 	var tag db.Tag
@@ -50,3 +62,111 @@ func (r *TagRepo) Update(tag *db.Tag) error {
 func (r *TagRepo) Delete(id int) error {
 	return lib.DB.Delete(&db.Tag{}, id).Error
 }
+
+// FindByIDs 根据ID列表查找标签
+func (r *TagRepo) FindByIDs(ids []int) ([]db.Tag, error) {
+	var tags []db.Tag
+	err := lib.DB.Where("id IN ?", ids).Find(&tags).Error
+	return tags, err
+}
+
+// Merge 将源标签合并到目标标签：书签与自动标签规则的关联、保存的搜索与订阅源的标签名称、标签的分享链接改为目标标签，
+// 然后删除源标签，返回受影响的书签ID
+func (r *TagRepo) Merge(sourceIDs []int, targetID int) ([]int, error) {
+	var bookmarkIDs []int
+	err := lib.DB.Transaction(func(tx *gorm.DB) error {
+		var target db.Tag
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+		var sourceNames []string
+		if err := tx.Model(&db.Tag{}).Where("id IN ?", sourceIDs).Pluck("name", &sourceNames).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&db.BookmarkTag{}).Distinct().
+			Where("tag_id IN ?", sourceIDs).
+			Pluck("bookmark_id", &bookmarkIDs).Error; err != nil {
+			return err
+		}
+		// 已有目标标签的书签与规则忽略重复关联
+		if err := tx.Exec(
+			"INSERT IGNORE INTO bookmark_tag (bookmark_id, tag_id) SELECT bookmark_id, ? FROM bookmark_tag WHERE tag_id IN ?",
+			targetID, sourceIDs,
+		).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(
+			"INSERT IGNORE INTO tag_rule_tag (rule_id, tag_id) SELECT rule_id, ? FROM tag_rule_tag WHERE tag_id IN ?",
+			targetID, sourceIDs,
+		).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id IN ?", sourceIDs).Delete(&db.BookmarkTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id IN ?", sourceIDs).Delete(&db.TagRuleTag{}).Error; err != nil {
+			return err
+		}
+
+		// 保存的搜索与订阅源按名称保存标签列表
+		if err := renameTagLists(tx, &db.SavedSearch{}, sourceNames, target.Name); err != nil {
+			return err
+		}
+		if err := renameTagLists(tx, &db.Subscription{}, sourceNames, target.Name); err != nil {
+			return err
+		}
+		// 源标签的分享链接改为分享目标标签，链接保持可用
+		if err := tx.Model(&db.Share{}).
+			Where("target_type = ? AND target_id IN ?", db.ShareTargetTag, sourceIDs).
+			Update("target_id", targetID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&db.Tag{}, sourceIDs).Error
+	})
+	return bookmarkIDs, err
+}
+
+// renameTagLists 将 model 对应表中 tags 字段（英文逗号分隔的标签名称）里的源标签替换为目标标签
+func renameTagLists(tx *gorm.DB, model interface{}, sourceNames []string, targetName string) error {
+	var rows []struct {
+		ID   int
+		Tags string
+	}
+	if err := tx.Model(model).Select("id", "tags").Where("tags <> ''").Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		tags, changed := renameTagList(row.Tags, sourceNames, targetName)
+		if !changed {
+			continue
+		}
+		if err := tx.Model(model).Where("id = ?", row.ID).Update("tags", tags).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameTagList 替换标签列表中的源标签（名称不区分大小写，与数据库排序规则一致），替换后去除重复，返回是否有修改
+func renameTagList(list string, sourceNames []string, targetName string) (string, bool) {
+	changed := false
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		for _, source := range sourceNames {
+			if strings.EqualFold(tag, source) {
+				tag = targetName
+				changed = true
+				break
+			}
+		}
+		if !slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			tags = append(tags, tag)
+		}
+	}
+	return strings.Join(tags, ","), changed
+}
@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"bk_kms/lib"
+	"bk_kms/model/db"
+
+	"gorm.io/gorm"
+)
+
+type TagRuleRepo struct{}
+
+// List 查询全部自动标签规则
+func (r *TagRuleRepo) List() ([]db.TagRule, error) {
+	var rules []db.TagRule
+	err := lib.DB.Preload("Tags").Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// ListEnabled 查询启用的自动标签规则，ids 不为空时只查询指定规则
+func (r *TagRuleRepo) ListEnabled(ids []int) ([]db.TagRule, error) {
+	var rules []db.TagRule
+	query := lib.DB.Preload("Tags").Where("enabled = ?", true)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	err := query.Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// FindByID 根据ID查找自动标签规则
+func (r *TagRuleRepo) FindByID(id int) (*db.TagRule, error) {
+	var rule db.TagRule
+	err := lib.DB.Preload("Tags").First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Create 创建自动标签规则
+func (r *TagRuleRepo) Create(rule *db.TagRule) error {
+	return lib.DB.Create(rule).Error
+}
+
+// Update 更新自动标签规则及其标签
+func (r *TagRuleRepo) Update(rule *db.TagRule) error {
+	return lib.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(rule).Select("name", "field", "match_type", "pattern", "enabled").
+			Updates(rule).Error; err != nil {
+			return err
+		}
+		return tx.Model(rule).Association("Tags").Replace(rule.Tags)
+	})
+}
+
+// Delete 删除自动标签规则及其标签关联
+func (r *TagRuleRepo) Delete(id int) error {
+	return lib.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", id).Delete(&db.TagRuleTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&db.TagRule{}, id).Error
+	})
+}
@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"bk_kms/lib"
+	"bk_kms/model/db"
+)
+
+type UserIdentityRepo struct{}
+
+// FindByIssuerSubject 根据身份提供方和 sub 查找绑定关系
+func (r *UserIdentityRepo) FindByIssuerSubject(issuer, subject string) (*db.UserIdentity, error) {
+	var identity db.UserIdentity
+	err := lib.DB.Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListByUserID 查询用户绑定的全部第三方身份
+func (r *UserIdentityRepo) ListByUserID(userID int) ([]db.UserIdentity, error) {
+	var identities []db.UserIdentity
+	err := lib.DB.Where("user_id = ?", userID).Order("id ASC").Find(&identities).Error
+	return identities, err
+}
+
+// Create 创建绑定关系
+func (r *UserIdentityRepo) Create(identity *db.UserIdentity) error {
+	return lib.DB.Create(identity).Error
+}
+
+// Delete 解除绑定（只能解除自己的绑定）
+func (r *UserIdentityRepo) Delete(id, userID int) (bool, error) {
+	result := lib.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&db.UserIdentity{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -3,6 +3,10 @@ package repo
 import (
 	"bk_kms/lib"
 	"bk_kms/model/db"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepo struct{}
@@ -31,3 +35,76 @@ func (r *UserRepo) FindByID(id int) (*db.User, error) {
 	}
 	return &user, nil
 }
+
+// UpdateTOTP 更新用户两步验证设置
+func (r *UserRepo) UpdateTOTP(userID int, secret string, enabled bool) error {
+	return lib.DB.Model(&db.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_enabled":   enabled,
+		"totp_last_step": 0,
+	}).Error
+}
+
+// UseTOTPStep 记录已使用的 TOTP 时间步，返回 false 表示该时间步已被使用（重放）
+func (r *UserRepo) UseTOTPStep(userID int, step int64) (bool, error) {
+	result := lib.DB.Model(&db.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RecordTOTPFailure 记录一次两步验证失败，连续失败 maxFailures 次时锁定并清零失败次数，返回本次是否被锁定
+func (r *UserRepo) RecordTOTPFailure(userID, maxFailures int) (bool, error) {
+	locked := false
+	err := lib.DB.Transaction(func(tx *gorm.DB) error {
+		var user db.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "totp_failures").
+			First(&user, userID).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"totp_failures": user.TOTPFailures + 1}
+		if user.TOTPFailures+1 >= maxFailures {
+			locked = true
+			updates = map[string]interface{}{"totp_failures": 0, "totp_locked_at": time.Now()}
+		}
+		return tx.Model(&db.User{}).Where("id = ?", userID).Updates(updates).Error
+	})
+	return locked, err
+}
+
+// ResetTOTPFailures 两步验证通过后清零失败次数
+func (r *UserRepo) ResetTOTPFailures(userID int) error {
+	return lib.DB.Model(&db.User{}).Where("id = ?", userID).Update("totp_failures", 0).Error
+}
+
+// UpdateRole 更新用户角色
+func (r *UserRepo) UpdateRole(userID int, role string) error {
+	return lib.DB.Model(&db.User{}).Where("id = ?", userID).Update("role", role).Error
+}
+
+// UpdatePassword 更新用户密码（已加盐哈希）与盐值
+func (r *UserRepo) UpdatePassword(userID int, password, salt string) error {
+	return lib.DB.Model(&db.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password": password,
+		"salt":     salt,
+	}).Error
+}
+
+// FindByFeedToken 根据书签订阅源访问令牌查找用户
+func (r *UserRepo) FindByFeedToken(token string) (*db.User, error) {
+	var user db.User
+	err := lib.DB.Where("feed_token = ? AND feed_token <> ''", token).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateFeedToken 更新书签订阅源访问令牌
+func (r *UserRepo) UpdateFeedToken(userID int, token string) error {
+	return lib.DB.Model(&db.User{}).Where("id = ?", userID).Update("feed_token", token).Error
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bk_kms/model/db"
+	"bk_kms/model/dto"
+	"bk_kms/repo"
+)
+
+// AdminMiddleware 管理员权限中间件，需在 AuthMiddleware 之后使用
+// 每次请求从数据库读取角色，角色变更可立即生效
+func AdminMiddleware() gin.HandlerFunc {
+	userRepo := &repo.UserRepo{}
+
+	return func(c *gin.Context) {
+		user, err := userRepo.FindByID(c.GetInt("user_id"))
+		if err != nil || user.Role != db.RoleAdmin {
+			c.JSON(http.StatusForbidden, dto.Response{
+				Code: 403,
+				Msg:  "无权限访问",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("role", user.Role)
+
+		c.Next()
+	}
+}
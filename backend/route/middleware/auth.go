@@ -3,16 +3,26 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"bk_kms/lib"
 	"bk_kms/model/dto"
+	"bk_kms/repo"
 	"bk_kms/utils"
 )
 
-// AuthMiddleware JWT 认证中间件
+// 认证方式，AuthMiddleware 写入上下文的 auth_method
+const (
+	AuthMethodSession = "session" // 登录后签发的 JWT
+	AuthMethodToken   = "token"   // 个人访问令牌
+)
+
+// AuthMiddleware JWT 认证中间件，同时支持个人访问令牌（bkms_ 前缀）
 func AuthMiddleware() gin.HandlerFunc {
+	personalTokenRepo := &repo.PersonalTokenRepo{}
+
 	return func(c *gin.Context) {
 		// 从 Authorization header 获取 token
 		authHeader := c.GetHeader("Authorization")
@@ -38,9 +48,37 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		tokenString := parts[1]
 
+		// 个人访问令牌
+		if utils.IsPersonalToken(tokenString) {
+			token, err := personalTokenRepo.FindByHash(utils.HashPersonalToken(tokenString))
+			if err != nil || token.Expired() || token.User == nil {
+				c.JSON(http.StatusUnauthorized, dto.Response{
+					Code: 401,
+					Msg:  "Token 无效或已过期",
+				})
+				c.Abort()
+				return
+			}
+
+			// 最近使用时间精确到分钟即可，避免每个请求都写数据库
+			now := time.Now()
+			if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > time.Minute {
+				if err := personalTokenRepo.Touch(token.ID, now); err != nil {
+					lib.Logger.Error("更新令牌使用时间失败: " + err.Error())
+				}
+			}
+
+			c.Set("user_id", token.UserID)
+			c.Set("username", token.User.Username)
+			c.Set("auth_method", AuthMethodToken)
+
+			c.Next()
+			return
+		}
+
 		// 验证 token
 		claims, err := utils.ParseToken(tokenString, lib.GlobalConfig.JWT.Secret)
-		if err != nil {
+		if err != nil || claims.Purpose != "" {
 			c.JSON(http.StatusUnauthorized, dto.Response{
 				Code: 401,
 				Msg:  "Token 无效或已过期",
@@ -52,6 +90,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 将用户信息存储到上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("auth_method", AuthMethodSession)
 
 		c.Next()
 	}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bk_kms/model/dto"
+)
+
+// SessionOnlyMiddleware 只允许登录会话（JWT）访问，拒绝个人访问令牌，需在 AuthMiddleware 之后使用
+// 用于两步验证、第三方身份绑定与令牌管理等账号安全操作，泄露的令牌不能借此提升或延续权限
+func SessionOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") == AuthMethodToken {
+			c.JSON(http.StatusForbidden, dto.Response{
+				Code: 403,
+				Msg:  "个人访问令牌不能执行此操作，请登录后重试",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
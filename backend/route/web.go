@@ -5,16 +5,36 @@ import (
 
 	"bk_kms/controller"
 	"bk_kms/route/middleware"
+	"bk_kms/view"
 )
 
 // InitRouter 初始化路由
 func InitRouter() *gin.Engine {
 	r := gin.Default()
+	r.SetHTMLTemplate(view.Templates())
 
 	// 认证相关路由（无需认证）
 	authController := controller.NewAuthController()
 	r.GET("/api/v1/captcha", authController.GetCaptcha)
 	r.POST("/api/v1/auth/login", authController.Login)
+	r.POST("/api/v1/auth/login/totp", authController.LoginTOTP)
+
+	// 单点登录（OIDC）
+	oidcController := controller.NewOIDCController()
+	r.GET("/api/v1/auth/oidc/login", oidcController.Login)
+	r.GET("/api/v1/auth/oidc/callback", oidcController.Callback)
+
+	// 订阅源（通过 URL 中的令牌访问，无需认证）
+	feedController := controller.NewFeedController()
+	savedSearchController := controller.NewSavedSearchController()
+	r.GET("/feeds/bookmarks/:token", feedController.Bookmarks)
+	r.GET("/feeds/saved-search/:token", savedSearchController.Feed)
+
+	// 公开分享页面（通过 URL 中的令牌访问，无需认证）
+	shareController := controller.NewShareController()
+	r.GET("/s/:token", shareController.View)
+	r.POST("/s/:token", shareController.Unlock)
+	r.GET("/s/:token/bookmark/:id", shareController.ViewBookmark)
 
 	// API v1 路由组（需要认证）
 	v1 := r.Group("/api/v1")
@@ -22,6 +42,7 @@ func InitRouter() *gin.Engine {
 	{
 		bookmarkController := controller.NewBookmarkController()
 		tagController := controller.NewTagController()
+		totpController := controller.NewTOTPController()
 
 		// 书签相关路由
 		v1.GET("/bookmarks", bookmarkController.List)
@@ -29,13 +50,137 @@ func InitRouter() *gin.Engine {
 		v1.PUT("/bookmarks", bookmarkController.Update)
 		v1.DELETE("/bookmark", bookmarkController.Delete)
 		v1.GET("/bookmark/:id/content", bookmarkController.GetContent)
+		v1.GET("/bookmark/:id/related", bookmarkController.Related)
+		v1.GET("/bookmarks/duplicates", bookmarkController.Duplicates)
+		v1.POST("/bookmarks/merge", bookmarkController.Merge)
+		v1.GET("/bookmarks/near-duplicates", bookmarkController.NearDuplicates)
+
+		// 书签导出
+		exportController := controller.NewExportController()
+		v1.GET("/bookmarks/export/markdown", exportController.Markdown)
+		v1.GET("/bookmarks/export/jsonl", exportController.JSONL)
+		v1.GET("/bookmarks/export/csv", exportController.CSV)
+
+		// 阅读状态、收藏与阅读进度（按用户）
+		bookmarkStateController := controller.NewBookmarkStateController()
+		v1.PUT("/bookmark/:id/state", bookmarkStateController.Update)
+		v1.PUT("/bookmarks/state", bookmarkStateController.BulkUpdate)
 
-		// 书签导入（SSE 流式响应）
+		// 高亮与批注（按用户）
+		highlightController := controller.NewHighlightController()
+		v1.GET("/bookmark/:id/highlights", highlightController.List)
+		v1.POST("/bookmark/:id/highlight", highlightController.Create)
+		v1.PUT("/highlight/:id", highlightController.Update)
+		v1.DELETE("/highlight/:id", highlightController.Delete)
+		v1.GET("/highlights", highlightController.Search)
+		v1.GET("/highlights/export", highlightController.Export)
+
+		// 书签笔记（Markdown，按用户）
+		noteController := controller.NewBookmarkNoteController()
+		v1.GET("/bookmark/:id/notes", noteController.List)
+		v1.POST("/bookmark/:id/note", noteController.Create)
+		v1.PUT("/note/:id", noteController.Update)
+		v1.DELETE("/note/:id", noteController.Delete)
+		v1.POST("/notes/preview", noteController.Preview)
+
+		// 回收站
+		trashController := controller.NewTrashController()
+		v1.GET("/trash", trashController.List)
+		v1.POST("/trash/restore", trashController.Restore)
+		v1.DELETE("/trash", trashController.Purge)
+
+		// 书签导入：浏览器书签文件（SSE 流式响应）与原生格式（JSON Lines / CSV）
 		v1.POST("/bookmarks/import", bookmarkController.Import)
+		v1.POST("/bookmarks/import/records", bookmarkController.ImportRecords)
 
 		// 标签相关路由
 		v1.GET("/tags", tagController.List)
 		v1.PUT("/tag/:id", tagController.Update)
+		v1.POST("/tags/merge", tagController.Merge)
+		v1.POST("/tags/suggest", tagController.Suggest)
+
+		// 自动标签规则
+		tagRuleController := controller.NewTagRuleController()
+		v1.GET("/tag-rules", tagRuleController.List)
+		v1.POST("/tag-rule", tagRuleController.Create)
+		v1.PUT("/tag-rule/:id", tagRuleController.Update)
+		v1.DELETE("/tag-rule/:id", tagRuleController.Delete)
+		v1.POST("/tag-rules/apply", tagRuleController.Apply)
+
+		// 收藏夹
+		collectionController := controller.NewCollectionController()
+		v1.GET("/collections", collectionController.List)
+		v1.POST("/collection", collectionController.Create)
+		v1.PUT("/collection/:id", collectionController.Update)
+		v1.DELETE("/collection/:id", collectionController.Delete)
+		v1.POST("/collections/reorder", collectionController.Reorder)
+		v1.GET("/collection/:id/bookmarks", collectionController.Bookmarks)
+		v1.POST("/collection/:id/bookmarks", collectionController.AddBookmarks)
+		v1.DELETE("/collection/:id/bookmarks", collectionController.RemoveBookmarks)
+		v1.POST("/collection/:id/bookmarks/reorder", collectionController.ReorderBookmarks)
+
+		// 保存的搜索（智能收藏夹）
+		v1.GET("/saved-searches", savedSearchController.List)
+		v1.POST("/saved-search", savedSearchController.Create)
+		v1.PUT("/saved-search/:id", savedSearchController.Update)
+		v1.DELETE("/saved-search/:id", savedSearchController.Delete)
+		v1.GET("/saved-search/:id/bookmarks", savedSearchController.Bookmarks)
+		v1.POST("/saved-search/:id/feed-token", savedSearchController.RegenerateFeedToken)
+
+		// 分享链接
+		v1.GET("/shares", shareController.List)
+		v1.POST("/share", shareController.Create)
+		v1.DELETE("/share/:id", shareController.Delete)
+
+		// 订阅源：定时拉取 RSS/Atom/JSON Feed 创建书签
+		subscriptionController := controller.NewSubscriptionController()
+		v1.GET("/subscriptions", subscriptionController.List)
+		v1.POST("/subscription", subscriptionController.Create)
+		v1.PUT("/subscription/:id", subscriptionController.Update)
+		v1.DELETE("/subscription/:id", subscriptionController.Delete)
+		v1.POST("/subscription/:id/poll", subscriptionController.Poll)
+
+		// 书签订阅源地址
+		v1.GET("/user/feed-token", feedController.Token)
+		v1.POST("/user/feed-token", feedController.RegenerateToken)
+
+		// 账号安全相关路由只允许登录会话访问，个人访问令牌不能使用
+		account := v1.Group("/user")
+		account.Use(middleware.SessionOnlyMiddleware())
+		{
+			// 两步验证相关路由
+			account.GET("/totp", totpController.Status)
+			account.POST("/totp/enroll", totpController.Enroll)
+			account.POST("/totp/activate", totpController.Activate)
+			account.POST("/totp/disable", totpController.Disable)
+			account.POST("/totp/recovery-codes", totpController.RegenerateRecoveryCodes)
+
+			// 第三方身份绑定
+			account.GET("/oidc", oidcController.Identities)
+			account.POST("/oidc/link", oidcController.Link)
+			account.DELETE("/oidc/:id", oidcController.Unlink)
+
+			// 个人访问令牌（命令行客户端等脚本使用）
+			personalTokenController := controller.NewPersonalTokenController()
+			account.GET("/tokens", personalTokenController.List)
+			account.POST("/token", personalTokenController.Create)
+			account.DELETE("/token/:id", personalTokenController.Delete)
+		}
+
+		// 管理员路由
+		admin := v1.Group("/admin")
+		admin.Use(middleware.AdminMiddleware())
+		{
+			auditController := controller.NewAuditController()
+			admin.GET("/audit-logs", auditController.List)
+			admin.POST("/bookmarks/canonicalize", bookmarkController.RefreshCanonicalURLs)
+			admin.POST("/bookmarks/fingerprint", bookmarkController.RefreshSimHashes)
+
+			// 全量备份与恢复
+			backupController := controller.NewBackupController()
+			admin.GET("/backup", backupController.Backup)
+			admin.POST("/restore", backupController.Restore)
+		}
 	}
 
 	// 测试路由
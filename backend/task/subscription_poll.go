@@ -0,0 +1,234 @@
+package task
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"bk_kms/index"
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/repo"
+	"bk_kms/utils"
+)
+
+const defaultSubscriptionMaxItems = 20
+
+// ErrSubscriptionPolling 订阅源正在拉取中
+var ErrSubscriptionPolling = errors.New("订阅源正在更新中，请稍后重试")
+
+// polling 正在拉取的订阅源ID，避免定时任务与手动触发同时拉取同一个订阅源
+var polling sync.Map
+
+// PollResult 一次拉取的结果
+type PollResult struct {
+	NotModified bool  `json:"not_modified"` // 订阅源内容未变化（服务端返回 304）
+	Total       int   `json:"total"`        // 订阅源中的条目数
+	Created     int   `json:"created"`      // 新创建的书签数
+	Skipped     int   `json:"skipped"`      // 已处理过或已收藏而跳过的条目数
+	Failed      int   `json:"failed"`       // 创建失败的条目数
+	BookmarkIDs []int `json:"bookmark_ids"` // 新创建的书签ID
+}
+
+// StartSubscriptionPoll 启动订阅源定时拉取任务
+func StartSubscriptionPoll(interval time.Duration) {
+	if interval <= 0 {
+		lib.Logger.Info("订阅源自动拉取未开启")
+		return
+	}
+
+	lib.Logger.Info(fmt.Sprintf("订阅源自动拉取已开启: 间隔 %s", interval))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			pollSubscriptions()
+			<-ticker.C
+		}
+	}()
+}
+
+// pollSubscriptions 依次拉取全部启用的订阅源
+func pollSubscriptions() {
+	subscriptions, err := (&repo.SubscriptionRepo{}).ListEnabled()
+	if err != nil {
+		lib.Logger.Error("查询订阅源失败: " + err.Error())
+		return
+	}
+	for i := range subscriptions {
+		if _, err := PollSubscription(&subscriptions[i]); err != nil && err != ErrSubscriptionPolling {
+			lib.Logger.Error(fmt.Sprintf("拉取订阅源失败: %s - %v", subscriptions[i].URL, err))
+		}
+	}
+}
+
+// PollSubscription 拉取一个订阅源，将新条目创建为书签，并记录拉取状态
+func PollSubscription(subscription *db.Subscription) (*PollResult, error) {
+	if _, running := polling.LoadOrStore(subscription.ID, struct{}{}); running {
+		return nil, ErrSubscriptionPolling
+	}
+	defer polling.Delete(subscription.ID)
+
+	subscriptionRepo := &repo.SubscriptionRepo{}
+	feed, err := utils.FetchFeed(subscription.URL, subscription.ETag, subscription.LastModified)
+	if err != nil {
+		if recordErr := subscriptionRepo.RecordError(subscription.ID, err.Error()); recordErr != nil {
+			lib.Logger.Error("记录订阅源状态失败: " + recordErr.Error())
+		}
+		return nil, err
+	}
+
+	result := &PollResult{NotModified: feed.NotModified, Total: len(feed.Items)}
+	if !feed.NotModified {
+		createFeedBookmarks(subscription, feed.Items, result)
+	}
+
+	if err := subscriptionRepo.RecordSuccess(subscription.ID, feed.ETag, feed.LastModified, result.Created); err != nil {
+		lib.Logger.Error("记录订阅源状态失败: " + err.Error())
+	}
+	if result.Created > 0 {
+		lib.Logger.Info(fmt.Sprintf("订阅源 %s 新增 %d 个书签", subscription.Name, result.Created))
+		(&repo.AuditRepo{}).Record("system", db.AuditActionCreate, db.AuditEntityBookmark, result.BookmarkIDs, map[string]interface{}{
+			"subscription": subscription.Name,
+			"url":          subscription.URL,
+		})
+	}
+	return result, nil
+}
+
+// feedEntry 待处理的订阅源条目
+type feedEntry struct {
+	Item utils.FetchedFeedItem
+	URL  string // 去除追踪参数后的地址
+	Key  string // 条目标识（GUID，没有时使用地址）的哈希
+}
+
+// feedItemKey 条目标识的哈希，用于记录已处理的条目
+func feedItemKey(item utils.FetchedFeedItem, cleanURL string) string {
+	id := item.GUID
+	if id == "" {
+		id = cleanURL
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// selectFeedItems 选出未处理过的条目：只处理最新的 maxItems 条，按从旧到新的顺序返回
+// skipped 为超出数量或已处理的条目数，failed 为地址无效的条目数
+func selectFeedItems(items []utils.FetchedFeedItem, seen map[string]bool, maxItems int) (entries []feedEntry, skipped, failed int) {
+	if len(items) > maxItems {
+		skipped += len(items) - maxItems
+		items = items[:maxItems]
+	}
+
+	picked := make(map[string]bool)
+	for i := len(items) - 1; i >= 0; i-- {
+		cleanURL, err := utils.RemoveUTMParams(items[i].URL)
+		if err != nil {
+			failed++
+			continue
+		}
+		key := feedItemKey(items[i], cleanURL)
+		if seen[key] || picked[key] {
+			skipped++
+			continue
+		}
+		picked[key] = true
+		entries = append(entries, feedEntry{Item: items[i], URL: cleanURL, Key: key})
+	}
+	return entries, skipped, failed
+}
+
+// createFeedBookmarks 将订阅源中未处理过的条目创建为书签，按从旧到新的顺序创建
+// 处理过的条目（包括地址已收藏而跳过的）都会记录，书签删除后不会被重新创建
+func createFeedBookmarks(subscription *db.Subscription, items []utils.FetchedFeedItem, result *PollResult) {
+	maxItems := lib.GlobalConfig.Subscription.MaxItems
+	if maxItems <= 0 {
+		maxItems = defaultSubscriptionMaxItems
+	}
+
+	subscriptionRepo := &repo.SubscriptionRepo{}
+	seen, err := subscriptionRepo.SeenItems(subscription.ID)
+	if err != nil {
+		lib.Logger.Error("查询订阅源已处理条目失败: " + err.Error())
+		result.Failed += len(items)
+		return
+	}
+	entries, skipped, failed := selectFeedItems(items, seen, maxItems)
+	result.Skipped += skipped
+	result.Failed += failed
+	if len(entries) == 0 {
+		return
+	}
+
+	bookmarkRepo := &repo.BookmarkRepo{}
+	tags, err := bookmarkRepo.FindOrCreateTags(strings.Split(subscription.Tags, ","))
+	if err != nil {
+		lib.Logger.Error("处理标签失败: " + err.Error())
+		result.Failed += len(entries)
+		return
+	}
+
+	markSeen := func(entry feedEntry) {
+		if err := subscriptionRepo.MarkItemSeen(subscription.ID, entry.Key, entry.URL); err != nil {
+			lib.Logger.Error("记录订阅源条目失败: " + err.Error())
+		}
+	}
+
+	for _, entry := range entries {
+		item := entry.Item
+		// 地址已收藏（包括回收站中的书签）时不再创建
+		exists, err := bookmarkRepo.ExistsByURL(entry.URL)
+		if err != nil {
+			lib.Logger.Error("查询书签失败: " + err.Error())
+			result.Failed++
+			continue
+		}
+		if exists {
+			markSeen(entry)
+			result.Skipped++
+			continue
+		}
+
+		bookmark := &db.Bookmark{
+			URL:       entry.URL,
+			Title:     utils.ValidateTitle(item.Title, entry.URL),
+			Excerpt:   item.Summary,
+			Tags:      tags,
+			IsArchive: subscription.Archive,
+		}
+		if subscription.Archive {
+			content, err := utils.FetchBookmarkContent(entry.URL, item.Title != "", item.Summary != "")
+			if err != nil {
+				// 获取内容失败不影响创建书签
+				lib.Logger.Error("获取书签内容失败: " + err.Error())
+			} else {
+				if item.Title == "" {
+					bookmark.Title = content.Title
+				}
+				if item.Summary == "" {
+					bookmark.Excerpt = content.Excerpt
+				}
+				bookmark.Author = content.Author
+				bookmark.Content = content.Content
+				bookmark.HTML = content.HTML
+			}
+		}
+
+		if err := bookmarkRepo.Create(bookmark); err != nil {
+			lib.Logger.Error(fmt.Sprintf("创建书签失败: %s - %v", entry.URL, err))
+			result.Failed++
+			continue
+		}
+		markSeen(entry)
+		index.Default().Put(bookmark)
+		result.Created++
+		result.BookmarkIDs = append(result.BookmarkIDs, bookmark.ID)
+	}
+}
@@ -0,0 +1,96 @@
+package task
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bk_kms/utils"
+)
+
+const testPollFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>示例</title>
+    <item><title>4</title><link>https://example.com/4?utm_source=rss</link><guid isPermaLink="false">post-4</guid></item>
+    <item><title>3</title><link>https://example.com/3</link><guid isPermaLink="false">post-3</guid></item>
+    <item><title>3 重复</title><link>https://example.com/3-dup</link><guid isPermaLink="false">post-3</guid></item>
+    <item><title>2</title><link>https://example.com/2</link></item>
+    <item><title>1</title><link>https://example.com/1</link><guid isPermaLink="false">post-1</guid></item>
+  </channel>
+</rss>`
+
+func TestSelectFeedItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testPollFeed))
+	}))
+	defer server.Close()
+
+	feed, err := utils.FetchFeed(server.URL, "", "")
+	if err != nil {
+		t.Fatalf("FetchFeed() error = %v", err)
+	}
+	keyOf := func(guid, url string) string {
+		return feedItemKey(utils.FetchedFeedItem{GUID: guid}, url)
+	}
+
+	tests := []struct {
+		name     string
+		seen     map[string]bool
+		maxItems int
+		urls     []string
+		skipped  int
+	}{
+		{
+			name:     "全部未处理，按从旧到新的顺序，同一 GUID 只处理一次",
+			seen:     map[string]bool{},
+			maxItems: 20,
+			urls:     []string{"https://example.com/1", "https://example.com/2", "https://example.com/3-dup", "https://example.com/4"},
+			skipped:  1,
+		},
+		{
+			name: "已处理的条目（书签已删除）不再处理",
+			seen: map[string]bool{
+				keyOf("post-1", ""):                true,
+				keyOf("", "https://example.com/2"): true,
+			},
+			maxItems: 20,
+			urls:     []string{"https://example.com/3-dup", "https://example.com/4"},
+			skipped:  3,
+		},
+		{
+			name:     "只处理最新的 maxItems 条",
+			seen:     map[string]bool{keyOf("post-4", ""): true},
+			maxItems: 2,
+			urls:     []string{"https://example.com/3"},
+			skipped:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, skipped, failed := selectFeedItems(feed.Items, tt.seen, tt.maxItems)
+			if failed != 0 {
+				t.Errorf("failed = %d, want 0", failed)
+			}
+			if skipped != tt.skipped {
+				t.Errorf("skipped = %d, want %d", skipped, tt.skipped)
+			}
+			urls := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				urls = append(urls, entry.URL)
+				if entry.Key != feedItemKey(entry.Item, entry.URL) {
+					t.Errorf("entry %s has key %s, want %s", entry.URL, entry.Key, feedItemKey(entry.Item, entry.URL))
+				}
+			}
+			if len(urls) != len(tt.urls) {
+				t.Fatalf("urls = %v, want %v", urls, tt.urls)
+			}
+			for i := range urls {
+				if urls[i] != tt.urls[i] {
+					t.Errorf("urls = %v, want %v", urls, tt.urls)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"bk_kms/lib"
+	"bk_kms/model/db"
+	"bk_kms/repo"
+)
+
+// StartTrashPurge 启动回收站自动清理任务，彻底删除超过保留时长的书签
+func StartTrashPurge(retention, interval time.Duration) {
+	if retention <= 0 {
+		lib.Logger.Info("回收站自动清理未开启")
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	lib.Logger.Info(fmt.Sprintf("回收站自动清理已开启: 保留 %s, 检查间隔 %s", retention, interval))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purgeTrash(retention)
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeTrash 执行一次回收站清理
+func purgeTrash(retention time.Duration) {
+	ids, err := (&repo.BookmarkRepo{}).PurgeDeletedBefore(time.Now().Add(-retention))
+	if err != nil {
+		lib.Logger.Error("回收站自动清理失败: " + err.Error())
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	lib.Logger.Info(fmt.Sprintf("回收站自动清理完成: 删除 %d 个书签", len(ids)))
+
+	(&repo.AuditRepo{}).Record("system", db.AuditActionPurge, db.AuditEntityBookmark, ids, map[string]string{"retention": retention.String()})
+}
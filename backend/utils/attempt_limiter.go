@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// AttemptBackoff 连续失败 failures 次后的锁定时长：前 free 次不锁定，之后从 base 开始每次翻倍，最长 maxDelay
+func AttemptBackoff(failures, free int, base, maxDelay time.Duration) time.Duration {
+	if failures <= free {
+		return 0
+	}
+	delay := base
+	for i := free + 1; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// AttemptLimiter 按键（如客户端 IP）统计连续失败次数并按指数退避锁定，数据保存在内存中（仅适用于单实例部署）
+type AttemptLimiter struct {
+	free     int
+	base     time.Duration
+	maxDelay time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*attemptEntry
+}
+
+type attemptEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// maxAttemptEntries 记录数超过该值时清理过期的记录
+const maxAttemptEntries = 10000
+
+// NewAttemptLimiter 创建失败次数限制：前 free 次失败不锁定，之后锁定时长从 base 开始翻倍，最长 maxDelay
+func NewAttemptLimiter(free int, base, maxDelay time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		free:     free,
+		base:     base,
+		maxDelay: maxDelay,
+		entries:  make(map[string]*attemptEntry),
+	}
+}
+
+// Locked 剩余的锁定时长，0 表示未锁定
+func (l *AttemptLimiter) Locked(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry, ok := l.entries[key]; ok && entry.lockedUntil.After(now) {
+		return entry.lockedUntil.Sub(now)
+	}
+	return 0
+}
+
+// Fail 记录一次失败，返回本次失败后的锁定时长。距上次失败超过 maxDelay 时重新计数
+func (l *AttemptLimiter) Fail(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.entries[key]
+	if !ok || now.Sub(entry.lastFailure) > l.maxDelay {
+		if len(l.entries) >= maxAttemptEntries {
+			l.cleanup(now)
+		}
+		entry = &attemptEntry{}
+		l.entries[key] = entry
+	}
+	entry.failures++
+	entry.lastFailure = now
+	delay := AttemptBackoff(entry.failures, l.free, l.base, l.maxDelay)
+	if delay > 0 {
+		entry.lockedUntil = now.Add(delay)
+	}
+	return delay
+}
+
+// Reset 清除失败记录
+func (l *AttemptLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, key)
+}
+
+// cleanup 删除已经重新计数的记录（调用方需持有锁）
+func (l *AttemptLimiter) cleanup(now time.Time) {
+	for key, entry := range l.entries {
+		if now.Sub(entry.lastFailure) > l.maxDelay && !entry.lockedUntil.After(now) {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttemptBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Minute},
+		{5, 2 * time.Minute},
+		{6, 4 * time.Minute},
+		{9, 32 * time.Minute},
+		{10, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := AttemptBackoff(tt.failures, 3, time.Minute, time.Hour); got != tt.want {
+			t.Errorf("AttemptBackoff(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestAttemptLimiter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(2, time.Minute, 10*time.Minute)
+
+	steps := []struct {
+		name   string
+		at     time.Duration // 距 start 的时间
+		fail   bool          // 记录一次失败，否则只检查锁定
+		key    string
+		locked time.Duration // 操作后的剩余锁定时长
+	}{
+		{"允许的失败次数内不锁定", 0, true, "1.1.1.1", 0},
+		{"第二次失败仍不锁定", time.Second, true, "1.1.1.1", 0},
+		{"超过后锁定", 2 * time.Second, true, "1.1.1.1", time.Minute},
+		{"其他 IP 不受影响", 2 * time.Second, false, "2.2.2.2", 0},
+		{"锁定时间递减", 32 * time.Second, false, "1.1.1.1", 30 * time.Second},
+		{"锁定结束", 62 * time.Second, false, "1.1.1.1", 0},
+		{"再次失败锁定时长翻倍", 63 * time.Second, true, "1.1.1.1", 2 * time.Minute},
+		{"长时间没有失败后重新计数", 63*time.Second + 11*time.Minute, true, "1.1.1.1", 0},
+	}
+	for _, step := range steps {
+		now := start.Add(step.at)
+		if step.fail {
+			limiter.Fail(step.key, now)
+		}
+		if got := limiter.Locked(step.key, now); got != step.locked {
+			t.Errorf("%s: Locked(%s) = %s, want %s", step.name, step.key, got, step.locked)
+		}
+	}
+
+	limiter.Reset("1.1.1.1")
+	if _, ok := limiter.entries["1.1.1.1"]; ok {
+		t.Error("Reset() did not remove the entry")
+	}
+}
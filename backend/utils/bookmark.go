@@ -96,24 +96,25 @@ func FetchBookmarkContent(bookmarkURL string, keepTitle, keepExcerpt bool) (*Boo
 	return result, nil
 }
 
-// RemoveUTMParams 移除 URL 中的 UTM 参数
+// RemoveUTMParams 移除 URL 中的追踪参数（utm_* 以及配置的追踪参数规则）
 func RemoveUTMParams(rawURL string) (string, error) {
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
 		return "", err
 	}
+	if parsedURL.RawQuery == "" {
+		return parsedURL.String(), nil
+	}
 
 	// 获取查询参数
 	queries := parsedURL.Query()
 
-	// 移除 UTM 参数
-	utmParams := []string{
-		"utm_source", "utm_medium", "utm_campaign",
-		"utm_term", "utm_content", "utm_name",
-	}
-
-	for _, param := range utmParams {
-		queries.Del(param)
+	// 移除追踪参数
+	removeParams := trackingParamsFor(strings.TrimPrefix(strings.ToLower(parsedURL.Hostname()), "www."))
+	for name := range queries {
+		if matchAnyPattern(strings.ToLower(name), removeParams) {
+			queries.Del(name)
+		}
 	}
 
 	// 重新设置查询参数
@@ -141,9 +142,22 @@ type ParsedBookmark struct {
 	Title      string
 	Tags       []string
 	Category   string
+	Folders    []string // 所在的文件夹路径，从最外层到最内层
 	ModifiedAt time.Time
 }
 
+// bookmarkFolders 获取书签所在的文件夹路径：每层 <DL> 前的 <H3> 为文件夹名称
+func bookmarkFolders(a *goquery.Selection) []string {
+	var folders []string
+	a.ParentsFiltered("dl").Each(func(_ int, dl *goquery.Selection) {
+		name := NormalizeSpace(dl.PrevAllFiltered("h3").First().Text())
+		if name != "" {
+			folders = append([]string{name}, folders...)
+		}
+	})
+	return folders
+}
+
 // ParseNetscapeBookmarkHTML 解析 Netscape Bookmark 格式的 HTML 文件
 func ParseNetscapeBookmarkHTML(reader io.Reader, generateTag bool) ([]ParsedBookmark, error) {
 	doc, err := goquery.NewDocumentFromReader(reader)
@@ -189,8 +203,12 @@ func ParseNetscapeBookmarkHTML(reader io.Reader, generateTag bool) ([]ParsedBook
 		// 验证标题
 		title = ValidateTitle(title, cleanURL)
 
-		// 检查 URL 是否已存在
-		if _, exist := mapURL[cleanURL]; exist {
+		// 检查 URL 是否已存在（按规范化 URL 判重）
+		canonicalURL, err := CanonicalizeURL(cleanURL)
+		if err != nil {
+			return
+		}
+		if _, exist := mapURL[canonicalURL]; exist {
 			return
 		}
 
@@ -215,10 +233,11 @@ func ParseNetscapeBookmarkHTML(reader io.Reader, generateTag bool) ([]ParsedBook
 			Title:      title,
 			Tags:       tags,
 			Category:   category,
+			Folders:    bookmarkFolders(a),
 			ModifiedAt: modifiedDate,
 		}
 
-		mapURL[cleanURL] = struct{}{}
+		mapURL[canonicalURL] = struct{}{}
 		bookmarks = append(bookmarks, bookmark)
 	})
 
@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 书签原生导入导出格式
+const (
+	BookmarkRecordJSONL = "jsonl"
+	BookmarkRecordCSV   = "csv"
+)
+
+// BookmarkRecordColumns 书签原生格式的字段，也是 CSV 表头的顺序
+var BookmarkRecordColumns = []string{
+	"id", "url", "canonical_url", "title", "excerpt", "author", "content", "html",
+	"folder", "simhash", "is_archive", "tags", "created_at", "updated_at",
+}
+
+// BookmarkRecord 书签的原生导入导出格式，包含书签的全部字段与标签
+// SimHash 以字符串输出，避免超过 2^53 的整数在 JavaScript 与表格软件中丢失精度
+type BookmarkRecord struct {
+	ID           int       `json:"id"`
+	URL          string    `json:"url"`
+	CanonicalURL string    `json:"canonical_url"`
+	Title        string    `json:"title"`
+	Excerpt      string    `json:"excerpt"`
+	Author       string    `json:"author"`
+	Content      string    `json:"content"`
+	HTML         string    `json:"html"`
+	Folder       string    `json:"folder"`
+	SimHash      uint64    `json:"simhash,string"`
+	IsArchive    bool      `json:"is_archive"`
+	Tags         []string  `json:"tags"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// 以下字段只在导入时使用
+	Line   int             `json:"-"` // 记录在文件中的起始行号
+	Fields map[string]bool `json:"-"` // 记录中出现的字段，覆盖已有书签时只更新出现的字段
+}
+
+// RecordError 导入时单条记录的错误
+type RecordError struct {
+	Line    int    `json:"line"`
+	URL     string `json:"url,omitempty"`
+	Message string `json:"message"`
+}
+
+// Has 记录中是否出现了指定字段
+func (r *BookmarkRecord) Has(field string) bool {
+	return r.Fields[field]
+}
+
+// CSVRow 按 BookmarkRecordColumns 的顺序输出一行，标签使用英文逗号分隔，文本字段按 csvEscapeCell 转义
+func (r *BookmarkRecord) CSVRow() []string {
+	return []string{
+		strconv.Itoa(r.ID),
+		csvEscapeCell(r.URL),
+		csvEscapeCell(r.CanonicalURL),
+		csvEscapeCell(r.Title),
+		csvEscapeCell(r.Excerpt),
+		csvEscapeCell(r.Author),
+		csvEscapeCell(r.Content),
+		csvEscapeCell(r.HTML),
+		csvEscapeCell(r.Folder),
+		strconv.FormatUint(r.SimHash, 10),
+		strconv.FormatBool(r.IsArchive),
+		csvEscapeCell(strings.Join(r.Tags, ",")),
+		r.CreatedAt.Format(time.RFC3339),
+		r.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// csvFormulaPrefix 表格软件会作为公式执行的单元格开头
+const csvFormulaPrefix = "=+-@\t\r"
+
+// csvEscapeCell 以 = + - @ 制表符或回车开头的单元格（忽略开头的单引号）前加一个单引号，
+// 避免在表格软件中打开导出文件时作为公式执行；导入时由 csvUnescapeCell 去掉
+func csvEscapeCell(value string) string {
+	if rest := strings.TrimLeft(value, "'"); rest != "" && strings.IndexByte(csvFormulaPrefix, rest[0]) >= 0 {
+		return "'" + value
+	}
+	return value
+}
+
+// csvUnescapeCell 去掉 csvEscapeCell 添加的单引号
+func csvUnescapeCell(value string) string {
+	if strings.HasPrefix(value, "'") && csvEscapeCell(value[1:]) != value[1:] {
+		return value[1:]
+	}
+	return value
+}
+
+// ParseBookmarkRecords 解析 JSON Lines 或 CSV 格式的书签记录
+// 单条记录格式错误时记录到错误列表并继续解析，文件整体无法解析（如 CSV 缺少 url 列）时返回 error
+func ParseBookmarkRecords(r io.Reader, format string) ([]BookmarkRecord, []RecordError, error) {
+	switch format {
+	case BookmarkRecordJSONL:
+		return parseBookmarkJSONL(r)
+	case BookmarkRecordCSV:
+		return parseBookmarkCSV(r)
+	default:
+		return nil, nil, fmt.Errorf("不支持的格式: %s", format)
+	}
+}
+
+// parseBookmarkJSONL 每行一个 JSON 对象，空行忽略
+func parseBookmarkJSONL(r io.Reader) ([]BookmarkRecord, []RecordError, error) {
+	var records []BookmarkRecord
+	var errs []RecordError
+
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, nil, err
+		}
+		data = bytes.TrimSpace(data)
+		if line == 1 {
+			data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		}
+		if len(data) > 0 {
+			if record, recordErr := decodeBookmarkJSON(data); recordErr != nil {
+				errs = append(errs, RecordError{Line: line, Message: recordErr.Error()})
+			} else {
+				record.Line = line
+				records = append(records, record)
+			}
+		}
+		if err == io.EOF {
+			return records, errs, nil
+		}
+	}
+}
+
+func decodeBookmarkJSON(data []byte) (BookmarkRecord, error) {
+	var record BookmarkRecord
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return record, errors.New("JSON 格式错误: " + err.Error())
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, errors.New("字段格式错误: " + err.Error())
+	}
+	record.Fields = make(map[string]bool, len(fields))
+	for field := range fields {
+		record.Fields[field] = true
+	}
+	return record, nil
+}
+
+// parseBookmarkCSV 第一行为表头，按表头匹配字段，未知的列忽略，必须包含 url 列
+func parseBookmarkCSV(r io.Reader) ([]BookmarkRecord, []RecordError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, errors.New("CSV 表头解析失败: " + err.Error())
+	}
+
+	columns := make(map[int]string, len(header))
+	hasURL := false
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\xef\xbb\xbf")))
+		columns[i] = name
+		if name == "url" {
+			hasURL = true
+		}
+	}
+	if !hasURL {
+		return nil, nil, errors.New("CSV 缺少 url 列")
+	}
+
+	var records []BookmarkRecord
+	var errs []RecordError
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, errs, nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			errs = append(errs, RecordError{Line: parseErr.StartLine, Message: "CSV 格式错误: " + parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		record := BookmarkRecord{Line: line, Fields: make(map[string]bool, len(row))}
+		if recordErr := record.setCSVFields(columns, row); recordErr != nil {
+			errs = append(errs, RecordError{Line: line, URL: record.URL, Message: recordErr.Error()})
+			continue
+		}
+		records = append(records, record)
+	}
+}
+
+// setCSVFields 按列名设置字段
+func (r *BookmarkRecord) setCSVFields(columns map[int]string, row []string) error {
+	for i, value := range row {
+		name, ok := columns[i]
+		if !ok {
+			continue
+		}
+		value = csvUnescapeCell(value)
+		var err error
+		switch name {
+		case "id":
+			if value != "" {
+				r.ID, err = strconv.Atoi(value)
+			}
+		case "url":
+			r.URL = strings.TrimSpace(value)
+		case "canonical_url":
+			r.CanonicalURL = value
+		case "title":
+			r.Title = value
+		case "excerpt":
+			r.Excerpt = value
+		case "author":
+			r.Author = value
+		case "content":
+			r.Content = value
+		case "html":
+			r.HTML = value
+		case "folder":
+			r.Folder = value
+		case "simhash":
+			if value != "" {
+				r.SimHash, err = strconv.ParseUint(value, 10, 64)
+			}
+		case "is_archive":
+			if value != "" {
+				r.IsArchive, err = strconv.ParseBool(value)
+			}
+		case "tags":
+			r.Tags = nil
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					r.Tags = append(r.Tags, tag)
+				}
+			}
+		case "created_at":
+			r.CreatedAt, err = parseRecordTime(value)
+		case "updated_at":
+			r.UpdatedAt, err = parseRecordTime(value)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("字段 %s 格式错误: %s", name, value)
+		}
+		r.Fields[name] = true
+	}
+	return nil
+}
+
+// parseRecordTime 解析 RFC3339 或 "2006-01-02 15:04:05"（本地时区）格式的时间，空字符串返回零值
+func parseRecordTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestCSVEscapeCell(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"普通文本", "Go 语言", "Go 语言"},
+		{"空字符串", "", ""},
+		{"等号", "=HYPERLINK(\"https://evil.example.com\")", "'=HYPERLINK(\"https://evil.example.com\")"},
+		{"加号", "+1+1", "'+1+1"},
+		{"减号", "-2+3", "'-2+3"},
+		{"at 符号", "@SUM(A1)", "'@SUM(A1)"},
+		{"制表符", "\t=1", "'\t=1"},
+		{"回车", "\r=1", "'\r=1"},
+		{"中间的等号", "a=b", "a=b"},
+		{"单引号开头的普通文本", "'quoted'", "'quoted'"},
+		{"单引号开头的公式", "'=1", "''=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := csvEscapeCell(tt.value)
+			if got != tt.want {
+				t.Errorf("csvEscapeCell(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+			if back := csvUnescapeCell(got); back != tt.value {
+				t.Errorf("csvUnescapeCell(%q) = %q, want %q", got, back, tt.value)
+			}
+		})
+	}
+}
+
+func TestBookmarkCSVRoundTrip(t *testing.T) {
+	record := BookmarkRecord{
+		URL:     "https://example.com/a",
+		Title:   "=cmd|' /C calc'!A0",
+		Excerpt: "-摘要",
+		Author:  "@作者",
+		Content: "'=正文",
+		Folder:  "+收藏",
+		Tags:    []string{"=标签", "go"},
+	}
+
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write(BookmarkRecordColumns)
+	row := record.CSVRow()
+	cw.Write(row)
+	cw.Flush()
+	for i, cell := range row {
+		if cell != "" && strings.IndexByte(csvFormulaPrefix, cell[0]) >= 0 {
+			t.Errorf("column %s = %q starts with a formula character", BookmarkRecordColumns[i], cell)
+		}
+	}
+
+	records, errs, err := ParseBookmarkRecords(&buf, BookmarkRecordCSV)
+	if err != nil || len(errs) != 0 || len(records) != 1 {
+		t.Fatalf("ParseBookmarkRecords() = %d records, errs %v, err %v", len(records), errs, err)
+	}
+	got := records[0]
+	if got.Title != record.Title || got.Excerpt != record.Excerpt || got.Author != record.Author ||
+		got.Content != record.Content || got.Folder != record.Folder ||
+		len(got.Tags) != 2 || got.Tags[0] != "=标签" || got.Tags[1] != "go" {
+		t.Errorf("round trip = %+v, want %+v", got, record)
+	}
+}
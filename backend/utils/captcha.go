@@ -3,107 +3,107 @@ package utils
 import (
 	"bytes"
 	"encoding/base64"
-	"sync"
 	"time"
 
 	"github.com/dchest/captcha"
 )
 
-// captchaValueStore 存储验证码的实际值（用于测试环境）
-type captchaValueStore struct {
-	sync.RWMutex
-	values map[string]captchaValue
+// CaptchaOptions 验证码生成参数
+type CaptchaOptions struct {
+	Length     int           // 验证码位数
+	Width      int           // 图片宽度
+	Height     int           // 图片高度
+	Expiration time.Duration // 有效期
+	Lang       string        // 语音验证码语言
 }
 
-type captchaValue struct {
-	value     string
-	expiresAt time.Time
+// 默认验证码参数
+var captchaOptions = CaptchaOptions{
+	Length:     4,
+	Width:      120,
+	Height:     40,
+	Expiration: 5 * time.Minute,
+	Lang:       "zh",
 }
 
-var valueStore = &captchaValueStore{
-	values: make(map[string]captchaValue),
-}
+// captchaStore 当前使用的验证码存储
+var captchaStore captcha.Store
 
-// 清理过期的验证码值
-func (s *captchaValueStore) cleanup() {
-	s.Lock()
-	defer s.Unlock()
-	
-	now := time.Now()
-	for id, v := range s.values {
-		if now.After(v.expiresAt) {
-			delete(s.values, id)
-		}
-	}
+// NewCaptchaMemoryStore 创建内存验证码存储（仅适用于单实例部署）
+func NewCaptchaMemoryStore(expiration time.Duration) captcha.Store {
+	return captcha.NewMemoryStore(captcha.CollectNum, expiration)
 }
 
-// 保存验证码值
-func (s *captchaValueStore) set(id, value string) {
-	s.Lock()
-	defer s.Unlock()
-	
-	s.values[id] = captchaValue{
-		value:     value,
-		expiresAt: time.Now().Add(5 * time.Minute),
+// InitCaptcha 初始化验证码存储和参数，需在服务启动时调用一次
+func InitCaptcha(store captcha.Store, opts CaptchaOptions) {
+	if opts.Length <= 0 {
+		opts.Length = captchaOptions.Length
 	}
-}
-
-// 获取验证码值
-func (s *captchaValueStore) get(id string) string {
-	s.RLock()
-	defer s.RUnlock()
-	
-	if v, ok := s.values[id]; ok && time.Now().Before(v.expiresAt) {
-		return v.value
+	if opts.Width <= 0 {
+		opts.Width = captchaOptions.Width
+	}
+	if opts.Height <= 0 {
+		opts.Height = captchaOptions.Height
 	}
-	return ""
+	if opts.Expiration <= 0 {
+		opts.Expiration = captchaOptions.Expiration
+	}
+	if opts.Lang == "" {
+		opts.Lang = captchaOptions.Lang
+	}
+	if store == nil {
+		store = NewCaptchaMemoryStore(opts.Expiration)
+	}
+
+	captchaOptions = opts
+	captchaStore = store
+	captcha.SetCustomStore(store)
 }
 
 // GenerateCaptcha 生成验证码
 // 返回: id(验证码ID), base64Img(图片base64), captchaStr(验证码实际值), error
 func GenerateCaptcha() (id string, base64Img string, captchaStr string, error error) {
-	// 定期清理过期的验证码值
-	go valueStore.cleanup()
-	
-	// 1. 生成验证码数字
-	digits := captcha.RandomDigits(4)
-	
-	// 2. 将数字转换为字符串（这是验证码的实际值）
-	captchaStr = ""
-	for _, d := range digits {
-		captchaStr += string('0' + d)
-	}
-	
-	// 3. 生成验证码ID
-	id = captcha.New()
-	
-	// 4. 使用自定义存储，将我们的数字存储进去
-	// 这样 captcha.WriteImage 和 captcha.VerifyString 都会使用相同的数字
-	store := captcha.NewMemoryStore(captcha.CollectNum, captcha.Expiration)
-	store.Set(id, digits)
-	captcha.SetCustomStore(store)
-	
-	// 5. 将实际值也存储到我们的 valueStore（用于测试环境返回）
-	valueStore.set(id, captchaStr)
+	// 1. 生成验证码ID，数字会写入全局存储
+	id = captcha.NewLen(captchaOptions.Length)
+
+	// 2. 读取验证码实际值（不清除）
+	captchaStr = GetCaptchaValue(id)
 
-	// 6. 生成验证码图片（会使用我们设置的 digits）
+	// 3. 生成验证码图片
 	var buf bytes.Buffer
-	if err := captcha.WriteImage(&buf, id, 120, 40); err != nil {
+	if err := captcha.WriteImage(&buf, id, captchaOptions.Width, captchaOptions.Height); err != nil {
 		return "", "", "", err
 	}
 
-	// 7. 转换为 base64
+	// 4. 转换为 base64
 	base64Img = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
 
 	return id, base64Img, captchaStr, nil
 }
 
-// VerifyCaptcha 验证验证码
+// GenerateCaptchaAudio 生成验证码对应的语音（WAV, base64）
+func GenerateCaptchaAudio(id string) (string, error) {
+	var buf bytes.Buffer
+	if err := captcha.WriteAudio(&buf, id, captchaOptions.Lang); err != nil {
+		return "", err
+	}
+	return "data:audio/wav;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// VerifyCaptcha 验证验证码（验证后立即失效）
 func VerifyCaptcha(id, digits string) bool {
 	return captcha.VerifyString(id, digits)
 }
 
 // GetCaptchaValue 获取验证码的实际值（仅用于测试）
 func GetCaptchaValue(id string) string {
-	return valueStore.get(id)
+	if captchaStore == nil {
+		return ""
+	}
+	digits := captchaStore.Get(id, false)
+	value := make([]byte, len(digits))
+	for i, d := range digits {
+		value[i] = '0' + d
+	}
+	return string(value)
 }
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// FieldDiff 单个字段的变更
+type FieldDiff struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// DiffJSON 比较两个对象（按 JSON 字段）并返回变更内容的 JSON
+// before 为 nil 表示新建，after 为 nil 表示删除，此时返回全部字段
+func DiffJSON(before, after interface{}) (string, error) {
+	beforeMap, err := toJSONMap(before)
+	if err != nil {
+		return "", err
+	}
+	afterMap, err := toJSONMap(after)
+	if err != nil {
+		return "", err
+	}
+
+	// 缺少的字段与值为 null 的字段视为相同
+	diff := make(map[string]FieldDiff)
+	for key, b := range beforeMap {
+		if a := afterMap[key]; !reflect.DeepEqual(a, b) {
+			diff[key] = FieldDiff{Before: b, After: a}
+		}
+	}
+	for key, a := range afterMap {
+		if _, ok := beforeMap[key]; !ok && a != nil {
+			diff[key] = FieldDiff{After: a}
+		}
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// toJSONMap 将对象转换为 JSON 字段 map
+func toJSONMap(v interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return result, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
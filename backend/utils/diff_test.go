@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiffJSON(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Count int      `json:"count"`
+		Note  *string  `json:"note"`
+	}
+	var nilItem *item
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   map[string]FieldDiff
+	}{
+		{
+			name:   "修改的字段",
+			before: item{Name: "a", Tags: []string{"go"}, Count: 1},
+			after:  item{Name: "b", Tags: []string{"go", "rust"}, Count: 1},
+			want: map[string]FieldDiff{
+				"name": {Before: "a", After: "b"},
+				"tags": {Before: []interface{}{"go"}, After: []interface{}{"go", "rust"}},
+			},
+		},
+		{
+			name:   "没有变化",
+			before: item{Name: "a", Count: 1},
+			after:  &item{Name: "a", Count: 1},
+			want:   map[string]FieldDiff{},
+		},
+		{
+			name:   "新建时输出全部非空字段",
+			before: nil,
+			after:  item{Name: "a", Count: 0},
+			want: map[string]FieldDiff{
+				"name":  {After: "a"},
+				"count": {After: float64(0)},
+			},
+		},
+		{
+			name:   "删除时输出全部非空字段",
+			before: item{Name: "a", Tags: []string{}},
+			after:  nilItem,
+			want: map[string]FieldDiff{
+				"name":  {Before: "a"},
+				"tags":  {Before: []interface{}{}},
+				"count": {Before: float64(0)},
+			},
+		},
+		{
+			name:   "零值变化",
+			before: map[string]interface{}{"enabled": true, "count": 1},
+			after:  map[string]interface{}{"enabled": false, "count": 0},
+			want: map[string]FieldDiff{
+				"enabled": {Before: true, After: false},
+				"count":   {Before: float64(1), After: float64(0)},
+			},
+		},
+		{
+			name:   "缺少的字段与 null 视为相同",
+			before: map[string]interface{}{"a": nil, "b": 1},
+			after:  map[string]interface{}{"c": nil, "b": 1},
+			want:   map[string]FieldDiff{},
+		},
+		{
+			name:   "新增与移除的字段",
+			before: map[string]interface{}{"a": 1},
+			after:  map[string]interface{}{"b": "x"},
+			want: map[string]FieldDiff{
+				"a": {Before: float64(1)},
+				"b": {After: "x"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffJSON(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("DiffJSON() error = %v", err)
+			}
+			var diff map[string]FieldDiff
+			if err := json.Unmarshal([]byte(got), &diff); err != nil {
+				t.Fatalf("DiffJSON() = %s is not valid JSON: %v", got, err)
+			}
+			if !reflect.DeepEqual(diff, tt.want) {
+				t.Errorf("DiffJSON() = %s, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := DiffJSON(nil, map[string]interface{}{"f": func() {}}); err == nil {
+		t.Error("DiffJSON() with an unsupported value error = nil, want error")
+	}
+}
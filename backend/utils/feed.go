@@ -0,0 +1,284 @@
+package utils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// 订阅源格式
+const (
+	FeedFormatRSS  = "rss"  // RSS 2.0
+	FeedFormatAtom = "atom" // Atom 1.0
+	FeedFormatJSON = "json" // JSON Feed 1.1
+)
+
+// Feed 订阅源
+type Feed struct {
+	Title       string
+	Link        string // 订阅源对应的页面地址
+	FeedURL     string // 订阅源自身的地址
+	Description string
+	Updated     time.Time
+	Items       []FeedItem
+}
+
+// FeedItem 订阅源条目
+type FeedItem struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string // 摘要（纯文本）
+	Content   string // 正文（HTML），为空时只输出摘要
+	Author    string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+}
+
+// FeedContentType 订阅源格式对应的 Content-Type
+func FeedContentType(format string) string {
+	switch format {
+	case FeedFormatJSON:
+		return "application/feed+json; charset=utf-8"
+	case FeedFormatAtom:
+		return "application/atom+xml; charset=utf-8"
+	}
+	return "application/rss+xml; charset=utf-8"
+}
+
+// RenderFeed 按指定格式生成订阅源，未知格式按 RSS 2.0 处理
+func RenderFeed(feed *Feed, format string) ([]byte, error) {
+	switch format {
+	case FeedFormatJSON:
+		return RenderJSONFeed(feed)
+	case FeedFormatAtom:
+		return RenderAtom(feed)
+	}
+	return RenderRSS(feed)
+}
+
+type rssDocument struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	GUID        rssGUID   `xml:"guid"`
+	Description string    `xml:"description"`
+	Content     *rssCDATA `xml:"content:encoded,omitempty"`
+	Categories  []string  `xml:"category"`
+	PubDate     string    `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+// RenderRSS 生成 RSS 2.0 订阅源
+func RenderRSS(feed *Feed) ([]byte, error) {
+	doc := rssDocument{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			AtomLink:      rssLink{Href: feed.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			Description:   feed.Description,
+			LastBuildDate: feed.Updated.Format(time.RFC1123Z),
+		},
+	}
+	for _, item := range feed.Items {
+		rss := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.ID},
+			Description: item.Summary,
+			Categories:  item.Tags,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		}
+		if item.Content != "" {
+			rss.Content = &rssCDATA{Value: item.Content}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, rss)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   atomAuthor  `xml:"author"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    *atomContent   `xml:"content,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// RenderAtom 生成 Atom 1.0 订阅源
+func RenderAtom(feed *Feed) ([]byte, error) {
+	doc := atomFeed{
+		ID:       feed.FeedURL,
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		Updated:  feed.Updated.Format(time.RFC3339),
+		Author:   atomAuthor{Name: "bk_kms"},
+		Links: []atomLink{
+			{Href: feed.Link, Rel: "alternate", Type: "text/html"},
+			{Href: feed.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+	for _, item := range feed.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Link:      atomLink{Href: item.Link, Rel: "alternate"},
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Published.Format(time.RFC3339),
+			Summary:   item.Summary,
+		}
+		if !item.Updated.IsZero() {
+			entry.Updated = item.Updated.Format(time.RFC3339)
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		if item.Content != "" {
+			entry.Content = &atomContent{Type: "html", Value: item.Content}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	Summary       string           `json:"summary,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// RenderJSONFeed 生成 JSON Feed 1.1 订阅源
+func RenderJSONFeed(feed *Feed) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     feed.FeedURL,
+		Description: feed.Description,
+		Items:       make([]jsonFeedItem, 0, len(feed.Items)),
+	}
+	for _, item := range feed.Items {
+		entry := jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			Summary:       item.Summary,
+			ContentHTML:   item.Content,
+			Tags:          item.Tags,
+			DatePublished: item.Published.Format(time.RFC3339),
+		}
+		// JSON Feed 要求 content_html 与 content_text 至少有一项
+		if entry.ContentHTML == "" {
+			entry.ContentText = item.Summary
+		}
+		if item.Author != "" {
+			entry.Authors = []jsonFeedAuthor{{Name: item.Author}}
+		}
+		if !item.Updated.IsZero() {
+			entry.DateModified = item.Updated.Format(time.RFC3339)
+		}
+		doc.Items = append(doc.Items, entry)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
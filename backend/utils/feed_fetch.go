@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+const maxFeedSize = 10 << 20 // 订阅源最大 10MB
+
+// FetchedFeed 拉取到的订阅源
+type FetchedFeed struct {
+	NotModified  bool   // 服务端返回 304，内容未变化
+	ETag         string // 响应的 ETag，下次请求时作为 If-None-Match
+	LastModified string // 响应的 Last-Modified，下次请求时作为 If-Modified-Since
+	Title        string
+	Items        []FetchedFeedItem
+}
+
+// FetchedFeedItem 订阅源中的条目
+type FetchedFeedItem struct {
+	GUID      string // 条目的唯一标识：RSS 的 guid、Atom 的 id、JSON Feed 的 id，可能为空
+	URL       string
+	Title     string
+	Summary   string
+	Published time.Time
+}
+
+// FetchFeed 使用条件请求（ETag / Last-Modified）拉取并解析订阅源，支持 RSS 2.0、RSS 1.0、Atom 与 JSON Feed
+func FetchFeed(feedURL, etag, lastModified string) (*FetchedFeed, error) {
+	req, err := http.NewRequest("GET", feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", "bk_kms feed fetcher")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/json;q=0.9, application/xml;q=0.8, text/xml;q=0.8, */*;q=0.5")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("下载订阅源失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &FetchedFeed{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP 状态码错误: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取订阅源失败: %w", err)
+	}
+	if len(data) > maxFeedSize {
+		return nil, fmt.Errorf("订阅源超过 %dMB", maxFeedSize>>20)
+	}
+
+	feed, err := ParseFeed(data)
+	if err != nil {
+		return nil, err
+	}
+	feed.ETag = resp.Header.Get("ETag")
+	feed.LastModified = resp.Header.Get("Last-Modified")
+
+	// 条目中的相对地址按订阅源地址解析
+	base, _ := url.Parse(feedURL)
+	for i := range feed.Items {
+		if ref, err := url.Parse(feed.Items[i].URL); err == nil && base != nil {
+			feed.Items[i].URL = base.ResolveReference(ref).String()
+		}
+	}
+	return feed, nil
+}
+
+// ParseFeed 解析订阅源内容，根据内容自动识别格式
+func ParseFeed(data []byte) (*FetchedFeed, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseJSONFeed(trimmed)
+	}
+	return parseXMLFeed(trimmed)
+}
+
+// parsedXMLFeed 同时兼容 RSS 2.0（rss/channel/item）、RSS 1.0（rdf:RDF/item）与 Atom（feed/entry）
+type parsedXMLFeed struct {
+	XMLName xml.Name
+	Title   string `xml:"title"`
+	Channel struct {
+		Title string          `xml:"title"`
+		Items []parsedXMLItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []parsedXMLItem `xml:"item"`
+	Entries []parsedXMLItem `xml:"entry"`
+}
+
+type parsedXMLItem struct {
+	Title string `xml:"title"`
+	Links []struct {
+		Href  string `xml:"href,attr"`
+		Rel   string `xml:"rel,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"link"`
+	GUID struct {
+		IsPermaLink string `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	} `xml:"guid"`
+	ID          string `xml:"id"`
+	Description string `xml:"description"`
+	Summary     string `xml:"summary"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Published   string `xml:"published"`
+	Updated     string `xml:"updated"`
+}
+
+// parseXMLFeed 解析 RSS / Atom 订阅源
+func parseXMLFeed(data []byte) (*FetchedFeed, error) {
+	var doc parsedXMLFeed
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.CharsetReader = charset.NewReaderLabel // 支持 GBK 等非 UTF-8 编码
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析订阅源失败: %w", err)
+	}
+
+	feed := &FetchedFeed{}
+	var items []parsedXMLItem
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss":
+		feed.Title = doc.Channel.Title
+		items = doc.Channel.Items
+	case "rdf":
+		feed.Title = doc.Channel.Title
+		items = doc.Items
+	case "feed":
+		feed.Title = doc.Title
+		items = doc.Entries
+	default:
+		return nil, fmt.Errorf("不支持的订阅源格式: %s", doc.XMLName.Local)
+	}
+	feed.Title = NormalizeSpace(feed.Title)
+
+	for _, item := range items {
+		parsed := FetchedFeedItem{
+			GUID:      strings.TrimSpace(firstNonEmpty(item.GUID.Value, item.ID)),
+			Title:     NormalizeSpace(item.Title),
+			URL:       xmlItemLink(item),
+			Summary:   htmlText(firstNonEmpty(item.Description, item.Summary)),
+			Published: parseFeedTime(firstNonEmpty(item.PubDate, item.Published, item.Date, item.Updated)),
+		}
+		if parsed.URL != "" {
+			feed.Items = append(feed.Items, parsed)
+		}
+	}
+	return feed, nil
+}
+
+// xmlItemLink 条目的网页地址：Atom 优先 rel=alternate 的链接，RSS 使用 link 或可作为链接的 guid
+func xmlItemLink(item parsedXMLItem) string {
+	for _, link := range item.Links {
+		if link.Href != "" && (link.Rel == "" || link.Rel == "alternate") {
+			return strings.TrimSpace(link.Href)
+		}
+		if value := strings.TrimSpace(link.Value); value != "" {
+			return value
+		}
+	}
+	guid := strings.TrimSpace(item.GUID.Value)
+	if item.GUID.IsPermaLink != "false" && (strings.HasPrefix(guid, "http://") || strings.HasPrefix(guid, "https://")) {
+		return guid
+	}
+	return ""
+}
+
+type parsedJSONFeed struct {
+	Version string `json:"version"`
+	Title   string `json:"title"`
+	Items   []struct {
+		ID            string `json:"id"`
+		URL           string `json:"url"`
+		ExternalURL   string `json:"external_url"`
+		Title         string `json:"title"`
+		Summary       string `json:"summary"`
+		ContentText   string `json:"content_text"`
+		DatePublished string `json:"date_published"`
+		DateModified  string `json:"date_modified"`
+	} `json:"items"`
+}
+
+// parseJSONFeed 解析 JSON Feed 订阅源
+func parseJSONFeed(data []byte) (*FetchedFeed, error) {
+	var doc parsedJSONFeed
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析订阅源失败: %w", err)
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("不支持的订阅源格式: %s", doc.Version)
+	}
+
+	feed := &FetchedFeed{Title: NormalizeSpace(doc.Title)}
+	for _, item := range doc.Items {
+		parsed := FetchedFeedItem{
+			GUID:      strings.TrimSpace(item.ID),
+			Title:     NormalizeSpace(item.Title),
+			URL:       strings.TrimSpace(firstNonEmpty(item.URL, item.ExternalURL)),
+			Summary:   htmlText(firstNonEmpty(item.Summary, item.ContentText)),
+			Published: parseFeedTime(firstNonEmpty(item.DatePublished, item.DateModified)),
+		}
+		if parsed.URL != "" {
+			feed.Items = append(feed.Items, parsed)
+		}
+	}
+	return feed, nil
+}
+
+// feedTimeLayouts 订阅源中常见的时间格式
+var feedTimeLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseFeedTime 解析订阅源中的时间，无法解析时返回零值
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// htmlText 提取 HTML 片段中的文本（RSS 的 description 通常包含 HTML）
+func htmlText(s string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(s))
+	if err != nil {
+		return NormalizeSpace(s)
+	}
+	return NormalizeSpace(doc.Text())
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title> 示例 RSS </title>
+    <item>
+      <title>第二篇</title>
+      <link>https://example.com/posts/2</link>
+      <guid isPermaLink="false">post-2</guid>
+      <description>&lt;p&gt;第二篇 &lt;b&gt;摘要&lt;/b&gt;&lt;/p&gt;</description>
+      <pubDate>Tue, 02 Jan 2024 08:00:00 +0000</pubDate>
+    </item>
+    <item>
+      <title>第一篇</title>
+      <guid>https://example.com/posts/1</guid>
+      <pubDate>Mon, 01 Jan 2024 08:00:00 +0000</pubDate>
+    </item>
+    <item>
+      <title>没有地址</title>
+      <guid isPermaLink="false">no-link</guid>
+    </item>
+  </channel>
+</rss>`
+
+const testAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>示例 Atom</title>
+  <entry>
+    <title>Atom 条目</title>
+    <id>tag:example.com,2024:entry-1</id>
+    <link rel="self" href="https://example.com/feed/entry-1"/>
+    <link rel="alternate" href="/entries/1"/>
+    <summary>Atom 摘要</summary>
+    <published>2024-01-03T10:00:00Z</published>
+  </entry>
+</feed>`
+
+const testJSONFeed = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "示例 JSON Feed",
+  "items": [
+    {
+      "id": "json-1",
+      "url": "https://example.com/json/1",
+      "title": "JSON 条目",
+      "content_text": "JSON 正文",
+      "date_published": "2024-01-04T12:00:00Z"
+    },
+    {
+      "id": "json-2",
+      "external_url": "https://other.example.com/2",
+      "summary": "外部链接"
+    }
+  ]
+}`
+
+func TestFetchFeed(t *testing.T) {
+	feeds := map[string]struct {
+		contentType string
+		body        string
+	}{
+		"/rss":  {"application/rss+xml", testRSS},
+		"/atom": {"application/atom+xml", testAtom},
+		"/json": {"application/feed+json", testJSONFeed},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		feed, ok := feeds[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", feed.contentType)
+		w.Header().Set("ETag", `"`+r.URL.Path+`"`)
+		w.Write([]byte(feed.body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		path  string
+		title string
+		items []FetchedFeedItem
+	}{
+		{
+			name:  "RSS 2.0",
+			path:  "/rss",
+			title: "示例 RSS",
+			items: []FetchedFeedItem{
+				{GUID: "post-2", URL: "https://example.com/posts/2", Title: "第二篇", Summary: "第二篇 摘要", Published: time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)},
+				{GUID: "https://example.com/posts/1", URL: "https://example.com/posts/1", Title: "第一篇", Published: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "Atom 相对地址按订阅源地址解析",
+			path:  "/atom",
+			title: "示例 Atom",
+			items: []FetchedFeedItem{
+				{GUID: "tag:example.com,2024:entry-1", URL: server.URL + "/entries/1", Title: "Atom 条目", Summary: "Atom 摘要", Published: time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "JSON Feed",
+			path:  "/json",
+			title: "示例 JSON Feed",
+			items: []FetchedFeedItem{
+				{GUID: "json-1", URL: "https://example.com/json/1", Title: "JSON 条目", Summary: "JSON 正文", Published: time.Date(2024, 1, 4, 12, 0, 0, 0, time.UTC)},
+				{GUID: "json-2", URL: "https://other.example.com/2", Summary: "外部链接"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := FetchFeed(server.URL+tt.path, "", "")
+			if err != nil {
+				t.Fatalf("FetchFeed() error = %v", err)
+			}
+			if feed.NotModified {
+				t.Errorf("NotModified = true, want false")
+			}
+			if feed.ETag != `"`+tt.path+`"` {
+				t.Errorf("ETag = %q, want %q", feed.ETag, `"`+tt.path+`"`)
+			}
+			if feed.Title != tt.title {
+				t.Errorf("Title = %q, want %q", feed.Title, tt.title)
+			}
+			if len(feed.Items) != len(tt.items) {
+				t.Fatalf("len(Items) = %d, want %d: %+v", len(feed.Items), len(tt.items), feed.Items)
+			}
+			for i, want := range tt.items {
+				got := feed.Items[i]
+				if got.GUID != want.GUID || got.URL != want.URL || got.Title != want.Title ||
+					got.Summary != want.Summary || !got.Published.Equal(want.Published) {
+					t.Errorf("Items[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchFeedConditional(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 01 Jan 2024 08:00:00 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag || r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(testRSS))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		etag         string
+		lastModified string
+		notModified  bool
+	}{
+		{name: "首次拉取", notModified: false},
+		{name: "ETag 未变化", etag: etag, notModified: true},
+		{name: "Last-Modified 未变化", lastModified: lastModified, notModified: true},
+		{name: "ETag 已变化", etag: `"v0"`, notModified: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := FetchFeed(server.URL, tt.etag, tt.lastModified)
+			if err != nil {
+				t.Fatalf("FetchFeed() error = %v", err)
+			}
+			if feed.NotModified != tt.notModified {
+				t.Fatalf("NotModified = %v, want %v", feed.NotModified, tt.notModified)
+			}
+			// 304 时保留原有的缓存信息，否则使用响应中的缓存信息
+			if feed.NotModified {
+				if feed.ETag != tt.etag || feed.LastModified != tt.lastModified || len(feed.Items) != 0 {
+					t.Errorf("got ETag=%q LastModified=%q items=%d, want cached values and no items", feed.ETag, feed.LastModified, len(feed.Items))
+				}
+			} else if feed.ETag != etag || feed.LastModified != lastModified || len(feed.Items) == 0 {
+				t.Errorf("got ETag=%q LastModified=%q items=%d, want response values", feed.ETag, feed.LastModified, len(feed.Items))
+			}
+		})
+	}
+}
+
+func TestFetchFeedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/html":
+			w.Write([]byte("<html><body>not a feed</body></html>"))
+		case "/json":
+			w.Write([]byte(`{"title": "not a feed"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/html", "/json", "/error"} {
+		t.Run(path, func(t *testing.T) {
+			if _, err := FetchFeed(server.URL+path, "", ""); err == nil {
+				t.Errorf("FetchFeed(%s) error = nil, want error", path)
+			}
+		})
+	}
+}
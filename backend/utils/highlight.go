@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// TextAnchor 高亮在正文中的位置（按字符计算，不含 EndOffset）
+type TextAnchor struct {
+	StartOffset int
+	EndOffset   int
+}
+
+// AnchorText 在正文中定位选中的文本：找出全部出现位置，优先前后文吻合最多的，
+// 其次离原位置最近的；找不到时返回 false
+func AnchorText(text, quote, prefix, suffix string, hint int) (TextAnchor, bool) {
+	textRunes := []rune(text)
+	quoteRunes := []rune(quote)
+	if len(quoteRunes) == 0 || len(quoteRunes) > len(textRunes) {
+		return TextAnchor{}, false
+	}
+	prefixRunes := []rune(prefix)
+	suffixRunes := []rune(suffix)
+
+	best, bestScore, bestDistance := -1, -1, 0
+	for start := 0; start+len(quoteRunes) <= len(textRunes); start++ {
+		if !runesEqual(textRunes[start:start+len(quoteRunes)], quoteRunes) {
+			continue
+		}
+		end := start + len(quoteRunes)
+		score := commonSuffixLen(textRunes[:start], prefixRunes) + commonPrefixLen(textRunes[end:], suffixRunes)
+		distance := start - hint
+		if distance < 0 {
+			distance = -distance
+		}
+		if score > bestScore || (score == bestScore && distance < bestDistance) {
+			best, bestScore, bestDistance = start, score, distance
+		}
+	}
+	if best < 0 {
+		return TextAnchor{}, false
+	}
+	return TextAnchor{StartOffset: best, EndOffset: best + len(quoteRunes)}, true
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// commonSuffixLen a 与 b 末尾相同的字符数
+func commonSuffixLen(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+// commonPrefixLen a 与 b 开头相同的字符数
+func commonPrefixLen(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// MarkdownHighlight 导出为 Markdown 的高亮
+type MarkdownHighlight struct {
+	Quote     string
+	Note      string
+	CreatedAt time.Time
+}
+
+// RenderHighlightsMarkdown 将一个书签的高亮渲染为 Markdown，以书签标题作为二级标题
+func RenderHighlightsMarkdown(title, link string, highlights []MarkdownHighlight) string {
+	return "## [" + MarkdownEscape(title) + "](" + link + ")\n\n" + RenderHighlightQuotes(highlights)
+}
+
+// RenderHighlightQuotes 将高亮渲染为 Markdown：选中文本为引用块，批注为引用块之后的段落
+func RenderHighlightQuotes(highlights []MarkdownHighlight) string {
+	var sb strings.Builder
+	for _, highlight := range highlights {
+		for _, line := range strings.Split(strings.TrimSpace(highlight.Quote), "\n") {
+			sb.WriteString("> " + strings.TrimSpace(line) + "\n")
+		}
+		sb.WriteString("\n")
+		if note := strings.TrimSpace(highlight.Note); note != "" {
+			sb.WriteString(note + "\n\n")
+		}
+		sb.WriteString("*" + highlight.CreatedAt.Format("2006-01-02 15:04") + "*\n\n")
+	}
+	return sb.String()
+}
+
+// MarkdownEscape 转义行内文本中的 Markdown 特殊字符
+func MarkdownEscape(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+		"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+	)
+	return replacer.Replace(NormalizeSpace(s))
+}
@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	markdownBlankLinesRe = regexp.MustCompile(`\n{3,}`)
+	markdownSpaceRe      = regexp.MustCompile(`[ \t\r\n\f]+`)
+	markdownTextEscaper  = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+)
+
+// HTMLToMarkdown 将归档的 HTML 转换为 Markdown，不支持的标签只保留文本，脚本与样式等直接删除
+func HTMLToMarkdown(raw string) string {
+	doc, err := html.Parse(strings.NewReader(raw))
+	if err != nil {
+		return ""
+	}
+	md := markdownChildren(doc)
+
+	// 清理只有空白的行与多余的空行
+	lines := strings.Split(md, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		}
+	}
+	md = markdownBlankLinesRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(md) + "\n"
+}
+
+// markdownChildren 转换全部子节点
+func markdownChildren(n *html.Node) string {
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(markdownNode(child))
+	}
+	return sb.String()
+}
+
+// markdownNode 转换一个节点
+func markdownNode(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return markdownTextEscaper.Replace(markdownSpaceRe.ReplaceAllString(n.Data, " "))
+	case html.ElementNode:
+	case html.DocumentNode:
+		return markdownChildren(n)
+	default:
+		return ""
+	}
+
+	switch n.Data {
+	case "script", "style", "noscript", "iframe", "object", "embed", "svg", "math", "head", "title",
+		"template", "form", "button", "select", "textarea":
+		return ""
+
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := markdownInlineText(markdownChildren(n))
+		if text == "" {
+			return ""
+		}
+		level, _ := strconv.Atoi(n.Data[1:])
+		return "\n\n" + strings.Repeat("#", level) + " " + text + "\n\n"
+
+	case "p", "div", "section", "article", "header", "footer", "main", "aside", "nav", "figure",
+		"figcaption", "details", "summary", "dl", "dd", "dt", "caption":
+		return "\n\n" + strings.TrimSpace(markdownChildren(n)) + "\n\n"
+
+	case "br":
+		return "  \n"
+
+	case "hr":
+		return "\n\n---\n\n"
+
+	case "strong", "b":
+		return markdownWrap(markdownChildren(n), "**")
+
+	case "em", "i", "cite":
+		return markdownWrap(markdownChildren(n), "*")
+
+	case "del", "s", "strike":
+		return markdownWrap(markdownChildren(n), "~~")
+
+	case "code", "kbd", "samp":
+		text := markdownSpaceRe.ReplaceAllString(markdownRawText(n), " ")
+		if strings.TrimSpace(text) == "" {
+			return ""
+		}
+		fence := "`"
+		for strings.Contains(text, fence) {
+			fence += "`"
+		}
+		if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+			text = " " + text + " "
+		}
+		return fence + text + fence
+
+	case "pre":
+		code := strings.Trim(markdownRawText(n), "\n")
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		return "\n\n" + fence + "\n" + code + "\n" + fence + "\n\n"
+
+	case "blockquote":
+		content := strings.TrimSpace(markdownBlankLinesRe.ReplaceAllString(markdownChildren(n), "\n\n"))
+		if content == "" {
+			return ""
+		}
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+
+	case "ul", "ol":
+		return "\n\n" + markdownList(n) + "\n\n"
+
+	case "a":
+		text := markdownInlineText(markdownChildren(n))
+		href := strings.TrimSpace(markdownAttr(n, "href"))
+		if href == "" || strings.HasPrefix(href, "#") || !isSafeURL(href) {
+			return text
+		}
+		if text == "" {
+			return ""
+		}
+		return "[" + text + "](" + markdownURL(href) + ")"
+
+	case "img":
+		src := strings.TrimSpace(markdownAttr(n, "src"))
+		if src == "" || !isSafeURL(src) {
+			return ""
+		}
+		alt := markdownTextEscaper.Replace(NormalizeSpace(markdownAttr(n, "alt")))
+		return "![" + alt + "](" + markdownURL(src) + ")"
+
+	case "table":
+		return "\n\n" + markdownTable(n) + "\n\n"
+	}
+
+	return markdownChildren(n)
+}
+
+// markdownList 转换列表，列表项中的后续行按列表符号的宽度缩进
+func markdownList(n *html.Node) string {
+	ordered := n.Data == "ol"
+	number := 1
+	if start, err := strconv.Atoi(markdownAttr(n, "start")); err == nil && ordered {
+		number = start
+	}
+
+	var items []string
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.Data != "li" {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		content := strings.TrimSpace(markdownBlankLinesRe.ReplaceAllString(markdownChildren(child), "\n\n"))
+		content = strings.ReplaceAll(content, "\n\n", "\n")
+		lines := strings.Split(content, "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = strings.Repeat(" ", len(marker)) + lines[i]
+			}
+		}
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+// markdownTable 转换表格，第一行作为表头，单元格中的换行替换为空格
+func markdownTable(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if child.Data != "tr" {
+				walk(child)
+				continue
+			}
+			var cells []string
+			for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+					text := markdownInlineText(markdownChildren(cell))
+					cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+				}
+			}
+			if len(cells) > 0 {
+				rows = append(rows, cells)
+			}
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	var sb strings.Builder
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// markdownWrap 用强调符号包裹行内文本，空白移到符号外侧
+func markdownWrap(text, mark string) string {
+	trimmed := markdownInlineText(text)
+	if trimmed == "" {
+		return text
+	}
+	prefix := ""
+	if strings.HasPrefix(text, " ") {
+		prefix = " "
+	}
+	suffix := ""
+	if strings.HasSuffix(text, " ") {
+		suffix = " "
+	}
+	return prefix + mark + trimmed + mark + suffix
+}
+
+// markdownInlineText 行内内容：合并为一行并去掉首尾空白
+func markdownInlineText(s string) string {
+	return strings.TrimSpace(markdownSpaceRe.ReplaceAllString(s, " "))
+}
+
+// markdownRawText 节点中的原始文本（代码中保留空白）
+func markdownRawText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "br" {
+			sb.WriteString("\n")
+			continue
+		}
+		sb.WriteString(markdownRawText(child))
+	}
+	return sb.String()
+}
+
+// markdownURL 转义链接地址中的空格与括号
+func markdownURL(u string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(u)
+}
+
+func markdownAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
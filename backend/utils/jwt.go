@@ -7,10 +7,18 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+const (
+	// TokenPurposePreAuth 预认证 token，仅可用于完成两步验证
+	TokenPurposePreAuth = "pre_auth"
+	// TokenPurposeOIDCState 单点登录流程状态，仅保存在 cookie 中
+	TokenPurposeOIDCState = "oidc_state"
+)
+
 // JWTClaims JWT 声明
 type JWTClaims struct {
 	UserID   int    `json:"user_id"`
 	Username string `json:"username"`
+	Purpose  string `json:"purpose,omitempty"` // 为空表示正常访问 token
 	jwt.RegisteredClaims
 }
 
@@ -29,6 +37,22 @@ func GenerateToken(userID int, username string, secret string, expDuration time.
 	return token.SignedString([]byte(secret))
 }
 
+// GeneratePreAuthToken 生成预认证 token（密码校验通过、待完成两步验证）
+func GeneratePreAuthToken(userID int, username string, secret string, expDuration time.Duration) (string, error) {
+	claims := JWTClaims{
+		UserID:   userID,
+		Username: username,
+		Purpose:  TokenPurposePreAuth,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expDuration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
 // ParseToken 解析 JWT token
 func ParseToken(tokenString string, secret string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CheckLink 检查链接是否可以访问，返回最终的 HTTP 状态码（跟随重定向）
+// 先发送 HEAD 请求，服务器不支持 HEAD 时改用 GET
+func CheckLink(linkURL string, timeout time.Duration) (int, error) {
+	client := &http.Client{Timeout: timeout}
+
+	status, err := requestStatus(client, http.MethodHead, linkURL)
+	if err == nil && status != http.StatusMethodNotAllowed && status != http.StatusForbidden && status != http.StatusNotImplemented {
+		return status, nil
+	}
+	return requestStatus(client, http.MethodGet, linkURL)
+}
+
+func requestStatus(client *http.Client, method, linkURL string) (int, error) {
+	req, err := http.NewRequest(method, linkURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 读取少量响应内容后关闭，便于复用连接
+	io.CopyN(io.Discard, resp.Body, 4096)
+	return resp.StatusCode, nil
+}
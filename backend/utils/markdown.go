@@ -0,0 +1,392 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RenderMarkdown 将 Markdown 渲染为过滤后的 HTML。支持常用语法：标题、段落、强调、删除线、行内代码、
+// 代码块、引用、有序与无序列表（可嵌套）、分隔线、链接、图片与自动链接；Markdown 中的原始 HTML 按文本显示
+func RenderMarkdown(src string) string {
+	src = strings.ReplaceAll(strings.ReplaceAll(src, "\r\n", "\n"), "\t", "    ")
+	var sb strings.Builder
+	renderMarkdownBlocks(&sb, strings.Split(src, "\n"), false)
+	return SanitizeHTML(sb.String())
+}
+
+var (
+	mdHeadingRe   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdRuleRe      = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdFenceRe     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	mdQuoteRe     = regexp.MustCompile(`^ {0,3}> ?`)
+	mdListItemRe  = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])( +|$)`)
+	mdAutoLinkRe  = regexp.MustCompile(`^https?://[^\s<>"]*[^\s<>".,;:!?)\]'*_~]`)
+	mdLinkTailRe  = regexp.MustCompile(`^\(\s*<?([^\s<>()]*)>?(?:\s+"((?:[^"\\]|\\.)*)")?\s*\)`)
+	mdEscapeRe    = regexp.MustCompile(`\\([\\"'()])`)
+	mdAngleLinkRe = regexp.MustCompile(`^<(https?://[^\s<>]+|mailto:[^\s<>]+)>`)
+)
+
+// renderMarkdownBlocks 渲染块级元素，tight 为 true 时（紧凑列表项）段落不输出 <p>
+func renderMarkdownBlocks(sb *strings.Builder, lines []string, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case mdFenceRe.MatchString(line):
+			fence := mdFenceRe.FindStringSubmatch(line)[1]
+			i++
+			var code []string
+			for ; i < len(lines); i++ {
+				if t := strings.TrimSpace(lines[i]); strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" {
+					i++
+					break
+				}
+				code = append(code, lines[i])
+			}
+			sb.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")))
+			if len(code) > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("</code></pre>\n")
+
+		case mdHeadingRe.MatchString(line):
+			m := mdHeadingRe.FindStringSubmatch(line)
+			level := strconv.Itoa(len(m[1]))
+			sb.WriteString("<h" + level + ">" + renderMarkdownInline(m[2]) + "</h" + level + ">\n")
+			i++
+
+		case mdRuleRe.MatchString(line):
+			sb.WriteString("<hr>\n")
+			i++
+
+		case mdQuoteRe.MatchString(line):
+			var quote []string
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				if loc := mdQuoteRe.FindStringIndex(lines[i]); loc != nil {
+					quote = append(quote, lines[i][loc[1]:])
+				} else {
+					quote = append(quote, lines[i]) // 段落的延续行
+				}
+			}
+			sb.WriteString("<blockquote>\n")
+			renderMarkdownBlocks(sb, quote, false)
+			sb.WriteString("</blockquote>\n")
+
+		case mdListItemRe.MatchString(line):
+			i = renderMarkdownList(sb, lines, i)
+
+		case strings.HasPrefix(line, "    "):
+			var code []string
+			for ; i < len(lines) && (strings.HasPrefix(lines[i], "    ") || strings.TrimSpace(lines[i]) == ""); i++ {
+				code = append(code, strings.TrimPrefix(lines[i], "    "))
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			sb.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "\n</code></pre>\n")
+
+		default:
+			var para []string
+			for ; i < len(lines); i++ {
+				l := lines[i]
+				if strings.TrimSpace(l) == "" || (len(para) > 0 && markdownInterruptsParagraph(l)) {
+					break
+				}
+				para = append(para, l)
+			}
+			text := renderMarkdownParagraph(para)
+			if tight {
+				sb.WriteString(text + "\n")
+			} else {
+				sb.WriteString("<p>" + text + "</p>\n")
+			}
+		}
+	}
+}
+
+// markdownInterruptsParagraph 判断一行是否结束当前段落并开始新的块
+func markdownInterruptsParagraph(line string) bool {
+	return mdFenceRe.MatchString(line) || mdHeadingRe.MatchString(line) || mdRuleRe.MatchString(line) ||
+		mdQuoteRe.MatchString(line) || mdListItemRe.MatchString(line)
+}
+
+// renderMarkdownParagraph 渲染段落，行尾两个以上空格或反斜杠表示换行
+func renderMarkdownParagraph(lines []string) string {
+	parts := make([]string, 0, len(lines))
+	for i, line := range lines {
+		line = strings.TrimLeft(line, " ")
+		hardBreak := i < len(lines)-1 && (strings.HasSuffix(line, "  ") || strings.HasSuffix(line, `\`))
+		line = strings.TrimRight(line, " ")
+		if hardBreak {
+			line = strings.TrimSuffix(line, `\`)
+		}
+		rendered := renderMarkdownInline(line)
+		if hardBreak {
+			rendered += "<br>"
+		}
+		parts = append(parts, rendered)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// renderMarkdownList 渲染从第 start 行开始的列表，返回列表结束后的行号
+func renderMarkdownList(sb *strings.Builder, lines []string, start int) int {
+	first := mdListItemRe.FindStringSubmatch(lines[start])
+	ordered := first[2][0] >= '0' && first[2][0] <= '9'
+	marker := first[2][len(first[2])-1:] // 无序列表的符号或有序列表的 . )
+
+	var items [][]string
+	loose := false
+	i := start
+	for i < len(lines) {
+		m := mdListItemRe.FindStringSubmatch(lines[i])
+		if m == nil || (m[2][0] >= '0' && m[2][0] <= '9') != ordered || m[2][len(m[2])-1:] != marker {
+			break
+		}
+		indent := len(m[0])
+		if m[3] == "" || len(m[3]) > 4 {
+			indent = len(m[1]) + len(m[2]) + 1
+		}
+		item := []string{strings.TrimPrefix(lines[i], m[0][:minInt(len(m[0]), indent)])}
+		i++
+
+		// 收集列表项的后续行：缩进的行属于该列表项，空行之后不缩进的行结束列表
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				if i+1 < len(lines) && (leadingSpaces(lines[i+1]) >= indent || mdListItemRe.MatchString(lines[i+1])) {
+					if leadingSpaces(lines[i+1]) >= indent {
+						item = append(item, "")
+					} else {
+						loose = true
+					}
+					i++
+					continue
+				}
+				break
+			}
+			if leadingSpaces(line) >= indent {
+				item = append(item, line[indent:])
+			} else if mdListItemRe.MatchString(line) || markdownInterruptsParagraph(line) {
+				break
+			} else {
+				item = append(item, line) // 段落的延续行
+			}
+			i++
+		}
+		for _, l := range item {
+			if l == "" {
+				loose = true
+			}
+		}
+		items = append(items, item)
+		if i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			break
+		}
+	}
+
+	if ordered {
+		number, _ := strconv.Atoi(first[2][:len(first[2])-1])
+		if number != 1 {
+			sb.WriteString(`<ol start="` + strconv.Itoa(number) + `">` + "\n")
+		} else {
+			sb.WriteString("<ol>\n")
+		}
+	} else {
+		sb.WriteString("<ul>\n")
+	}
+	for _, item := range items {
+		sb.WriteString("<li>")
+		renderMarkdownBlocks(sb, item, !loose)
+		sb.WriteString("</li>\n")
+	}
+	if ordered {
+		sb.WriteString("</ol>\n")
+	} else {
+		sb.WriteString("</ul>\n")
+	}
+	return i
+}
+
+// renderMarkdownInline 渲染行内元素
+func renderMarkdownInline(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		rest := s[i:]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_{}[]()#+-.!~<>|\"'", s[i+1]) >= 0:
+			sb.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			run := len(rest) - len(strings.TrimLeft(rest, "`"))
+			delimiter := rest[:run]
+			if end := strings.Index(rest[run:], delimiter); end >= 0 {
+				code := rest[run : run+end]
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				sb.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += run + end + run
+				continue
+			}
+			sb.WriteString(delimiter)
+			i += run
+			continue
+
+		case c == '!' && strings.HasPrefix(rest, "!["):
+			if text, url, title, n, ok := parseMarkdownLink(rest[1:]); ok {
+				sb.WriteString(`<img src="` + html.EscapeString(url) + `" alt="` + html.EscapeString(markdownPlainText(text)) + `"`)
+				if title != "" {
+					sb.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				sb.WriteString(">")
+				i += 1 + n
+				continue
+			}
+
+		case c == '[':
+			if text, url, title, n, ok := parseMarkdownLink(rest); ok {
+				sb.WriteString(`<a href="` + html.EscapeString(url) + `"`)
+				if title != "" {
+					sb.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				sb.WriteString(">" + renderMarkdownInline(text) + "</a>")
+				i += n
+				continue
+			}
+
+		case c == '<':
+			if m := mdAngleLinkRe.FindStringSubmatch(rest); m != nil {
+				sb.WriteString(`<a href="` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+
+		case c == 'h' && (i == 0 || !isMarkdownWordByte(s[i-1])):
+			if m := mdAutoLinkRe.FindString(rest); m != "" {
+				sb.WriteString(`<a href="` + html.EscapeString(m) + `">` + html.EscapeString(m) + "</a>")
+				i += len(m)
+				continue
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			if tag, inner, n, ok := parseMarkdownEmphasis(s, i); ok {
+				sb.WriteString("<" + tag + ">" + renderMarkdownInline(inner) + "</" + tag + ">")
+				i += n
+				continue
+			}
+		}
+
+		sb.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+	return sb.String()
+}
+
+// parseMarkdownLink 解析 [text](url "title")，标题中可以用反斜杠转义引号，返回消耗的字节数
+func parseMarkdownLink(s string) (text, url, title string, n int, ok bool) {
+	depth := 0
+	for j := 0; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				m := mdLinkTailRe.FindStringSubmatch(s[j+1:])
+				if m == nil {
+					return "", "", "", 0, false
+				}
+				return s[1:j], m[1], mdEscapeRe.ReplaceAllString(m[2], "$1"), j + 1 + len(m[0]), true
+			}
+		}
+	}
+	return "", "", "", 0, false
+}
+
+// parseMarkdownEmphasis 解析 **strong**、*em*、__strong__、_em_ 与 ~~del~~，返回标签、内容与消耗的字节数
+func parseMarkdownEmphasis(s string, i int) (tag, inner string, n int, ok bool) {
+	c := s[i]
+	run := 1
+	if i+1 < len(s) && s[i+1] == c {
+		run = 2
+	}
+	if c == '~' && run != 2 {
+		return "", "", 0, false
+	}
+	// 下划线在单词中间时不作为强调
+	if c == '_' && i > 0 && isMarkdownWordByte(s[i-1]) {
+		return "", "", 0, false
+	}
+	delimiter := s[i : i+run]
+	body := s[i+run:]
+	if body == "" || body[0] == ' ' {
+		return "", "", 0, false
+	}
+
+	for j := 0; j < len(body); j++ {
+		if body[j] == '\\' {
+			j++
+			continue
+		}
+		if body[j] == '`' {
+			// 跳过行内代码中的符号
+			if end := strings.IndexByte(body[j+1:], '`'); end >= 0 {
+				j += end + 1
+			}
+			continue
+		}
+		if !strings.HasPrefix(body[j:], delimiter) || j == 0 || body[j-1] == ' ' {
+			continue
+		}
+		after := j + run
+		if after < len(body) && body[after] == c {
+			continue // 更长的符号串，留给外层
+		}
+		if c == '_' && after < len(body) && isMarkdownWordByte(body[after]) {
+			continue
+		}
+		switch {
+		case c == '~':
+			tag = "del"
+		case run == 2:
+			tag = "strong"
+		default:
+			tag = "em"
+		}
+		return tag, body[:j], run + j + run, true
+	}
+	return "", "", 0, false
+}
+
+// markdownPlainText 去掉行内标记，用于图片的 alt
+func markdownPlainText(s string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "", "~", "").Replace(s)
+}
+
+func isMarkdownWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
+
+func leadingSpaces(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
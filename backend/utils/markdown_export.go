@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MarkdownDocument 导出为 Markdown 文件的书签
+type MarkdownDocument struct {
+	URL        string
+	Title      string
+	Tags       []string
+	Author     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Body       string // 正文（Markdown）
+	Highlights []MarkdownHighlight
+	Notes      []string // 笔记（Markdown）
+}
+
+// RenderMarkdownDocument 渲染书签的 Markdown 文件：YAML front matter、正文，之后附加高亮与笔记，
+// 兼容 Obsidian、Logseq 等笔记软件
+func RenderMarkdownDocument(doc MarkdownDocument) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.WriteString("url: " + yamlString(doc.URL) + "\n")
+	sb.WriteString("title: " + yamlString(doc.Title) + "\n")
+	if len(doc.Tags) > 0 {
+		sb.WriteString("tags:\n")
+		for _, tag := range doc.Tags {
+			sb.WriteString("  - " + yamlString(tag) + "\n")
+		}
+	} else {
+		sb.WriteString("tags: []\n")
+	}
+	if doc.Author != "" {
+		sb.WriteString("author: " + yamlString(doc.Author) + "\n")
+	}
+	sb.WriteString("created: " + doc.CreatedAt.Format(time.RFC3339) + "\n")
+	sb.WriteString("updated: " + doc.UpdatedAt.Format(time.RFC3339) + "\n")
+	sb.WriteString("---\n\n")
+
+	sb.WriteString("# " + MarkdownEscape(doc.Title) + "\n\n")
+	if body := strings.TrimSpace(doc.Body); body != "" {
+		sb.WriteString(body + "\n\n")
+	}
+	if len(doc.Highlights) > 0 {
+		sb.WriteString("## Highlights\n\n")
+		sb.WriteString(RenderHighlightQuotes(doc.Highlights))
+	}
+	if len(doc.Notes) > 0 {
+		sb.WriteString("## Notes\n\n")
+		for _, note := range doc.Notes {
+			sb.WriteString(strings.TrimSpace(note) + "\n\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// yamlString 输出 YAML 双引号字符串（JSON 字符串是合法的 YAML 双引号字符串）
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// SafeFileName 将标题转换为可以在各操作系统中使用的文件名（不含扩展名），最长 maxRunes 个字符
+func SafeFileName(name string, maxRunes int) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|#^[]`, r) {
+			return ' '
+		}
+		return r
+	}, name)
+	name = strings.Trim(NormalizeSpace(name), ". ")
+	if runes := []rune(name); len(runes) > maxRunes {
+		name = strings.TrimSpace(string(runes[:maxRunes]))
+	}
+	return name
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// testLinkAttrs 过滤后的链接统一附加的属性
+const testLinkAttrs = ` rel="noopener noreferrer nofollow" target="_blank"`
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"标题", "# 标题 #", "<h1>标题</h1>\n"},
+		{"六级标题", "###### h6", "<h6>h6</h6>\n"},
+		{"井号后没有空格不是标题", "#no", "<p>#no</p>\n"},
+		{"段落", "段落一\n第二行\n\n段落二", "<p>段落一\n第二行</p>\n<p>段落二</p>\n"},
+		{"行尾两个空格换行", "a  \nb", "<p>a<br>\nb</p>\n"},
+		{"行尾反斜杠换行", "a\\\nb", "<p>a<br>\nb</p>\n"},
+		{"CRLF 换行", "a\r\nb", "<p>a\nb</p>\n"},
+		{"强调", "**粗** *斜* __粗__ _斜_ ~~删~~", "<p><strong>粗</strong> <em>斜</em> <strong>粗</strong> <em>斜</em> <del>删</del></p>\n"},
+		{"嵌套强调", "**粗 *斜* 粗**", "<p><strong>粗 <em>斜</em> 粗</strong></p>\n"},
+		{"单词中间的下划线", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"反斜杠转义", `\*不是强调\*`, "<p>*不是强调*</p>\n"},
+		{"行内代码", "`a < b`", "<p><code>a &lt; b</code></p>\n"},
+		{"行内代码包含反引号", "`` a`b ``", "<p><code>a`b</code></p>\n"},
+		{"行内代码中的强调符号", "`*a*`", "<p><code>*a*</code></p>\n"},
+		{"代码块", "```go\nfmt.Println(\"<b>\")\n```", "<pre><code>fmt.Println(&#34;&lt;b&gt;&#34;)\n</code></pre>\n"},
+		{"波浪线代码块", "~~~\n**a**\n~~~", "<pre><code>**a**\n</code></pre>\n"},
+		{"未闭合的代码块", "```\nunclosed", "<pre><code>unclosed\n</code></pre>\n"},
+		{"缩进代码块", "    code\n    more\n\ntext", "<pre><code>code\nmore\n</code></pre>\n<p>text</p>\n"},
+		{"引用", "> 引用\n继续", "<blockquote>\n<p>引用\n继续</p>\n</blockquote>\n"},
+		{"引用中的多个段落", "> a\n>\n> b", "<blockquote>\n<p>a</p>\n<p>b</p>\n</blockquote>\n"},
+		{"无序列表", "- a\n- b", "<ul>\n<li>a\n</li>\n<li>b\n</li>\n</ul>\n"},
+		{"有序列表", "1. a\n2. b", "<ol>\n<li>a\n</li>\n<li>b\n</li>\n</ol>\n"},
+		{"有序列表的起始序号", "3) a\n4) b", "<ol start=\"3\">\n<li>a\n</li>\n<li>b\n</li>\n</ol>\n"},
+		{"嵌套列表", "- a\n  - b\n- c", "<ul>\n<li>a\n<ul>\n<li>b\n</li>\n</ul>\n</li>\n<li>c\n</li>\n</ul>\n"},
+		{"松散列表", "- a\n\n- b", "<ul>\n<li><p>a</p>\n</li>\n<li><p>b</p>\n</li>\n</ul>\n"},
+		{"列表项的延续行", "- a\nb", "<ul>\n<li>a\nb\n</li>\n</ul>\n"},
+		{"不同类型的列表", "1. a\n- b", "<ol>\n<li>a\n</li>\n</ol>\n<ul>\n<li>b\n</li>\n</ul>\n"},
+		{"分隔线", "a\n\n---\n\n* * *", "<p>a</p>\n<hr>\n<hr>\n"},
+		{"标题打断段落", "text\n# h", "<p>text</p>\n<h1>h</h1>\n"},
+		{"链接", `[链接](https://example.com "标题")`, `<p><a href="https://example.com" title="标题"` + testLinkAttrs + ">链接</a></p>\n"},
+		{"链接标题中的转义引号", `[x](https://a "说 \"你好\"")`, `<p><a href="https://a" title="说 &#34;你好&#34;"` + testLinkAttrs + ">x</a></p>\n"},
+		{"链接文字中的强调", "[**粗**](/a)", `<p><a href="/a"` + testLinkAttrs + "><strong>粗</strong></a></p>\n"},
+		{"图片", `![图](/a.png "t")`, "<p><img src=\"/a.png\" alt=\"图\" title=\"t\"></p>\n"},
+		{"图片的 alt 去掉行内标记", "![**图**](/a.png)", "<p><img src=\"/a.png\" alt=\"图\"></p>\n"},
+		{"尖括号自动链接", "<https://example.com>", `<p><a href="https://example.com"` + testLinkAttrs + ">https://example.com</a></p>\n"},
+		{"邮件自动链接", "<mailto:a@example.com>", `<p><a href="mailto:a@example.com"` + testLinkAttrs + ">mailto:a@example.com</a></p>\n"},
+		{"裸地址自动链接不包含句末标点", "见 https://example.com/a.", `<p>见 <a href="https://example.com/a"` + testLinkAttrs + ">https://example.com/a</a>.</p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkdown(tt.src); got != tt.want {
+				t.Errorf("RenderMarkdown(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownXSS(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string   // 期望输出中包含的内容
+		notWant []string // 输出中不能出现的内容
+	}{
+		{
+			name:    "原始 script 标签按文本显示",
+			src:     "<script>alert(1)</script>",
+			want:    "&lt;script&gt;alert(1)&lt;/script&gt;",
+			notWant: []string{"<script"},
+		},
+		{
+			name:    "原始 img 标签的事件属性按文本显示",
+			src:     "<img src=x onerror=alert(1)>",
+			want:    "&lt;img src=x onerror=alert(1)&gt;",
+			notWant: []string{"<img"},
+		},
+		{
+			name:    "代码块中的 HTML",
+			src:     "```\n</code></pre><script>alert(1)</script>\n```",
+			want:    "&lt;/code&gt;&lt;/pre&gt;&lt;script&gt;",
+			notWant: []string{"<script"},
+		},
+		{
+			name:    "javascript: 链接",
+			src:     "[x](javascript:alert%281%29)",
+			want:    "<a" + testLinkAttrs + ">x</a>",
+			notWant: []string{"javascript:"},
+		},
+		{
+			name:    "大写的 JAVASCRIPT: 链接",
+			src:     "[x](JAVASCRIPT:alert%281%29)",
+			notWant: []string{"href", "JAVASCRIPT:"},
+		},
+		{
+			name:    "实体编码的 javascript: 链接只是相对地址",
+			src:     "[x](&#106;avascript:alert%281%29)",
+			want:    `href="&amp;#106;avascript:alert%281%29"`,
+			notWant: []string{`href="javascript:`, `href="&#106;`},
+		},
+		{
+			name:    "data: 链接",
+			src:     "[x](data:text/html;base64,PHNjcmlwdD4=)",
+			notWant: []string{"href", "data:"},
+		},
+		{
+			name:    "javascript: 图片",
+			src:     "![x](javascript:alert%281%29)",
+			want:    `<img alt="x">`,
+			notWant: []string{"src", "javascript:"},
+		},
+		{
+			name:    "尖括号中的 javascript: 不是自动链接",
+			src:     "<javascript:alert(1)>",
+			want:    "&lt;javascript:alert(1)&gt;",
+			notWant: []string{"<a"},
+		},
+		{
+			name:    "链接标题通过转义引号注入属性",
+			src:     `[x](https://a "t\" onmouseover=\"alert(1)")`,
+			want:    `title="t&#34; onmouseover=&#34;alert(1)"`,
+			notWant: []string{` onmouseover="`},
+		},
+		{
+			name:    "链接标题通过单引号注入属性",
+			src:     `[x](https://a "t' onmouseover='alert(1)")`,
+			want:    `title="t&#39; onmouseover=&#39;alert(1)"`,
+			notWant: []string{" onmouseover='"},
+		},
+		{
+			name:    "链接标题中的标签",
+			src:     `[x](https://a "\"><script>alert(1)</script>")`,
+			want:    `title="&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;"`,
+			notWant: []string{"<script"},
+		},
+		{
+			name:    "链接文字中的标签",
+			src:     "[<b onclick=alert(1)>x</b>](https://a)",
+			want:    "&lt;b onclick=alert(1)&gt;x&lt;/b&gt;",
+			notWant: []string{"<b "},
+		},
+		{
+			name:    "图片 alt 中的引号",
+			src:     `![a" onerror="alert(1)](https://a)`,
+			want:    `alt="a&#34; onerror=&#34;alert(1)"`,
+			notWant: []string{` onerror="`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderMarkdown(tt.src)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("RenderMarkdown(%q) = %q, want it to contain %q", tt.src, got, tt.want)
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("RenderMarkdown(%q) = %q, must not contain %q", tt.src, got, s)
+				}
+			}
+		})
+	}
+}
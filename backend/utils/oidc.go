@@ -0,0 +1,395 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// OIDCProvider OpenID Connect 身份提供方（通过 discovery 文档初始化）
+type OIDCProvider struct {
+	Issuer      string `json:"issuer"`
+	AuthURL     string `json:"authorization_endpoint"`
+	TokenURL    string `json:"token_endpoint"`
+	UserInfoURL string `json:"userinfo_endpoint"`
+	JWKSURL     string `json:"jwks_uri"`
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+}
+
+// OIDCToken 授权码换取的 token
+type OIDCToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// OIDCState 登录流程中保存在 cookie 里的状态，使用 JWT 密钥签名，支持多实例部署
+type OIDCState struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	LinkUserID   int    `json:"link_user_id,omitempty"` // 非 0 表示将身份绑定到该本地用户
+	Purpose      string `json:"purpose"`                // 固定为 oidc_state，防止被当作访问 token 使用
+	jwt.RegisteredClaims
+}
+
+// DiscoverOIDC 读取 {issuer}/.well-known/openid-configuration
+func DiscoverOIDC(issuer string) (*OIDCProvider, error) {
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	resp, err := httpClient.Get(wellKnown)
+	if err != nil {
+		return nil, fmt.Errorf("获取 OIDC 配置失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取 OIDC 配置失败, HTTP 状态码: %d", resp.StatusCode)
+	}
+
+	provider := &OIDCProvider{}
+	if err := json.NewDecoder(resp.Body).Decode(provider); err != nil {
+		return nil, fmt.Errorf("解析 OIDC 配置失败: %w", err)
+	}
+
+	// issuer 必须与配置一致，防止被替换为其他身份提供方
+	if strings.TrimSuffix(provider.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("OIDC issuer 不匹配: %s", provider.Issuer)
+	}
+	if provider.AuthURL == "" || provider.TokenURL == "" || provider.JWKSURL == "" {
+		return nil, errors.New("OIDC 配置缺少必要的端点")
+	}
+
+	return provider, nil
+}
+
+// RandomString 生成 URL 安全的随机字符串
+func RandomString(byteLen int) (string, error) {
+	buf := make([]byte, byteLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// PKCEChallenge 计算 PKCE S256 code_challenge
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL 生成授权码模式（PKCE）的登录地址
+func (p *OIDCProvider) AuthCodeURL(clientID, redirectURL string, scopes []string, state, nonce, codeVerifier string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", redirectURL)
+	params.Set("scope", strings.Join(scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", PKCEChallenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.AuthURL + sep + params.Encode()
+}
+
+// Exchange 使用授权码换取 token
+func (p *OIDCProvider) Exchange(clientID, clientSecret, redirectURL, code, codeVerifier string) (*OIDCToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("client_id", clientID)
+	form.Set("code_verifier", codeVerifier)
+	if clientSecret != "" {
+		form.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequest("POST", p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("换取 token 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("读取 token 响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("换取 token 失败, HTTP 状态码: %d, %s", resp.StatusCode, string(body))
+	}
+
+	token := &OIDCToken{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("解析 token 响应失败: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token 响应缺少 id_token")
+	}
+	return token, nil
+}
+
+// VerifyIDToken 校验 id_token 的签名、issuer、audience、有效期和 nonce，返回全部 claims
+func (p *OIDCProvider) VerifyIDToken(rawIDToken, clientID, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodRSAPSS:
+		default:
+			return nil, fmt.Errorf("不支持的签名算法: %s", token.Method.Alg())
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("id_token 校验失败: %w", err)
+	}
+
+	if !claims.VerifyIssuer(p.Issuer, true) {
+		return nil, errors.New("id_token issuer 不匹配")
+	}
+	if !claims.VerifyAudience(clientID, true) {
+		return nil, errors.New("id_token audience 不匹配")
+	}
+	// 解析时只校验已有的 exp，id_token 必须带有过期时间
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("id_token 缺少过期时间或已过期")
+	}
+	if claimNonce, _ := claims["nonce"].(string); claimNonce != nonce {
+		return nil, errors.New("id_token nonce 不匹配")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("id_token 缺少 sub")
+	}
+
+	return claims, nil
+}
+
+// UserInfo 通过 userinfo 端点获取补充的用户信息
+func (p *OIDCProvider) UserInfo(accessToken string) (map[string]interface{}, error) {
+	if p.UserInfoURL == "" || accessToken == "" {
+		return nil, nil
+	}
+
+	req, err := http.NewRequest("GET", p.UserInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户信息失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取用户信息失败, HTTP 状态码: %d", resp.StatusCode)
+	}
+
+	info := map[string]interface{}{}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("解析用户信息失败: %w", err)
+	}
+	return info, nil
+}
+
+// publicKey 按 kid 获取签名公钥，找不到时重新拉取 JWKS（身份提供方可能已轮换密钥）
+func (p *OIDCProvider) publicKey(kid string) (crypto.PublicKey, error) {
+	p.mu.RLock()
+	key, ok := p.lookupKey(kid)
+	p.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	keys, err := fetchJWKS(p.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	key, ok = p.lookupKey(kid)
+	p.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("未找到签名公钥: %s", kid)
+	}
+	return key, nil
+}
+
+// lookupKey 查找公钥，kid 为空且只有一个公钥时直接使用该公钥
+func (p *OIDCProvider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// jsonWebKey JWKS 中的单个公钥
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchJWKS 下载并解析 JWKS，支持 RSA 与 EC 公钥
+func fetchJWKS(jwksURL string) (map[string]crypto.PublicKey, error) {
+	resp, err := httpClient.Get(jwksURL)
+	if err != nil {
+		return nil, fmt.Errorf("获取 JWKS 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取 JWKS 失败, HTTP 状态码: %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("解析 JWKS 失败: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				continue
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, err := base64.RawURLEncoding.DecodeString(k.X)
+			if err != nil {
+				continue
+			}
+			y, err := base64.RawURLEncoding.DecodeString(k.Y)
+			if err != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{
+				Curve: curve,
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS 中没有可用的签名公钥")
+	}
+	return keys, nil
+}
+
+// GenerateOIDCStateToken 签名登录状态，写入 cookie
+func GenerateOIDCStateToken(state OIDCState, secret string, expDuration time.Duration) (string, error) {
+	state.Purpose = TokenPurposeOIDCState
+	state.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expDuration)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, state)
+	return token.SignedString([]byte(secret))
+}
+
+// ParseOIDCStateToken 解析 cookie 中的登录状态
+func ParseOIDCStateToken(tokenString string, secret string) (*OIDCState, error) {
+	state := &OIDCState{}
+	token, err := jwt.ParseWithClaims(tokenString, state, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("不支持的签名算法: %s", token.Method.Alg())
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || state.Purpose != TokenPurposeOIDCState {
+		return nil, errors.New("invalid state")
+	}
+	return state, nil
+}
+
+// ClaimStrings 读取字符串或字符串数组类型的 claim
+func ClaimStrings(claims map[string]interface{}, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
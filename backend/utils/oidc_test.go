@@ -0,0 +1,359 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testClientID    = "bk_kms"
+	testRedirectURL = "http://localhost:8081/api/v1/auth/oidc/callback"
+	testKeyID       = "test-key"
+)
+
+// testIdP 测试用的身份提供方：discovery、JWKS、授权码换取 token（校验 PKCE）
+type testIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu         sync.Mutex
+	challenges map[string]string // 授权码 -> code_challenge
+	nonces     map[string]string // 授权码 -> nonce
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &testIdP{
+		key:        key,
+		challenges: make(map[string]string),
+		nonces:     make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": testKeyID,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		code := r.PostForm.Get("code")
+		idp.mu.Lock()
+		challenge, ok := idp.challenges[code]
+		nonce := idp.nonces[code]
+		delete(idp.challenges, code) // 授权码只能使用一次
+		idp.mu.Unlock()
+
+		if !ok || r.PostForm.Get("grant_type") != "authorization_code" ||
+			r.PostForm.Get("client_id") != testClientID || r.PostForm.Get("redirect_uri") != testRedirectURL {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		if PKCEChallenge(r.PostForm.Get("code_verifier")) != challenge {
+			http.Error(w, `{"error":"invalid_grant","error_description":"PKCE verification failed"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"id_token":     idp.sign(t, key, idp.claims(nonce)),
+			"expires_in":   3600,
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize 模拟用户在身份提供方登录并同意授权，返回授权码
+func (idp *testIdP) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("authorization URL without PKCE: %s", authURL)
+	}
+	code := "code-" + query.Get("state")
+	idp.mu.Lock()
+	idp.challenges[code] = query.Get("code_challenge")
+	idp.nonces[code] = query.Get("nonce")
+	idp.mu.Unlock()
+	return code
+}
+
+// claims 有效的 id_token claims
+func (idp *testIdP) claims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                idp.server.URL,
+		"sub":                "user-1",
+		"aud":                testClientID,
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              nonce,
+		"preferred_username": "alice",
+	}
+}
+
+// sign 使用 RS256 签名 id_token
+func (idp *testIdP) sign(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestDiscoverOIDC(t *testing.T) {
+	idp := newTestIdP(t)
+
+	provider, err := DiscoverOIDC(idp.server.URL + "/")
+	if err != nil {
+		t.Fatalf("DiscoverOIDC() error = %v", err)
+	}
+	if provider.TokenURL != idp.server.URL+"/token" || provider.JWKSURL != idp.server.URL+"/jwks" {
+		t.Errorf("provider = %+v", provider)
+	}
+
+	// discovery 文档中的 issuer 与配置不一致
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	}))
+	defer other.Close()
+	if _, err := DiscoverOIDC(other.URL); err == nil || !strings.Contains(err.Error(), "issuer 不匹配") {
+		t.Errorf("DiscoverOIDC() with mismatched issuer error = %v", err)
+	}
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	idp := newTestIdP(t)
+	provider, err := DiscoverOIDC(idp.server.URL)
+	if err != nil {
+		t.Fatalf("DiscoverOIDC() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		verifier func(verifier string) string // 换取 token 时提交的 code_verifier
+		nonce    func(nonce string) string    // 校验 id_token 时期望的 nonce
+		wantErr  string
+	}{
+		{
+			name:     "成功",
+			verifier: func(v string) string { return v },
+			nonce:    func(n string) string { return n },
+		},
+		{
+			name:     "code_verifier 错误",
+			verifier: func(v string) string { return v + "x" },
+			nonce:    func(n string) string { return n },
+			wantErr:  "PKCE verification failed",
+		},
+		{
+			name:     "nonce 不匹配",
+			verifier: func(v string) string { return v },
+			nonce:    func(n string) string { return "other-nonce" },
+			wantErr:  "nonce 不匹配",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, nonce, verifier := fmt.Sprintf("state-%d", i), "nonce-value", "verifier-value-0123456789-0123456789-0123"
+			authURL := provider.AuthCodeURL(testClientID, testRedirectURL, []string{"openid"}, state, nonce, verifier)
+			code := idp.authorize(t, authURL)
+
+			token, err := provider.Exchange(testClientID, "", testRedirectURL, code, tt.verifier(verifier))
+			if err == nil {
+				_, err = provider.VerifyIDToken(token.IDToken, testClientID, tt.nonce(nonce))
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+
+	t.Run("授权码不能重复使用", func(t *testing.T) {
+		authURL := provider.AuthCodeURL(testClientID, testRedirectURL, []string{"openid"}, "reuse", "n", "verifier-value-0123456789-0123456789-0123")
+		code := idp.authorize(t, authURL)
+		if _, err := provider.Exchange(testClientID, "", testRedirectURL, code, "verifier-value-0123456789-0123456789-0123"); err != nil {
+			t.Fatalf("first Exchange() error = %v", err)
+		}
+		if _, err := provider.Exchange(testClientID, "", testRedirectURL, code, "verifier-value-0123456789-0123456789-0123"); err == nil {
+			t.Error("second Exchange() error = nil, want error")
+		}
+	})
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := newTestIdP(t)
+	provider, err := DiscoverOIDC(idp.server.URL)
+	if err != nil {
+		t.Fatalf("DiscoverOIDC() error = %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr string
+	}{
+		{
+			name:  "有效",
+			token: func() string { return idp.sign(t, idp.key, idp.claims("n")) },
+		},
+		{
+			name:    "签名错误（其他私钥，相同 kid）",
+			token:   func() string { return idp.sign(t, otherKey, idp.claims("n")) },
+			wantErr: "id_token 校验失败",
+		},
+		{
+			name: "篡改 payload",
+			token: func() string {
+				parts := strings.Split(idp.sign(t, idp.key, idp.claims("n")), ".")
+				claims := idp.claims("n")
+				claims["sub"] = "admin"
+				payload, _ := json.Marshal(claims)
+				parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+				return strings.Join(parts, ".")
+			},
+			wantErr: "id_token 校验失败",
+		},
+		{
+			name: "HS256 签名（使用公钥作为密钥的算法混淆）",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.claims("n"))
+				token.Header["kid"] = testKeyID
+				signed, _ := token.SignedString(idp.key.N.Bytes())
+				return signed
+			},
+			wantErr: "不支持的签名算法",
+		},
+		{
+			name: "未知的 kid",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.claims("n"))
+				token.Header["kid"] = "unknown"
+				signed, _ := token.SignedString(idp.key)
+				return signed
+			},
+			wantErr: "未找到签名公钥",
+		},
+		{
+			name: "已过期",
+			token: func() string {
+				claims := idp.claims("n")
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+				return idp.sign(t, idp.key, claims)
+			},
+			wantErr: "id_token 校验失败",
+		},
+		{
+			name: "缺少 exp",
+			token: func() string {
+				claims := idp.claims("n")
+				delete(claims, "exp")
+				return idp.sign(t, idp.key, claims)
+			},
+			wantErr: "缺少过期时间",
+		},
+		{
+			name: "issuer 不匹配",
+			token: func() string {
+				claims := idp.claims("n")
+				claims["iss"] = "https://evil.example.com"
+				return idp.sign(t, idp.key, claims)
+			},
+			wantErr: "issuer 不匹配",
+		},
+		{
+			name: "audience 不匹配",
+			token: func() string {
+				claims := idp.claims("n")
+				claims["aud"] = "other-client"
+				return idp.sign(t, idp.key, claims)
+			},
+			wantErr: "audience 不匹配",
+		},
+		{
+			name:    "nonce 不匹配",
+			token:   func() string { return idp.sign(t, idp.key, idp.claims("other")) },
+			wantErr: "nonce 不匹配",
+		},
+		{
+			name: "缺少 sub",
+			token: func() string {
+				claims := idp.claims("n")
+				delete(claims, "sub")
+				return idp.sign(t, idp.key, claims)
+			},
+			wantErr: "缺少 sub",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := provider.VerifyIDToken(tt.token(), testClientID, "n")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("VerifyIDToken() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error = %v", err)
+			}
+			if claims["preferred_username"] != "alice" {
+				t.Errorf("claims = %v", claims)
+			}
+		})
+	}
+}
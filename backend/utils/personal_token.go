@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// PersonalTokenPrefix 个人访问令牌的前缀，用于与 JWT 区分
+const PersonalTokenPrefix = "bkms_"
+
+// GeneratePersonalToken 生成个人访问令牌，格式 bkms_<随机串>
+func GeneratePersonalToken() (string, error) {
+	random, err := RandomString(32)
+	if err != nil {
+		return "", err
+	}
+	return PersonalTokenPrefix + random, nil
+}
+
+// IsPersonalToken 是否为个人访问令牌
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
+
+// HashPersonalToken 计算个人访问令牌的哈希值（令牌为高熵随机串，无需加盐）
+func HashPersonalToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
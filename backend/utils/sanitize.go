@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// sanitizeAllowedTags 允许保留的标签及其属性
+var sanitizeAllowedTags = map[string][]string{
+	"a": {"href", "title"}, "abbr": {"title"}, "b": nil, "blockquote": {"cite"}, "br": nil,
+	"caption": nil, "cite": nil, "code": nil, "col": {"span"}, "colgroup": {"span"}, "dd": nil,
+	"del": nil, "details": nil, "dfn": nil, "div": nil, "dl": nil, "dt": nil, "em": nil,
+	"figcaption": nil, "figure": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil,
+	"h6": nil, "hr": nil, "i": nil, "img": {"src", "alt", "title", "width", "height"}, "ins": nil,
+	"kbd": nil, "li": nil, "mark": nil, "ol": {"start"}, "p": nil, "pre": nil, "q": {"cite"},
+	"s": nil, "samp": nil, "small": nil, "span": nil, "strong": nil, "sub": nil, "summary": nil,
+	"sup": nil, "table": nil, "tbody": nil, "td": {"colspan", "rowspan"}, "tfoot": nil,
+	"th": {"colspan", "rowspan", "scope"}, "thead": nil, "time": {"datetime"}, "tr": nil,
+	"u": nil, "ul": nil, "var": nil,
+}
+
+// sanitizeDroppedTags 连同内容一起删除的标签
+var sanitizeDroppedTags = map[string]struct{}{
+	"script": {}, "style": {}, "iframe": {}, "object": {}, "embed": {}, "noscript": {},
+	"template": {}, "svg": {}, "math": {}, "form": {}, "textarea": {}, "select": {}, "title": {},
+}
+
+// sanitizeRawTextTags 内容按原始文本解析的删除标签，自闭合写法（如 <script/>）同样视为开始标签
+var sanitizeRawTextTags = map[string]struct{}{
+	"script": {}, "style": {}, "iframe": {}, "noscript": {}, "textarea": {}, "title": {},
+}
+
+// sanitizeVoidTags 没有结束标签的元素
+var sanitizeVoidTags = map[string]struct{}{
+	"br": {}, "col": {}, "hr": {}, "img": {},
+}
+
+// SanitizeHTML 按白名单过滤 HTML：只保留排版相关的标签与属性，删除脚本、样式、事件属性，
+// 链接只允许 http、https、mailto 与相对地址，用于在页面中直接展示归档内容等不可信 HTML
+func SanitizeHTML(raw string) string {
+	var sb strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(raw))
+	dropDepth := 0 // 处于需要删除内容的标签中的层数
+	var dropTag string
+
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			// io.EOF 或解析错误，返回已处理的部分
+			return sb.String()
+		}
+		token := tokenizer.Token()
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if dropDepth > 0 {
+				if token.Data == dropTag && tt == html.StartTagToken {
+					dropDepth++
+				}
+				continue
+			}
+			if _, ok := sanitizeDroppedTags[token.Data]; ok {
+				_, raw := sanitizeRawTextTags[token.Data]
+				if tt == html.StartTagToken || raw {
+					dropTag = token.Data
+					dropDepth = 1
+				}
+				continue
+			}
+			attrs, ok := sanitizeAllowedTags[token.Data]
+			if !ok {
+				continue
+			}
+			sb.WriteString("<" + token.Data)
+			for _, attr := range token.Attr {
+				if !sanitizeAllowedAttr(attrs, attr) {
+					continue
+				}
+				sb.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+			}
+			if token.Data == "a" {
+				sb.WriteString(` rel="noopener noreferrer nofollow" target="_blank"`)
+			}
+			sb.WriteString(">")
+		case html.EndTagToken:
+			if dropDepth > 0 {
+				if token.Data == dropTag {
+					dropDepth--
+				}
+				continue
+			}
+			if _, ok := sanitizeAllowedTags[token.Data]; !ok {
+				continue
+			}
+			if _, ok := sanitizeVoidTags[token.Data]; ok {
+				continue
+			}
+			sb.WriteString("</" + token.Data + ">")
+		case html.TextToken:
+			if dropDepth == 0 {
+				sb.WriteString(html.EscapeString(token.Data))
+			}
+		}
+	}
+}
+
+// sanitizeAllowedAttr 判断属性是否允许保留
+func sanitizeAllowedAttr(allowed []string, attr html.Attribute) bool {
+	if attr.Namespace != "" {
+		return false
+	}
+	for _, key := range allowed {
+		if attr.Key != key {
+			continue
+		}
+		switch key {
+		case "href", "src", "cite":
+			return isSafeURL(attr.Val)
+		}
+		return true
+	}
+	return false
+}
+
+// isSafeURL 只允许 http、https、mailto 与相对地址
+func isSafeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
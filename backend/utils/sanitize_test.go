@@ -0,0 +1,54 @@
+package utils
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"保留排版标签", "<p>a<br/>b <strong>c</strong></p>", "<p>a<br>b <strong>c</strong></p>"},
+		{"转义文本", "a < b & c", "a &lt; b &amp; c"},
+		{"删除事件属性", `<p onclick="alert(1)">a</p>`, "<p>a</p>"},
+		{"删除不允许的属性", `<td colspan=2 style="color:red">c</td>`, `<td colspan="2">c</td>`},
+		{"保留允许的属性", `<ol start=3 type=a><li>x</li></ol>`, `<ol start="3"><li>x</li></ol>`},
+		{"删除未知标签保留内容", "<unknown>t</unknown>", "t"},
+		{"删除注释", "<!-- c -->t", "t"},
+		{"链接", `<a href="https://example.com" title="t">x</a>`, `<a href="https://example.com" title="t"` + testLinkAttrs + ">x</a>"},
+		{"相对地址", "<a href=/rel>x</a>", `<a href="/rel"` + testLinkAttrs + ">x</a>"},
+		{"mailto 链接", `<a href="mailto:a@example.com">m</a>`, `<a href="mailto:a@example.com"` + testLinkAttrs + ">m</a>"},
+		{"覆盖原有的 target", `<a href="/a" target="_self" rel="opener">x</a>`, `<a href="/a"` + testLinkAttrs + ">x</a>"},
+		{"javascript: 链接", `<a href="javascript:alert(1)">x</a>`, "<a" + testLinkAttrs + ">x</a>"},
+		{"大小写混合的 javascript: 链接", `<a href="JaVaScRiPt:alert(1)">x</a>`, "<a" + testLinkAttrs + ">x</a>"},
+		{"实体编码的 javascript: 链接", `<a href="&#106;avascript:alert(1)">x</a>`, "<a" + testLinkAttrs + ">x</a>"},
+		{"命名实体编码的冒号", `<a href="javascript&colon;alert(1)">x</a>`, "<a" + testLinkAttrs + ">x</a>"},
+		{"协议中包含制表符", `<a href="java&#x09;script:alert(1)">x</a>`, "<a" + testLinkAttrs + ">x</a>"},
+		{"协议前有空格", `<a href=" javascript:alert(1)">x</a>`, "<a" + testLinkAttrs + ">x</a>"},
+		{"vbscript: 链接", `<a href="vbscript:x">v</a>`, "<a" + testLinkAttrs + ">v</a>"},
+		{"data: 图片", `<img src="data:image/svg+xml,<svg onload=alert(1)>">`, "<img>"},
+		{"图片的事件属性", "<img src=x onerror=alert(1)>", `<img src="x">`},
+		{"javascript: 引用地址", `<blockquote cite="javascript:alert(1)">q</blockquote>`, "<blockquote>q</blockquote>"},
+		{"属性值中的标签", `<a title='"><script>alert(1)</script>'>x</a>`, `<a title="&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;"` + testLinkAttrs + ">x</a>"},
+		{"删除 script 及其内容", "<script>alert(1)</script>after", "after"},
+		{"大写的 script", "<SCRIPT>alert(1)</SCRIPT>", ""},
+		{"自闭合的 script 同样删除内容", "<script/>alert(1)</script>after", "after"},
+		{"自闭合的 iframe 同样删除内容", "<iframe/><p>x</p></iframe>after", "after"},
+		{"script 中的 script 开始标签按文本处理", "<script>a<script>b</script>c</script>d", "cd"},
+		{"删除 style", "<div><style>body{}</style>ok</div>", "<div>ok</div>"},
+		{"删除 iframe", "<iframe src=x></iframe>", ""},
+		{"删除 svg 及其中的 script", "<svg><script>alert(1)</script></svg>t", "t"},
+		{"自闭合的 svg", "<svg/>t", "t"},
+		{"删除 noscript", "<noscript><p>x</p></noscript>y", "y"},
+		{"删除 textarea", "<textarea><script>x</script></textarea>z", "z"},
+		{"删除表单", `<form action="/x"><input name="a">b</form>c`, "c"},
+		{"未闭合的标签", "<p", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeHTML(tt.raw); got != tt.want {
+				t.Errorf("SanitizeHTML(%q)\n got %q\nwant %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+const (
+	simHashShingle   = 3  // 指纹特征的词组长度（连续 token 数）
+	simHashMinTokens = 20 // 生成指纹所需的最少 token 数，过短的正文不参与相似度检测
+)
+
+// Tokenize 将文本切分为 token：拉丁字母与数字按单词切分并转为小写，中日韩文字按二元组（bigram）切分
+// 与 MySQL ngram 全文解析器（ngram_token_size=2）的切分方式保持一致
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			tokens = append(tokens, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens = append(tokens, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+// isCJK 判断是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// SimHash 计算文本的 64 位 SimHash 指纹，特征为连续 token 组成的词组
+// 文本过短时返回 0，表示没有指纹
+func SimHash(text string) uint64 {
+	tokens := Tokenize(text)
+	if len(tokens) < simHashMinTokens {
+		return 0
+	}
+
+	var weights [64]int
+	h := fnv.New64a()
+	for i := 0; i+simHashShingle <= len(tokens); i++ {
+		h.Reset()
+		h.Write([]byte(strings.Join(tokens[i:i+simHashShingle], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			fingerprint |= 1 << uint(bit)
+		}
+	}
+	// 极端情况下指纹可能为 0，与"没有指纹"区分
+	if fingerprint == 0 {
+		fingerprint = 1
+	}
+	return fingerprint
+}
+
+// SimHashDistance 计算两个指纹的海明距离
+func SimHashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// SimHashSimilarity 计算两个指纹的相似度（0~1），任一方没有指纹时返回 0
+func SimHashSimilarity(a, b uint64) float64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return 1 - float64(SimHashDistance(a, b))/64
+}
+
+// SimHashMaxDistance 将相似度阈值转换为允许的最大海明距离
+func SimHashMaxDistance(threshold float64) int {
+	if threshold <= 0 || threshold > 1 {
+		threshold = 0.9
+	}
+	return int((1 - threshold) * 64)
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"英文单词转为小写", "Hello, World!", []string{"hello", "world"}},
+		{"数字", "Go 1.25 发布", []string{"go", "1", "25", "发布"}},
+		{"中文二元组", "中文分词", []string{"中文", "文分", "分词"}},
+		{"单个汉字", "中", []string{"中"}},
+		{"中英文混排", "Go语言2024", []string{"go", "语言", "2024"}},
+		{"日文与韩文", "にほんご 한국어", []string{"にほ", "ほん", "んご", "한국", "국어"}},
+		{"非 ASCII 字母", "Café_Noir", []string{"café", "noir"}},
+		{"标点分隔汉字", "你，好", []string{"你", "好"}},
+		{"空文本", " \n\t", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimHash(t *testing.T) {
+	const article = "Go 是一门开源的编程语言，它让构建简单、可靠且高效的软件变得容易。" +
+		"Go 语言由 Google 设计，支持并发编程，拥有垃圾回收机制，编译速度快，标准库丰富，" +
+		"广泛用于网络服务、命令行工具与云原生基础设施的开发。"
+	const other = "Rust is a systems programming language focused on safety, speed and concurrency. " +
+		"It achieves memory safety without garbage collection through its ownership model and borrow checker, " +
+		"and is used for operating systems, browsers and embedded devices."
+
+	tests := []struct {
+		name   string
+		a, b   string
+		minSim float64
+		maxSim float64
+		same   bool
+	}{
+		{name: "相同文本", a: article, b: article, minSim: 1, maxSim: 1, same: true},
+		{name: "只有空白与大小写不同", a: article, b: strings.ToUpper(strings.ReplaceAll(article, " ", "\n  ")), minSim: 1, maxSim: 1, same: true},
+		{name: "少量修改", a: article, b: strings.Replace(article, "编译速度快", "编译很快", 1), minSim: 0.85, maxSim: 1},
+		{name: "不同文本", a: article, b: other, minSim: 0, maxSim: 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := SimHash(tt.a), SimHash(tt.b)
+			if a == 0 || b == 0 {
+				t.Fatalf("SimHash() = %d, %d, want non-zero fingerprints", a, b)
+			}
+			if tt.same && a != b {
+				t.Errorf("SimHash() = %x and %x, want equal", a, b)
+			}
+			if sim := SimHashSimilarity(a, b); sim < tt.minSim || sim > tt.maxSim {
+				t.Errorf("SimHashSimilarity() = %.3f, want between %.2f and %.2f", sim, tt.minSim, tt.maxSim)
+			}
+		})
+	}
+
+	t.Run("正文过短没有指纹", func(t *testing.T) {
+		if fp := SimHash("只有 几个 词"); fp != 0 {
+			t.Errorf("SimHash() = %x, want 0", fp)
+		}
+		if sim := SimHashSimilarity(0, SimHash(article)); sim != 0 {
+			t.Errorf("SimHashSimilarity(0, x) = %v, want 0", sim)
+		}
+	})
+}
+
+func TestSimHashMaxDistance(t *testing.T) {
+	tests := []struct {
+		threshold float64
+		want      int
+	}{
+		{1, 0},
+		{0.9, 6},
+		{0.75, 16},
+		{0.5, 32},
+		{0, 6},   // 无效阈值使用默认的 0.9
+		{1.5, 6}, // 无效阈值使用默认的 0.9
+	}
+	for _, tt := range tests {
+		if got := SimHashMaxDistance(tt.threshold); got != tt.want {
+			t.Errorf("SimHashMaxDistance(%v) = %d, want %d", tt.threshold, got, tt.want)
+		}
+	}
+	if got := SimHashDistance(0b1011, 0b0110); got != 3 {
+		t.Errorf("SimHashDistance() = %d, want 3", got)
+	}
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// 自动标签规则的匹配字段
+const (
+	TagRuleFieldDomain  = "domain"  // 域名
+	TagRuleFieldURL     = "url"     // 网址
+	TagRuleFieldTitle   = "title"   // 标题
+	TagRuleFieldContent = "content" // 正文
+	TagRuleFieldFolder  = "folder"  // 导入时的书签文件夹路径，如 书签栏/技术/Go
+)
+
+// 自动标签规则的匹配方式
+const (
+	TagRuleMatchGlob     = "glob"     // 通配符匹配，如 *.github.com
+	TagRuleMatchRegex    = "regex"    // 正则表达式
+	TagRuleMatchContains = "contains" // 包含（不区分大小写）
+	TagRuleMatchKeywords = "keywords" // 包含任一关键词（英文逗号分隔，不区分大小写）
+)
+
+// TagRuleTarget 自动标签规则的匹配对象
+type TagRuleTarget struct {
+	URL     string
+	Title   string
+	Content string
+	Folder  string
+}
+
+// TagRuleMatcher 编译后的自动标签规则条件
+type TagRuleMatcher struct {
+	field    string
+	match    string
+	pattern  string
+	re       *regexp.Regexp
+	keywords []string
+}
+
+// NewTagRuleMatcher 校验并编译自动标签规则条件
+func NewTagRuleMatcher(field, match, pattern string) (*TagRuleMatcher, error) {
+	switch field {
+	case TagRuleFieldDomain, TagRuleFieldURL, TagRuleFieldTitle, TagRuleFieldContent, TagRuleFieldFolder:
+	default:
+		return nil, fmt.Errorf("不支持的匹配字段: %s", field)
+	}
+
+	matcher := &TagRuleMatcher{
+		field:   field,
+		match:   match,
+		pattern: strings.TrimSpace(pattern),
+	}
+	if matcher.pattern == "" {
+		return nil, fmt.Errorf("匹配规则不能为空")
+	}
+
+	switch match {
+	case TagRuleMatchGlob:
+		matcher.pattern = strings.ToLower(matcher.pattern)
+		if _, err := path.Match(matcher.pattern, ""); err != nil {
+			return nil, fmt.Errorf("通配符格式错误: %w", err)
+		}
+	case TagRuleMatchRegex:
+		re, err := regexp.Compile(matcher.pattern)
+		if err != nil {
+			return nil, fmt.Errorf("正则表达式格式错误: %w", err)
+		}
+		matcher.re = re
+	case TagRuleMatchContains:
+		matcher.pattern = strings.ToLower(matcher.pattern)
+	case TagRuleMatchKeywords:
+		for _, keyword := range strings.Split(matcher.pattern, ",") {
+			keyword = strings.ToLower(strings.TrimSpace(keyword))
+			if keyword != "" {
+				matcher.keywords = append(matcher.keywords, keyword)
+			}
+		}
+		if len(matcher.keywords) == 0 {
+			return nil, fmt.Errorf("关键词不能为空")
+		}
+	default:
+		return nil, fmt.Errorf("不支持的匹配方式: %s", match)
+	}
+
+	return matcher, nil
+}
+
+// Match 判断书签是否满足条件
+func (m *TagRuleMatcher) Match(target TagRuleTarget) bool {
+	var value string
+	switch m.field {
+	case TagRuleFieldDomain:
+		value = URLDomain(target.URL)
+	case TagRuleFieldURL:
+		value = target.URL
+	case TagRuleFieldTitle:
+		value = target.Title
+	case TagRuleFieldContent:
+		value = target.Content
+	case TagRuleFieldFolder:
+		value = target.Folder
+	}
+	if value == "" {
+		return false
+	}
+
+	switch m.match {
+	case TagRuleMatchGlob:
+		if m.field == TagRuleFieldDomain {
+			return matchDomain(value, m.pattern)
+		}
+		matched, _ := path.Match(m.pattern, strings.ToLower(value))
+		return matched
+	case TagRuleMatchRegex:
+		return m.re.MatchString(value)
+	case TagRuleMatchContains:
+		return strings.Contains(strings.ToLower(value), m.pattern)
+	case TagRuleMatchKeywords:
+		value = strings.ToLower(value)
+		for _, keyword := range m.keywords {
+			if strings.Contains(value, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
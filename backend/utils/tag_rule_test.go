@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewTagRuleMatcherErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		match   string
+		pattern string
+		wantErr string
+	}{
+		{"不支持的字段", "author", TagRuleMatchContains, "go", "不支持的匹配字段"},
+		{"不支持的匹配方式", TagRuleFieldTitle, "prefix", "go", "不支持的匹配方式"},
+		{"规则为空", TagRuleFieldTitle, TagRuleMatchContains, "  ", "匹配规则不能为空"},
+		{"通配符格式错误", TagRuleFieldURL, TagRuleMatchGlob, "[a-", "通配符格式错误"},
+		{"正则表达式格式错误", TagRuleFieldTitle, TagRuleMatchRegex, "(go", "正则表达式格式错误"},
+		{"关键词全部为空", TagRuleFieldTitle, TagRuleMatchKeywords, " , ,", "关键词不能为空"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTagRuleMatcher(tt.field, tt.match, tt.pattern)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewTagRuleMatcher() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTagRuleMatcher(t *testing.T) {
+	target := TagRuleTarget{
+		URL:     "https://www.Blog.GitHub.com/golang/go/issues?q=1",
+		Title:   "Go 并发编程实战",
+		Content: "介绍 Goroutine 与 Channel 的使用",
+		Folder:  "书签栏/技术/Go",
+	}
+
+	tests := []struct {
+		name    string
+		field   string
+		match   string
+		pattern string
+		target  TagRuleTarget
+		want    bool
+	}{
+		{"域名通配符", TagRuleFieldDomain, TagRuleMatchGlob, "*.github.com", target, true},
+		{"域名通配符匹配主域名", TagRuleFieldDomain, TagRuleMatchGlob, "*.github.com", TagRuleTarget{URL: "https://github.com/"}, true},
+		{"域名通配符不区分大小写", TagRuleFieldDomain, TagRuleMatchGlob, "*.GITHUB.COM", target, true},
+		{"域名忽略 www.", TagRuleFieldDomain, TagRuleMatchGlob, "www.blog.github.com", target, true},
+		{"域名不匹配", TagRuleFieldDomain, TagRuleMatchGlob, "*.gitlab.com", target, false},
+		{"域名不匹配相似的域名", TagRuleFieldDomain, TagRuleMatchGlob, "*.github.com", TagRuleTarget{URL: "https://notgithub.com/"}, false},
+		{"网址通配符", TagRuleFieldURL, TagRuleMatchGlob, "https://*github.com/golang/go/*", target, true},
+		{"网址通配符的 * 不匹配 /", TagRuleFieldURL, TagRuleMatchGlob, "https://*github.com/*", target, false},
+		{"标题正则表达式", TagRuleFieldTitle, TagRuleMatchRegex, `^Go\s`, target, true},
+		{"正则表达式区分大小写", TagRuleFieldTitle, TagRuleMatchRegex, `^go\s`, target, false},
+		{"正则表达式可以忽略大小写", TagRuleFieldTitle, TagRuleMatchRegex, `(?i)^go\s`, target, true},
+		{"正文包含（不区分大小写）", TagRuleFieldContent, TagRuleMatchContains, "goroutine", target, true},
+		{"正文不包含", TagRuleFieldContent, TagRuleMatchContains, "mutex", target, false},
+		{"任一关键词", TagRuleFieldTitle, TagRuleMatchKeywords, "rust, 并发 ,python", target, true},
+		{"关键词都不包含", TagRuleFieldTitle, TagRuleMatchKeywords, "rust,python", target, false},
+		{"文件夹通配符", TagRuleFieldFolder, TagRuleMatchGlob, "书签栏/技术/*", target, true},
+		{"文件夹包含", TagRuleFieldFolder, TagRuleMatchContains, "/技术/", target, true},
+		{"字段为空时不匹配", TagRuleFieldFolder, TagRuleMatchGlob, "*", TagRuleTarget{URL: "https://example.com"}, false},
+		{"无法解析的网址没有域名", TagRuleFieldDomain, TagRuleMatchGlob, "*", TagRuleTarget{URL: "%zz"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewTagRuleMatcher(tt.field, tt.match, tt.pattern)
+			if err != nil {
+				t.Fatalf("NewTagRuleMatcher() error = %v", err)
+			}
+			if got := matcher.Match(tt.target); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	totpDigits = 6                                 // 动态码位数
+	totpPeriod = 30                                // 时间步长（秒）
+	totpSkew   = 1                                 // 允许前后偏移的时间步数
+	totpSecret = 20                                // 密钥字节数（160 位，RFC 4226 推荐）
+	recoveryN  = 10                                // 恢复码数量
+	recoveryCh = "abcdefghjkmnpqrstuvwxyz23456789" // 恢复码字符集（去掉易混淆字符）
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret 生成 base32 编码的 TOTP 密钥
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecret)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPURI 生成 otpauth:// URI，供认证器 App 扫码添加
+func TOTPURI(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", totpDigits))
+	params.Set("period", fmt.Sprintf("%d", totpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPQRCode 生成 otpauth URI 对应的二维码（PNG, base64）
+func TOTPQRCode(uri string) (string, error) {
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png), nil
+}
+
+// totpCode 计算指定时间步的动态码（RFC 6238）
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// VerifyTOTP 校验动态码，允许前后一个时间步的时钟偏差
+// lastStep 为上次成功使用的时间步，用于防止同一动态码被重复使用
+// 返回匹配的时间步，校验失败返回 0
+func VerifyTOTP(secret, code string, lastStep int64, now time.Time) int64 {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0
+	}
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0
+	}
+
+	current := now.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		step := current + int64(i)
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step
+		}
+	}
+	return 0
+}
+
+// GenerateRecoveryCodes 生成一组一次性恢复码，格式 xxxxx-xxxxx
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, recoveryN)
+	buf := make([]byte, 10)
+	for i := 0; i < recoveryN; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		code := make([]byte, 0, 11)
+		for j, b := range buf {
+			if j == 5 {
+				code = append(code, '-')
+			}
+			code = append(code, recoveryCh[int(b)%len(recoveryCh)])
+		}
+		codes = append(codes, string(code))
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode 计算恢复码的哈希值（恢复码为高熵随机串，无需加盐）
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
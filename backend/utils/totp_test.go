@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTOTPSecret RFC 6238 附录 B 的 SHA1 测试密钥 "12345678901234567890"
+var testTOTPSecret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// RFC 6238 附录 B 的 8 位动态码取后 6 位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0)
+		if got := totpCode([]byte("12345678901234567890"), tt.unix/totpPeriod); got != tt.want {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+		if step := VerifyTOTP(testTOTPSecret, tt.want, 0, now); step != tt.unix/totpPeriod {
+			t.Errorf("VerifyTOTP(T=%d) = %d, want %d", tt.unix, step, tt.unix/totpPeriod)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / totpPeriod
+	codeAt := func(offset int64) string { return totpCode(key, current+offset) }
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		lastStep int64
+		want     int64
+	}{
+		{"当前时间步", testTOTPSecret, codeAt(0), 0, current},
+		{"前一个时间步", testTOTPSecret, codeAt(-1), 0, current - 1},
+		{"后一个时间步", testTOTPSecret, codeAt(1), 0, current + 1},
+		{"超出允许的偏差（前两个时间步）", testTOTPSecret, codeAt(-2), 0, 0},
+		{"超出允许的偏差（后两个时间步）", testTOTPSecret, codeAt(2), 0, 0},
+		{"前后有空格", testTOTPSecret, " " + codeAt(0) + " ", 0, current},
+		{"小写密钥", strings.ToLower(testTOTPSecret), codeAt(0), 0, current},
+		{"错误的动态码", testTOTPSecret, "000000", 0, 0},
+		{"位数错误", testTOTPSecret, codeAt(0)[:5], 0, 0},
+		{"密钥格式错误", "not-base32!", codeAt(0), 0, 0},
+		{"重复使用同一时间步", testTOTPSecret, codeAt(0), current, 0},
+		{"重复使用更早的时间步", testTOTPSecret, codeAt(-1), current, 0},
+		{"上次使用前一个时间步", testTOTPSecret, codeAt(0), current - 1, current},
+		{"上次使用当前时间步后仍可使用后一个时间步", testTOTPSecret, codeAt(1), current, current + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyTOTP(tt.secret, tt.code, tt.lastStep, now); got != tt.want {
+				t.Errorf("VerifyTOTP(%q, lastStep=%d) = %d, want %d", tt.code, tt.lastStep, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != totpSecret {
+		t.Fatalf("secret %q decodes to %d bytes, err %v", secret, len(key), err)
+	}
+	now := time.Now()
+	if step := VerifyTOTP(secret, totpCode(key, now.Unix()/totpPeriod), 0, now); step == 0 {
+		t.Error("VerifyTOTP() with a generated secret = 0")
+	}
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"net"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// TrackingRule 按域名配置的追踪参数规则
+type TrackingRule struct {
+	Domain string   // 域名，支持通配符，如 *.taobao.com（同时匹配 taobao.com）
+	Params []string // 需要移除的参数，支持通配符，如 share_*
+}
+
+// defaultTrackingParams 默认移除的追踪参数（所有域名）
+var defaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid",
+	"mc_cid", "mc_eid", "_hsenc", "_hsmi", "igshid", "spm", "scm",
+}
+
+var (
+	urlRulesMu     sync.RWMutex
+	trackingParams = defaultTrackingParams
+	trackingRules  []TrackingRule
+)
+
+// InitURLRules 设置 URL 规范化规则：额外的全局追踪参数与按域名的追踪参数
+func InitURLRules(params []string, rules []TrackingRule) {
+	urlRulesMu.Lock()
+	defer urlRulesMu.Unlock()
+
+	trackingParams = append(append([]string{}, defaultTrackingParams...), params...)
+	trackingRules = rules
+}
+
+// CanonicalizeURL 生成用于判重的规范化 URL
+//  1. 协议统一为 https，域名小写并去掉 www. 前缀与默认端口
+//  2. 去掉路径末尾的 /，去掉片段（#! 与 #/ 开头的前端路由片段除外）
+//  3. 移除追踪参数，其余查询参数按名称排序
+func CanonicalizeURL(rawURL string) (string, error) {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+
+	// 非 http(s) 链接（如 javascript:、ftp:）只做最基本的处理
+	scheme := strings.ToLower(parsedURL.Scheme)
+	if scheme != "http" && scheme != "https" {
+		parsedURL.Scheme = scheme
+		return parsedURL.String(), nil
+	}
+	parsedURL.Scheme = "https"
+
+	// 域名与端口
+	host := strings.ToLower(parsedURL.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	port := parsedURL.Port()
+	if port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6 地址
+	}
+	parsedURL.Host = host
+
+	// 路径：按转义后的路径处理，保留 %2F 等转义字符
+	escapedPath := strings.TrimRight(parsedURL.EscapedPath(), "/")
+	if escapedPath == "" {
+		escapedPath = "/"
+	}
+	if parsedURL.Path, err = url.PathUnescape(escapedPath); err != nil {
+		return "", err
+	}
+	parsedURL.RawPath = escapedPath
+
+	// 片段
+	if !strings.HasPrefix(parsedURL.Fragment, "!") && !strings.HasPrefix(parsedURL.Fragment, "/") {
+		parsedURL.Fragment = ""
+		parsedURL.RawFragment = ""
+	}
+
+	// 查询参数（Encode 会按名称排序）
+	queries := parsedURL.Query()
+	removeParams := trackingParamsFor(parsedURL.Hostname())
+	for name := range queries {
+		if matchAnyPattern(strings.ToLower(name), removeParams) {
+			queries.Del(name)
+		}
+	}
+	parsedURL.RawQuery = queries.Encode()
+	parsedURL.ForceQuery = false
+
+	return parsedURL.String(), nil
+}
+
+// URLDomain 获取 URL 的域名（小写，去掉 www. 前缀），解析失败返回空字符串
+func URLDomain(rawURL string) string {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsedURL.Hostname()), "www.")
+}
+
+// trackingParamsFor 获取指定域名需要移除的追踪参数
+func trackingParamsFor(host string) []string {
+	urlRulesMu.RLock()
+	defer urlRulesMu.RUnlock()
+
+	params := trackingParams
+	for _, rule := range trackingRules {
+		if matchDomain(host, rule.Domain) {
+			params = append(append([]string{}, params...), rule.Params...)
+		}
+	}
+	return params
+}
+
+// matchDomain 域名匹配，*.example.com 同时匹配 example.com
+func matchDomain(host, pattern string) bool {
+	pattern = strings.TrimPrefix(strings.ToLower(pattern), "www.")
+	if strings.HasPrefix(pattern, "*.") && host == pattern[2:] {
+		return true
+	}
+	matched, _ := path.Match(pattern, host)
+	return matched
+}
+
+// matchAnyPattern 判断名称是否匹配任一通配符
+func matchAnyPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package utils
+
+import "testing"
+
+func TestCanonicalizeURL(t *testing.T) {
+	InitURLRules([]string{"from"}, []TrackingRule{{Domain: "*.taobao.com", Params: []string{"share_*"}}})
+	t.Cleanup(func() { InitURLRules(nil, nil) })
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"协议、域名与默认端口", "HTTP://WWW.Example.COM:80/a", "https://example.com/a"},
+		{"https 默认端口", "https://example.com:443/", "https://example.com/"},
+		{"保留非默认端口", "http://example.com:8080", "https://example.com:8080/"},
+		{"只去掉开头的 www.", "https://wwwexample.com/", "https://wwwexample.com/"},
+		{"空路径", "https://example.com", "https://example.com/"},
+		{"去掉路径末尾的 /", "https://example.com/a/b//", "https://example.com/a/b"},
+		{"根路径的多个 /", "https://example.com//", "https://example.com/"},
+		{"保留转义的 /", "https://example.com/a%2Fb/", "https://example.com/a%2Fb"},
+		{"保留非 ASCII 路径的转义", "https://example.com/%E4%B8%AD", "https://example.com/%E4%B8%AD"},
+		{"IPv6 地址", "http://[::1]/a", "https://[::1]/a"},
+		{"IPv6 地址与端口", "http://[::1]:8080/a/", "https://[::1]:8080/a"},
+		{"去掉片段", "https://example.com/a#section", "https://example.com/a"},
+		{"保留 #! 路由片段", "https://example.com/#!/route", "https://example.com/#!/route"},
+		{"保留 #/ 路由片段", "https://example.com/#/route", "https://example.com/#/route"},
+		{"查询参数排序", "https://example.com/a?b=2&a=1", "https://example.com/a?a=1&b=2"},
+		{"同名参数保持顺序", "https://example.com/a?b=1&b=0", "https://example.com/a?b=1&b=0"},
+		{"空查询", "https://example.com/?", "https://example.com/"},
+		{"默认追踪参数（不区分大小写）", "https://example.com/?UTM_Source=x&Spm=1&fbclid=2&id=3", "https://example.com/?id=3"},
+		{"配置的全局追踪参数", "https://example.com/?from=x&id=1", "https://example.com/?id=1"},
+		{"按域名的追踪参数", "https://item.taobao.com/?id=1&share_from=x", "https://item.taobao.com/?id=1"},
+		{"通配符域名匹配主域名", "https://taobao.com/?id=1&share_from=x", "https://taobao.com/?id=1"},
+		{"其他域名不移除", "https://example.com/?share_from=x", "https://example.com/?share_from=x"},
+		{"非 http 链接", "FTP://Example.com/a/", "ftp://Example.com/a/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalizeURL(tt.url)
+			if err != nil {
+				t.Fatalf("CanonicalizeURL(%q) error = %v", tt.url, err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalizeURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := CanonicalizeURL("http://[::1"); err == nil {
+		t.Error("CanonicalizeURL() with invalid host error = nil, want error")
+	}
+}
+
+func TestURLDomain(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://WWW.Example.com:8080/a", "example.com"},
+		{"https://blog.example.com", "blog.example.com"},
+		{"http://[::1]/", "::1"},
+		{"not a url %zz", ""},
+	}
+	for _, tt := range tests {
+		if got := URLDomain(tt.url); got != tt.want {
+			t.Errorf("URLDomain(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
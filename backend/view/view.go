@@ -0,0 +1,20 @@
+// Package view 服务端渲染的页面模板（公开分享页面等）
+package view
+
+import (
+	"embed"
+	"html/template"
+	"time"
+)
+
+//go:embed *.html
+var files embed.FS
+
+// Templates 解析全部页面模板
+func Templates() *template.Template {
+	return template.Must(template.New("").Funcs(template.FuncMap{
+		"date": func(t time.Time) string {
+			return t.Format("2006-01-02")
+		},
+	}).ParseFS(files, "*.html"))
+}
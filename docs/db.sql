@@ -1,76 +0,0 @@
-/*
- Navicat Premium Dump SQL
-
- Source Server         : hk-local
- Source Server Type    : MySQL
- Source Server Version : 80040 (8.0.40)
- Source Host           : localhost:3307
- Source Schema         : bk_kms
-
- Target Server Type    : MySQL
- Target Server Version : 80040 (8.0.40)
- File Encoding         : 65001
-
- Date: 07/10/2025 00:45:10
-*/
-
-SET NAMES utf8mb4;
-SET FOREIGN_KEY_CHECKS = 0;
-
--- ----------------------------
--- Table structure for bookmark
--- ----------------------------
-CREATE TABLE `bookmark`  (
-  `id` int NOT NULL AUTO_INCREMENT,
-  `url` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网址地址',
-  `title` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网址标题',
-  `excerpt` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网站内容节选',
-  `author` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '作者',
-  `content` mediumtext CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网站文本内容(去掉html标签)',
-  `html` mediumtext CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '网站原始内容',
-  `is_archive` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否已存档,0:否，1:是',
-  `created_at` datetime(3) NOT NULL,
-  `updated_at` datetime(3) NOT NULL,
-  PRIMARY KEY (`id`) USING BTREE,
-  UNIQUE INDEX `bookmark_url_UNIQUE`(`url`(255) ASC) USING BTREE,
-  INDEX `idx_created_at`(`created_at` ASC) USING BTREE,
-  INDEX `idx_modified_at`(`updated_at` ASC) USING BTREE,
-  FULLTEXT INDEX `ft_all_zh`(`url`, `title`, `excerpt`, `content`) WITH PARSER `ngram`
-) ENGINE = InnoDB AUTO_INCREMENT = 1341 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '书签表' ROW_FORMAT = Dynamic;
-
--- ----------------------------
--- Table structure for bookmark_tag
--- ----------------------------
-CREATE TABLE `bookmark_tag`  (
-  `bookmark_id` int NOT NULL,
-  `tag_id` int NOT NULL,
-  PRIMARY KEY (`bookmark_id`, `tag_id`) USING BTREE,
-  INDEX `bookmark_tag_bookmark_id_FK`(`bookmark_id` ASC) USING BTREE,
-  INDEX `bookmark_tag_tag_id_FK`(`tag_id` ASC) USING BTREE
-) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = 'bookmark与tag关联中间表' ROW_FORMAT = Dynamic;
-
--- ----------------------------
--- Table structure for tag
--- ----------------------------
-CREATE TABLE `tag`  (
-  `id` int NOT NULL AUTO_INCREMENT,
-  `name` varchar(250) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL,
-  PRIMARY KEY (`id`) USING BTREE,
-  UNIQUE INDEX `tag_name_UNIQUE`(`name` ASC) USING BTREE
-) ENGINE = InnoDB AUTO_INCREMENT = 258 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = 'tag表' ROW_FORMAT = Dynamic;
-
--- ----------------------------
--- Table structure for user
--- ----------------------------
-CREATE TABLE `user`  (
-  `id` int NOT NULL AUTO_INCREMENT,
-  `username` varchar(250) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '用户名',
-  `password` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL,
-  `salt` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '0' COMMENT '密码盐值',
-  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
-  `updated_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
-  PRIMARY KEY (`id`) USING BTREE,
-  UNIQUE INDEX `account_username_UNIQUE`(`username` ASC) USING BTREE
-) ENGINE = InnoDB AUTO_INCREMENT = 4 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT = '用户表' ROW_FORMAT = Dynamic;
-
-SET FOREIGN_KEY_CHECKS = 1;